@@ -1,16 +1,29 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
-	"time"
 )
 
+const baseURL = "http://localhost:8080"
+
+// queueStatus: /queue/status 응답
+type queueStatus struct {
+	State         string `json:"state"`
+	Rank          int    `json:"rank"`
+	EstimatedWait int    `json:"estimated_wait_seconds"`
+	Progress      int    `json:"progress_percent"`
+}
+
 func main() {
 	var wg sync.WaitGroup
 	totalUsers := 50000
+	eventID := 1 // 예매 대상 이벤트 (카탈로그의 ID)
 
 	for i := 0; i < totalUsers; i++ {
 		wg.Add(1)
@@ -18,10 +31,15 @@ func main() {
 			defer wg.Done()
 
 			userID := fmt.Sprintf("user_%d", user)
-			url := fmt.Sprintf("http://localhost:8080/ticket?user_id=%s", userID)
+			ticketURL := fmt.Sprintf("%s/ticket?user_id=%s&event_id=%d", baseURL, userID, eventID)
+			token := ""
 
 			for {
-				resp, err := http.Get(url)
+				reqURL := ticketURL
+				if token != "" {
+					reqURL += "&queue_token=" + url.QueryEscape(token)
+				}
+				resp, err := http.Get(reqURL)
 				if err != nil {
 					return
 				}
@@ -32,15 +50,17 @@ func main() {
 					resp.Body.Close()
 					return
 
-				case http.StatusAccepted: // 202: 대기열 진입 성공 (순번 대기 중)
+				case http.StatusAccepted: // 202: 대기열(또는 판매 전 대기실) 진입 성공
 					var result map[string]interface{}
 					json.NewDecoder(resp.Body).Decode(&result)
-
-					// 대기 번호를 출력하며 1초 대기 후 재시도(Polling)
-					fmt.Printf("사용자 %d: [대기중] 순번: %v (ID: %s)\n", user, result["rank"], userID)
 					resp.Body.Close()
+					token, _ = result["queue_token"].(string)
+					fmt.Printf("사용자 %d: [대기중] 순번: %v (ID: %s)\n", user, result["rank"], userID)
 
-					time.Sleep(1 * time.Second) // 1초 뒤에 "저 이제 차례인가요?" 물어봄
+					// 예매 API를 다시 호출하지 않고 푸시로 순번을 받다가 입장(ADMITTED)되면 예매 재시도
+					if !waitForAdmission(user, token) {
+						return
+					}
 					continue
 
 				case http.StatusGone: // 410: 매진
@@ -64,3 +84,39 @@ func main() {
 	wg.Wait()
 	fmt.Println("테스트 종료")
 }
+
+// waitForAdmission: SSE로 순번 갱신을 받다가 입장 가능해지면 true (연결 실패 등으로 대기할 수 없으면 false)
+// 입장 유효 시간이 지났거나(EXPIRED) 대기열에 없으면(NONE) 스트림이 닫히며 예매 API로 다시 진입합니다.
+func waitForAdmission(user int, token string) bool {
+	resp, err := http.Get(baseURL + "/queue/events?token=" + url.QueryEscape(token))
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+
+	// "event: {이름}" 다음 줄의 "data: {JSON}"을 읽음
+	event := ""
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if event == "lobby" {
+				fmt.Printf("사용자 %d: [대기실] 판매 시작을 기다리는 중\n", user)
+				continue
+			}
+			if event != "rank" {
+				return true // admitted 또는 closed
+			}
+			var status queueStatus
+			json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &status)
+			fmt.Printf("사용자 %d: [대기중] 순번: %d (예상 대기 %d초, 진행률 %d%%)\n", user, status.Rank, status.EstimatedWait, status.Progress)
+		}
+	}
+	return false
+}
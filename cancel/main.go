@@ -13,6 +13,7 @@ func main() {
 	// 취소를 시도할 유저 범위 (예: user_0부터 user_19까지 20명 취소)
 	cancelStart := 0
 	cancelEnd := 19
+	eventID := 1 // 취소 대상 이벤트 (카탈로그의 ID)
 
 	fmt.Printf("--- %d번부터 %d번 유저까지 취소 테스트 시작 ---\n", cancelStart, cancelEnd)
 
@@ -23,7 +24,7 @@ func main() {
 
 			userID := fmt.Sprintf("user_%d", user)
 			// 취소 API 호출 (URL에 user_id를 실어서 보냄)
-			url := fmt.Sprintf("http://localhost:8080/cancel?user_id=%s", userID)
+			url := fmt.Sprintf("http://localhost:8080/cancel?user_id=%s&event_id=%d", userID, eventID)
 
 			// DELETE 또는 POST 등 서버에서 설정한 메서드에 맞춰 http.Get 혹은 http.NewRequest 사용
 			// 여기서는 테스트 편의상 Get으로 작성합니다.
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"ticket-system/repository"
+	"ticket-system/worker"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+/*
+ * Reconciler
+ * Redis 재고/구매자 명단과 MySQL purchases/tickets를 비교하여 차이를 보고(및 복구)합니다.
+ *   go run cmd/reconciler/main.go -once            : 한 번 검사 후 결과(JSON) 출력, 차이가 있으면 종료 코드 1
+ *   go run cmd/reconciler/main.go -once -repair    : 검사 후 복구
+ *   go run cmd/reconciler/main.go -interval 1m     : 주기 검사 + Prometheus 메트릭(:8082)
+ */
+
+func main() {
+	once := flag.Bool("once", false, "한 번만 검사하고 종료")
+	repair := flag.Bool("repair", false, "확인된 차이를 복구")
+	interval := flag.Duration("interval", time.Minute, "주기 검사 간격")
+	settle := flag.Duration("settle", 10*time.Second, "처리 중인 메시지를 기다린 뒤 재검사하는 시간")
+	metricsAddr := flag.String("metrics-addr", ":8082", "Prometheus 메트릭 주소")
+	flag.Parse()
+
+	// 1. 인프라 연결 (API 서버와 동일한 Redis / MySQL)
+	rdb := redis.NewClient(&redis.Options{
+		Addr: "localhost:16379",
+	})
+	dsn := "root:password123@tcp(127.0.0.1:3306)/ticket_db?charset=utf8mb4&parseTime=True&loc=Local"
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("DB 연결 실패: %v", err)
+	}
+
+	// 2. Reconciler 조립
+	rc := worker.NewReconciler(
+		&repository.RedisRepository{Client: rdb},
+		repository.NewMySQLRepository(db),
+		repository.NewMySQLSeatRepository(db),
+	)
+	rc.Repair = *repair
+	rc.Settle = *settle
+
+	ctx := context.Background()
+
+	if *once {
+		reports, err := rc.Run(ctx)
+		if err != nil {
+			log.Fatalf("정합성 검사 실패: %v", err)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(reports)
+		if len(reports) > 0 && !*repair {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// 3. 상시 실행 모드: 메트릭 서버 + 주기 검사
+	go func() {
+		log.Printf("📊 Prometheus 메트릭 서버 시작 중... (%s/metrics)", *metricsAddr)
+		http.Handle("/metrics", promhttp.Handler())
+		if err := http.ListenAndServe(*metricsAddr, nil); err != nil {
+			log.Fatalf("메트릭 서버 실행 실패: %v", err)
+		}
+	}()
+
+	log.Printf("🔍 Reconciler 시작 (간격 %s, 복구 모드 %v)", *interval, *repair)
+	rc.Start(ctx, *interval)
+}
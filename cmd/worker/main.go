@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"net/http"
 	"ticket-system/repository"
@@ -17,6 +18,11 @@ import (
  */
 
 func main() {
+	lanes := flag.Int("lanes", worker.DefaultLanes, "병렬 처리 레인 수 (같은 유저의 이벤트는 한 레인에서 순서대로 처리)")
+	batchSize := flag.Int("batch-size", worker.DefaultBatchSize, "레인별 일괄 처리 최대 메시지 수")
+	batchWait := flag.Duration("batch-wait", worker.DefaultBatchWait, "배치를 채우기 위해 기다리는 최대 시간")
+	flag.Parse()
+
 	// 1. Database Connection (GORM)
 	dsn := "root:password123@tcp(127.0.0.1:3306)/ticket_db?charset=utf8mb4&parseTime=True&loc=Local"
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
@@ -26,6 +32,7 @@ func main() {
 
 	// 2. Repository 초기화 (Dependency Injection)
 	ticketRepo := repository.NewMySQLRepository(db)
+	seatRepo := repository.NewMySQLSeatRepository(db)
 	kafkaRepo := repository.NewKafkaRepository([]string{"localhost:9092"}, "ticket-topic")
 
 	// 3. Prometheus Metrics Server (Monitoring)
@@ -45,8 +52,12 @@ func main() {
 		"ticket-topic",
 		"ticket-group",
 		ticketRepo,
+		seatRepo,
 		kafkaRepo,
 	)
+	pWorker.Lanes = *lanes
+	pWorker.BatchSize = *batchSize
+	pWorker.BatchWait = *batchWait
 
 	pWorker.Start()
 }
@@ -0,0 +1,172 @@
+package codec
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * ticket-topic 이벤트 공통 스키마 (Producer/Worker 공용)
+ * 메시지 Value는 JSON Envelope이며, 구버전 문자열 포맷("CANCEL:{ticket}" 등)도
+ * 마이그레이션 기간 동안 Decode에서 같은 Event로 변환하여 처리합니다.
+ */
+
+// SchemaVersion: 현재 Producer가 발행하는 스키마 버전 (필드 추가는 버전 유지, 의미 변경 시 증가)
+const SchemaVersion = 1
+
+// ContentType: JSON Envelope 메시지에 붙는 content_type 헤더 값
+const ContentType = "application/vnd.ticket-event+json"
+
+type EventType string
+
+const (
+	TypePurchase     EventType = "PURCHASE"
+	TypeCancel       EventType = "CANCEL"
+	TypeExpire       EventType = "EXPIRE"
+	TypeSeatPurchase EventType = "SEAT_PURCHASE"
+	TypeSeatCancel   EventType = "SEAT_CANCEL"
+	TypeTransfer     EventType = "TRANSFER"
+)
+
+var (
+	ErrMalformed          = errors.New("이벤트 형식이 올바르지 않습니다")
+	ErrUnsupportedVersion = errors.New("지원하지 않는 스키마 버전입니다")
+)
+
+// Event: ticket-topic으로 오가는 이벤트 Envelope
+type Event struct {
+	Type          EventType `json:"type"`
+	EventID       string    `json:"event_id"` // 메시지 고유 ID (중복 수신 추적용)
+	SchemaVersion int       `json:"schema_version"`
+	UserID        string    `json:"user_id"`
+	TicketName    string    `json:"ticket"`
+	Quantity      int       `json:"quantity"`
+	Timestamp     time.Time `json:"timestamp"`
+	TraceID       string    `json:"trace_id,omitempty"` // 홀드 ID 등 요청 흐름을 묶는 ID
+
+	// 타입별 부가 정보
+	PaymentID  string   `json:"payment_id,omitempty"`
+	Amount     int      `json:"amount,omitempty"`
+	SeatIDs    []uint   `json:"seat_ids,omitempty"`
+	HoldID     string   `json:"hold_id,omitempty"`
+	OrderID    string   `json:"order_id,omitempty"`
+	ItemIDs    []uint   `json:"item_ids,omitempty"`    // 주문 항목 단위 취소 (비어 있으면 유저의 이벤트 구매 전체 취소)
+	PaymentIDs []string `json:"payment_ids,omitempty"` // 구매 전체 취소 시 대상 주문의 결제 ID (비어 있으면 구버전 메시지, 유저의 구매 전체)
+	ToUserID   string   `json:"to_user_id,omitempty"`  // 양도받는 유저 (UserID는 양도하는 유저)
+
+	// Legacy: 구버전 문자열 포맷에서 변환된 이벤트 여부 (직렬화하지 않음)
+	Legacy bool `json:"-"`
+}
+
+// New: 현재 스키마 버전으로 새 이벤트를 생성
+func New(typ EventType, userID string, ticketName string, traceID string) *Event {
+	return &Event{
+		Type:          typ,
+		EventID:       newEventID(),
+		SchemaVersion: SchemaVersion,
+		UserID:        userID,
+		TicketName:    ticketName,
+		Quantity:      1,
+		Timestamp:     time.Now().UTC(),
+		TraceID:       traceID,
+	}
+}
+
+func Encode(e *Event) ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// Decode: 메시지 Key/Value/Headers로부터 Event를 복원
+// Value가 JSON이면 Envelope으로, 아니면 구버전 문자열 포맷으로 해석합니다.
+func Decode(key []byte, value []byte, headers map[string]string) (*Event, error) {
+	if len(value) > 0 && value[0] == '{' {
+		var e Event
+		if err := json.Unmarshal(value, &e); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+		if e.SchemaVersion < 1 || e.SchemaVersion > SchemaVersion {
+			return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, e.SchemaVersion)
+		}
+		if e.Type == "" || e.UserID == "" || e.TicketName == "" {
+			return nil, fmt.Errorf("%w: 필수 필드 누락", ErrMalformed)
+		}
+		return &e, nil
+	}
+	return decodeLegacy(string(key), string(value), headers)
+}
+
+// decodeLegacy: 구버전 포맷 (Key = 유저 ID)
+// - "{ticket}"                       : 예매 (헤더 payment_id, amount)
+// - "CANCEL:{ticket}"                : 예매 취소
+// - "EXPIRE:{ticket}"                : 홀드 만료 (헤더 hold_id)
+// - "SEAT:{ticket}:{seatIDs}"        : 지정석 구매 (헤더 payment_id, amount)
+// - "SEAT_CANCEL:{ticket}:{seatIDs}" : 지정석 취소
+func decodeLegacy(userID string, value string, headers map[string]string) (*Event, error) {
+	if userID == "" || value == "" {
+		return nil, fmt.Errorf("%w: 빈 메시지", ErrMalformed)
+	}
+
+	e := &Event{
+		SchemaVersion: 0,
+		UserID:        userID,
+		Quantity:      1,
+		Legacy:        true,
+	}
+
+	switch {
+	case strings.HasPrefix(value, "SEAT_CANCEL:"):
+		ticketName, seatIDs, err := splitSeats(strings.TrimPrefix(value, "SEAT_CANCEL:"))
+		if err != nil {
+			return nil, err
+		}
+		e.Type, e.TicketName, e.SeatIDs, e.Quantity = TypeSeatCancel, ticketName, seatIDs, len(seatIDs)
+	case strings.HasPrefix(value, "SEAT:"):
+		ticketName, seatIDs, err := splitSeats(strings.TrimPrefix(value, "SEAT:"))
+		if err != nil {
+			return nil, err
+		}
+		e.Type, e.TicketName, e.SeatIDs, e.Quantity = TypeSeatPurchase, ticketName, seatIDs, len(seatIDs)
+	case strings.HasPrefix(value, "EXPIRE:"):
+		e.Type, e.TicketName = TypeExpire, strings.TrimPrefix(value, "EXPIRE:")
+		e.HoldID = headers["hold_id"]
+	case strings.HasPrefix(value, "CANCEL:"):
+		e.Type, e.TicketName = TypeCancel, strings.TrimPrefix(value, "CANCEL:")
+	default:
+		e.Type, e.TicketName = TypePurchase, value
+	}
+
+	if e.Type == TypePurchase || e.Type == TypeSeatPurchase {
+		e.PaymentID = headers["payment_id"]
+		e.Amount, _ = strconv.Atoi(headers["amount"])
+	}
+	return e, nil
+}
+
+// splitSeats: "{ticket}:{seatID,seatID}" 형식 해석 (공연 이름에 ':'가 있어도 마지막 구분자 기준)
+func splitSeats(body string) (string, []uint, error) {
+	idx := strings.LastIndex(body, ":")
+	if idx < 0 {
+		return "", nil, fmt.Errorf("%w: 좌석 구분자가 없습니다", ErrMalformed)
+	}
+	var seatIDs []uint
+	for _, part := range strings.Split(body[idx+1:], ",") {
+		id, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: 잘못된 좌석 ID %q", ErrMalformed, part)
+		}
+		seatIDs = append(seatIDs, uint(id))
+	}
+	return body[:idx], seatIDs, nil
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,122 @@
+package codec
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeEnvelope(t *testing.T) {
+	e := New(TypeCancel, "user_1", "concert_2026", "trace-1")
+	e.Quantity, e.PaymentIDs = 2, []string{"pay_1", "pay_2"}
+	value, err := Encode(e)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	got, err := Decode([]byte("user_1"), value, map[string]string{"content_type": ContentType})
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got.Legacy {
+		t.Error("Envelope 메시지가 Legacy로 표시됨")
+	}
+	if got.Type != TypeCancel || got.UserID != "user_1" || got.TicketName != "concert_2026" || got.Quantity != 2 || got.TraceID != "trace-1" {
+		t.Errorf("Decode = %+v", got)
+	}
+	if !reflect.DeepEqual(got.PaymentIDs, e.PaymentIDs) {
+		t.Errorf("PaymentIDs = %v, want %v", got.PaymentIDs, e.PaymentIDs)
+	}
+}
+
+func TestDecodeEnvelopeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  error
+	}{
+		{"깨진 JSON", `{"type":`, ErrMalformed},
+		{"버전 없음", `{"type":"PURCHASE","user_id":"u","ticket":"t"}`, ErrUnsupportedVersion},
+		{"미래 버전", `{"type":"PURCHASE","schema_version":2,"user_id":"u","ticket":"t"}`, ErrUnsupportedVersion},
+		{"유저 누락", `{"type":"PURCHASE","schema_version":1,"ticket":"t"}`, ErrMalformed},
+		{"타입 누락", `{"schema_version":1,"user_id":"u","ticket":"t"}`, ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode([]byte("u"), []byte(tt.value), nil)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Decode(%s) err = %v, want %v", tt.value, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeLegacy(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		headers map[string]string
+		want    Event
+	}{
+		{
+			name:    "예매",
+			value:   "concert_2026",
+			headers: map[string]string{"payment_id": "pay_1", "amount": "50000"},
+			want:    Event{Type: TypePurchase, TicketName: "concert_2026", Quantity: 1, PaymentID: "pay_1", Amount: 50000},
+		},
+		{
+			name:  "취소",
+			value: "CANCEL:concert_2026",
+			want:  Event{Type: TypeCancel, TicketName: "concert_2026", Quantity: 1},
+		},
+		{
+			name:    "홀드 만료",
+			value:   "EXPIRE:concert_2026",
+			headers: map[string]string{"hold_id": "hold_1"},
+			want:    Event{Type: TypeExpire, TicketName: "concert_2026", Quantity: 1, HoldID: "hold_1"},
+		},
+		{
+			name:    "지정석 구매 (이름에 ':' 포함)",
+			value:   "SEAT:live:2026:1,2,3",
+			headers: map[string]string{"payment_id": "pay_2", "amount": "30000"},
+			want:    Event{Type: TypeSeatPurchase, TicketName: "live:2026", Quantity: 3, SeatIDs: []uint{1, 2, 3}, PaymentID: "pay_2", Amount: 30000},
+		},
+		{
+			name:  "지정석 취소",
+			value: "SEAT_CANCEL:live_2026:7",
+			want:  Event{Type: TypeSeatCancel, TicketName: "live_2026", Quantity: 1, SeatIDs: []uint{7}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode([]byte("user_1"), []byte(tt.value), tt.headers)
+			if err != nil {
+				t.Fatalf("Decode(%q): %v", tt.value, err)
+			}
+			tt.want.UserID, tt.want.Legacy = "user_1", true
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Decode(%q) = %+v, want %+v", tt.value, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeLegacyErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{"빈 Key", "", "concert_2026"},
+		{"빈 Value", "user_1", ""},
+		{"좌석 구분자 없음", "user_1", "SEAT:concert_2026"},
+		{"잘못된 좌석 ID", "user_1", "SEAT_CANCEL:concert_2026:1,x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode([]byte(tt.key), []byte(tt.value), nil); !errors.Is(err, ErrMalformed) {
+				t.Errorf("Decode(%q, %q) err = %v, want ErrMalformed", tt.key, tt.value, err)
+			}
+		})
+	}
+}
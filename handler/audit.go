@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+	"ticket-system/service"
+)
+
+/*
+ * AuditHandler: 구매 상태 변경 이력 조회 (관리자)
+ * - GET /admin/users/{user_id}/history?event_id=... : 유저의 구매/취소/복구 이력 (event_id 생략 시 전체 이벤트)
+ */
+type AuditHandler struct {
+	Service *service.TicketService
+}
+
+func NewAuditHandler(s *service.TicketService) *AuditHandler {
+	return &AuditHandler{
+		Service: s,
+	}
+}
+
+func (h *AuditHandler) History(w http.ResponseWriter, r *http.Request) {
+	var eventID uint
+	if raw := r.URL.Query().Get("event_id"); raw != "" {
+		id, ok := parseEventID(raw)
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "event_id가 올바르지 않습니다"})
+			return
+		}
+		eventID = id
+	}
+
+	logs, err := h.Service.UserHistory(r.PathValue("user_id"), eventID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, logs)
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"ticket-system/worker"
+)
+
+/*
+ * DLQHandler: ticket-dlq-topic 관리자 API
+ * - GET  /admin/dlq                                 : 파티션별 offset 범위
+ * - GET  /admin/dlq/messages?partition=&offset=&limit=&reason=&type=&ticket=&status= : 메시지 목록 (페이지)
+ * - GET  /admin/dlq/messages/{partition}/{offset}   : 메시지 상세 (실패 이력, 해석된 이벤트, 처리 결과)
+ * - POST /admin/dlq/messages/{partition}/{offset}/discard : 폐기 표시 (재처리 대상에서 제외)
+ * - POST /admin/dlq/replay                          : 개별 메시지 또는 범위 + 필터 재처리 Job 생성
+ * - GET  /admin/dlq/jobs, /admin/dlq/jobs/{id}      : 재처리 Job 진행 상황
+ * - POST /admin/dlq/purge                           : partition의 before_offset 이전 메시지 정리
+ */
+type DLQHandler struct {
+	Manager *worker.DLQManager
+}
+
+func NewDLQHandler(m *worker.DLQManager) *DLQHandler {
+	return &DLQHandler{
+		Manager: m,
+	}
+}
+
+func (h *DLQHandler) Partitions(w http.ResponseWriter, r *http.Request) {
+	partitions, err := h.Manager.Partitions(r.Context())
+	if err != nil {
+		writeDLQError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"partitions": partitions})
+}
+
+func (h *DLQHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	partition, _ := strconv.Atoi(q.Get("partition"))
+	offset, _ := strconv.ParseInt(q.Get("offset"), 10, 64)
+	limit, err := strconv.Atoi(q.Get("limit"))
+	if err != nil || limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	filter := worker.DLQFilter{
+		Reason: q.Get("reason"),
+		Type:   q.Get("type"),
+		Ticket: q.Get("ticket"),
+		Status: q.Get("status"),
+	}
+
+	msgs, next, err := h.Manager.List(r.Context(), partition, offset, limit, filter)
+	if err != nil {
+		writeDLQError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"messages":    msgs,
+		"next_offset": next,
+	})
+}
+
+func (h *DLQHandler) Get(w http.ResponseWriter, r *http.Request) {
+	partition, offset, ok := parseDLQRef(w, r)
+	if !ok {
+		return
+	}
+	msg, err := h.Manager.Get(r.Context(), partition, offset)
+	if err != nil {
+		writeDLQError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, msg)
+}
+
+func (h *DLQHandler) Discard(w http.ResponseWriter, r *http.Request) {
+	partition, offset, ok := parseDLQRef(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Note string `json:"note"`
+	}
+	json.NewDecoder(r.Body).Decode(&req) // 사유는 선택 사항
+
+	if err := h.Manager.Discard(r.Context(), partition, offset, req.Note); err != nil {
+		writeDLQError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "메시지가 폐기 처리되었습니다."})
+}
+
+func (h *DLQHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	var req worker.ReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "요청 본문을 해석할 수 없습니다."})
+		return
+	}
+	writeJSON(w, http.StatusAccepted, h.Manager.StartReplay(req))
+}
+
+func (h *DLQHandler) Jobs(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"jobs": h.Manager.Jobs()})
+}
+
+func (h *DLQHandler) Job(w http.ResponseWriter, r *http.Request) {
+	job, err := h.Manager.Job(r.PathValue("id"))
+	if err != nil {
+		writeDLQError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+func (h *DLQHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Partition    int   `json:"partition"`
+		BeforeOffset int64 `json:"before_offset"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.BeforeOffset <= 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "partition과 before_offset이 필요합니다."})
+		return
+	}
+	if err := h.Manager.Purge(r.Context(), req.Partition, req.BeforeOffset); err != nil {
+		writeDLQError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "DLQ 구간이 정리되었습니다."})
+}
+
+func parseDLQRef(w http.ResponseWriter, r *http.Request) (int, int64, bool) {
+	partition, err1 := strconv.Atoi(r.PathValue("partition"))
+	offset, err2 := strconv.ParseInt(r.PathValue("offset"), 10, 64)
+	if err1 != nil || err2 != nil || partition < 0 || offset < 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "잘못된 partition/offset입니다."})
+		return 0, 0, false
+	}
+	return partition, offset, true
+}
+
+func writeDLQError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, worker.ErrDLQMessageNotFound), errors.Is(err, worker.ErrJobNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "시스템 오류가 발생했습니다."})
+	}
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"ticket-system/repository"
+	"ticket-system/service"
+	"time"
+)
+
+/*
+ * EventHandler: 이벤트 카탈로그 조회 및 관리자용 CRUD API
+ * - GET    /events, /events/{id}             : 판매 중인 공연 목록/상세 조회
+ * - POST   /admin/events                     : 공연 등록
+ * - PUT    /admin/events/{id}                : 공연 정보 수정
+ * - DELETE /admin/events/{id}                : 공연 삭제
+ */
+type EventHandler struct {
+	Service *service.TicketService
+}
+
+func NewEventHandler(s *service.TicketService) *EventHandler {
+	return &EventHandler{
+		Service: s,
+	}
+}
+
+// eventRequest: 등록/수정 요청 본문 (재고는 서버가 총 판매 수량 기준으로 관리)
+type eventRequest struct {
+	Name        string    `json:"name"`
+	Venue       string    `json:"venue"`
+	Price       int       `json:"price"`
+	Capacity    int       `json:"capacity"`
+	SaleStartAt time.Time `json:"sale_start_at"`
+	SaleEndAt   time.Time `json:"sale_end_at"`
+	LobbyOpenAt time.Time `json:"lobby_open_at"`
+	MaxActive   int       `json:"max_active"`
+	MaxPerUser  int       `json:"max_per_user"`
+	MaxPerOrder int       `json:"max_per_order"`
+}
+
+func (req eventRequest) toTicket() *repository.Ticket {
+	return &repository.Ticket{
+		Name:        req.Name,
+		Venue:       req.Venue,
+		Price:       req.Price,
+		Capacity:    req.Capacity,
+		SaleStartAt: req.SaleStartAt,
+		SaleEndAt:   req.SaleEndAt,
+		LobbyOpenAt: req.LobbyOpenAt,
+		MaxActive:   req.MaxActive,
+		MaxPerUser:  req.MaxPerUser,
+		MaxPerOrder: req.MaxPerOrder,
+	}
+}
+
+func (h *EventHandler) List(w http.ResponseWriter, r *http.Request) {
+	events, err := h.Service.ListEvents()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, events)
+}
+
+func (h *EventHandler) Get(w http.ResponseWriter, r *http.Request) {
+	eventID, ok := parseEventID(r.PathValue("id"))
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "잘못된 이벤트 ID입니다."})
+		return
+	}
+
+	event, err := h.Service.GetEvent(eventID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, event)
+}
+
+func (h *EventHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req eventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "요청 본문을 해석할 수 없습니다."})
+		return
+	}
+
+	event := req.toTicket()
+	if err := h.Service.CreateEvent(r.Context(), event); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, event)
+}
+
+func (h *EventHandler) Update(w http.ResponseWriter, r *http.Request) {
+	eventID, ok := parseEventID(r.PathValue("id"))
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "잘못된 이벤트 ID입니다."})
+		return
+	}
+
+	var req eventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "요청 본문을 해석할 수 없습니다."})
+		return
+	}
+
+	event := req.toTicket()
+	event.ID = eventID
+	if err := h.Service.UpdateEvent(r.Context(), event); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	updated, err := h.Service.GetEvent(eventID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (h *EventHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	eventID, ok := parseEventID(r.PathValue("id"))
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "잘못된 이벤트 ID입니다."})
+		return
+	}
+
+	if err := h.Service.DeleteEvent(r.Context(), eventID); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseEventID: 쿼리/경로 파라미터의 이벤트 ID를 숫자로 변환
+func parseEventID(raw string) (uint, bool) {
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parseQuantity: 주문 수량 (생략하면 1매)
+func parseQuantity(raw string) (int, bool) {
+	if raw == "" {
+		return 1, true
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError: 서비스 에러를 HTTP 상태 코드로 변환
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrTicketNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidEvent):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrEventBusy):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "시스템 오류가 발생했습니다."})
+	}
+}
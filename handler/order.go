@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"ticket-system/repository"
+	"ticket-system/service"
+)
+
+/*
+ * OrderHandler: 주문 조회 및 주문/항목 단위 취소 API
+ * - GET    /orders?user_id=...&event_id=...                : 주문 이력 (event_id 생략 시 전체 이벤트)
+ * - GET    /orders/{order_id}?user_id=...                   : 주문 상세 (항목 포함)
+ * - DELETE /orders/{order_id}?user_id=...                   : 주문 전체 취소
+ * - DELETE /orders/{order_id}/items/{item_id}?user_id=...   : 주문 항목 1건 취소 (부분 취소)
+ * - POST   /orders/{order_id}/transfer?user_id=...&to_user_id=... : 주문 양도 (남은 티켓 전체)
+ */
+type OrderHandler struct {
+	Service *service.TicketService
+}
+
+func NewOrderHandler(s *service.TicketService) *OrderHandler {
+	return &OrderHandler{
+		Service: s,
+	}
+}
+
+func (h *OrderHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := orderUser(w, r)
+	if !ok {
+		return
+	}
+
+	var eventID uint
+	if raw := r.URL.Query().Get("event_id"); raw != "" {
+		if eventID, ok = parseEventID(raw); !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "event_id가 올바르지 않습니다"})
+			return
+		}
+	}
+
+	orders, err := h.Service.ListOrders(userID, eventID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, orders)
+}
+
+func (h *OrderHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := orderUser(w, r)
+	if !ok {
+		return
+	}
+
+	order, err := h.Service.GetOrder(userID, r.PathValue("order_id"))
+	if errors.Is(err, repository.ErrOrderNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	} else if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, order)
+}
+
+func (h *OrderHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	userID, ok := orderUser(w, r)
+	if !ok {
+		return
+	}
+
+	success, message := h.Service.CancelOrder(userID, r.PathValue("order_id"), nil)
+	writeCancelResult(w, success, message)
+}
+
+func (h *OrderHandler) CancelItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := orderUser(w, r)
+	if !ok {
+		return
+	}
+
+	itemID, err := strconv.ParseUint(r.PathValue("item_id"), 10, 64)
+	if err != nil || itemID == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "item_id가 올바르지 않습니다"})
+		return
+	}
+
+	success, message := h.Service.CancelOrder(userID, r.PathValue("order_id"), []uint{uint(itemID)})
+	writeCancelResult(w, success, message)
+}
+
+func (h *OrderHandler) Transfer(w http.ResponseWriter, r *http.Request) {
+	userID, ok := orderUser(w, r)
+	if !ok {
+		return
+	}
+	toUserID := r.URL.Query().Get("to_user_id")
+	if toUserID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "to_user_id가 필요합니다"})
+		return
+	}
+
+	success, message := h.Service.TransferOrder(userID, toUserID, r.PathValue("order_id"))
+	writeCancelResult(w, success, message)
+}
+
+// orderUser: 주문 API는 본인 주문만 다루므로 user_id 필수
+func orderUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "user_id가 필요합니다"})
+		return "", false
+	}
+	return userID, true
+}
+
+// writeCancelResult: 취소/양도 요청 결과 응답
+func writeCancelResult(w http.ResponseWriter, success bool, message string) {
+	if !success {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": message})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": message})
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"ticket-system/repository"
+	"ticket-system/service"
+	"time"
+)
+
+// queueStreamResync: 푸시 연결에서 Redis 기준 순번으로 다시 맞추는 주기 (연결 유지 신호 겸용)
+const queueStreamResync = 10 * time.Second
+
+/*
+ * QueueHandler: 대기열 상태 조회 API
+ * - GET /queue/status?token=... : 대기 순번, 예상 대기 시간, 입장(승급) 여부 조회 (대기열에 다시 진입하지 않음)
+ * - GET /queue/events?token=... : 같은 내용을 SSE(Server-Sent Events)로 푸시 (lobby → rank → admitted 순으로 전달 후 종료)
+ *
+ * 대기열에 진입하면 WAITING 응답의 queue_token을 받습니다.
+ * ADMITTED가 되면 admission_expires_at 전에 예매 API를 queue_token과 함께 호출해야 합니다.
+ */
+type QueueHandler struct {
+	Service *service.TicketService
+}
+
+func NewQueueHandler(s *service.TicketService) *QueueHandler {
+	return &QueueHandler{
+		Service: s,
+	}
+}
+
+func (h *QueueHandler) Status(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "token이 필요합니다"})
+		return
+	}
+
+	status, err := h.Service.GetQueueStatus(token)
+	if err != nil {
+		writeQueueError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (h *QueueHandler) Events(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "token이 필요합니다"})
+		return
+	}
+
+	sub, err := h.Service.SubscribeQueue(token)
+	if err != nil {
+		writeQueueError(w, err)
+		return
+	}
+	defer sub.Close()
+
+	// SSE 연결은 서버 WriteTimeout보다 오래 유지되므로 쓰기 기한을 해제
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	resync := time.NewTicker(queueStreamResync)
+	defer resync.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-resync.C:
+			sub.Resync()
+		case status := <-sub.Updates:
+			if err := writeQueueEvent(w, rc, status); err != nil {
+				return
+			}
+			// 입장/만료 등 대기가 끝난 상태를 전달하면 연결 종료
+			if status.State != "WAITING" && status.State != "LOBBY" {
+				return
+			}
+		}
+	}
+}
+
+// writeQueueEvent: 대기열 상태를 SSE 이벤트로 전송 (LOBBY는 lobby, WAITING은 rank, ADMITTED는 admitted, 그 외는 closed)
+func writeQueueEvent(w http.ResponseWriter, rc *http.ResponseController, status service.QueueStatus) error {
+	name := "closed"
+	switch status.State {
+	case "LOBBY":
+		name = "lobby"
+	case "WAITING":
+		name = "rank"
+	case "ADMITTED":
+		name = "admitted"
+	}
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
+
+// writeQueueError: 대기열 조회 에러 응답
+func writeQueueError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidQueueToken), errors.Is(err, service.ErrQueueTokenRequired):
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	case errors.Is(err, repository.ErrTicketNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+	default:
+		writeError(w, err)
+	}
+}
+
+// queueTokenUser: 예매 요청에 queue_token이 있으면 검증하고 토큰의 유저를 반환
+// 토큰이 없으면 아직 대기실/대기열에 들어가지 않은 유저만 userID 그대로 진행하고, 이미 들어간 유저는 401로 응답합니다.
+// 토큰도 userID도 없으면 400으로 응답합니다.
+// 토큰의 이벤트나 유저가 요청과 다르면 403으로 응답합니다.
+func queueTokenUser(w http.ResponseWriter, r *http.Request, svc *service.TicketService, userID string, eventID uint) (string, bool) {
+	token := r.URL.Query().Get("queue_token")
+	if token == "" {
+		if userID == "" {
+			// 유저를 알 수 없으면 대기실/대기열 진입 여부를 확인할 수 없으므로 거절
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "user_id 또는 queue_token이 필요합니다"})
+			return "", false
+		}
+		if err := svc.CheckQueueEntry(userID, eventID); err != nil {
+			writeQueueError(w, err)
+			return "", false
+		}
+		return userID, true
+	}
+
+	tokenUser, tokenEvent, err := svc.VerifyQueueToken(token)
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return "", false
+	}
+	if tokenEvent != eventID || (userID != "" && userID != tokenUser) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "다른 유저 또는 이벤트의 대기열 토큰입니다"})
+		return "", false
+	}
+	return tokenUser, true
+}
+
+// writeLobby: 판매 전 대기실 입장 응답 (판매 시작 시각 + 상태 조회용 대기열 토큰)
+func writeLobby(w http.ResponseWriter, r *http.Request, svc *service.TicketService, userID string, eventID uint) {
+	status, token := svc.LobbyStatus(userID, eventID, r.URL.Query().Get("queue_token"))
+
+	// [202 Accepted] 판매 시작 시 무작위 순서로 대기열에 배정되므로 일찍 들어와도 순서상 이점이 없음
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"success":     false,
+		"status":      "LOBBY",
+		"message":     "판매 시작 전 대기실에 입장했습니다. 판매가 시작되면 무작위 순서로 대기열에 배정됩니다.",
+		"opens_at":    status.OpensAt,
+		"queue_token": token,
+	})
+}
+
+// writeWaiting: 대기열 진입 응답 (순번, 예상 대기 시간, 진행률 + 상태 조회용 대기열 토큰)
+func writeWaiting(w http.ResponseWriter, r *http.Request, svc *service.TicketService, userID string, eventID uint, rank int) {
+	status, token := svc.WaitingStatus(userID, eventID, rank, r.URL.Query().Get("queue_token"))
+
+	// [202 Accepted] 요청이 수락되었으나 대기 중임을 명시 (이후에는 /queue/status로 순번 확인)
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"success":                false,
+		"status":                 "WAITING",
+		"message":                "현재 대기열에 진입했습니다.",
+		"rank":                   rank,
+		"estimated_wait_seconds": status.EstimatedWait,
+		"eta":                    status.ETA,
+		"progress_percent":       status.Progress,
+		"queue_token":            token,
+	})
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"net/http"
+	"ticket-system/service"
+)
+
+/*
+ * ReservationHandler: 2단계 예매(홀드 → 확정/해제) API
+ * - POST   /reservations?user_id=...&event_id=...&quantity=...  : 재고 홀드 생성 (10분 유지, 수량 생략 시 1매, queue_token 선택)
+ * - POST   /reservations/{hold_id}/confirm?user_id=...&event_id= : 구매 확정
+ * - DELETE /reservations/{hold_id}?user_id=...&event_id=...      : 홀드 해제 (재고 반환)
+ */
+type ReservationHandler struct {
+	Service *service.TicketService
+}
+
+func NewReservationHandler(s *service.TicketService) *ReservationHandler {
+	return &ReservationHandler{
+		Service: s,
+	}
+}
+
+func (h *ReservationHandler) Reserve(w http.ResponseWriter, r *http.Request) {
+	userID, eventID, ok := userAndEvent(w, r)
+	if !ok {
+		return
+	}
+	if userID, ok = queueTokenUser(w, r, h.Service, userID, eventID); !ok {
+		return
+	}
+
+	quantity, ok := parseQuantity(r.URL.Query().Get("quantity"))
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "quantity는 1 이상이어야 합니다"})
+		return
+	}
+
+	res := h.Service.Reserve(userID, eventID, quantity)
+	switch res.Status {
+	case "RESERVED":
+		writeJSON(w, http.StatusCreated, res)
+	case "ALREADY_RESERVED":
+		// [409 Conflict] 이미 유효한 홀드가 있으면 기존 홀드 ID를 알려줌
+		writeJSON(w, http.StatusConflict, res)
+	case "LOBBY":
+		writeLobby(w, r, h.Service, userID, eventID)
+	case "WAITING":
+		writeWaiting(w, r, h.Service, userID, eventID, res.Rank)
+	case "ALREADY_PURCHASED":
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "1인 구매 한도를 모두 사용했습니다."})
+	case "LIMIT_EXCEEDED":
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "1인 구매 한도를 초과했습니다.", "allowance": res.Allowance})
+	case "ORDER_LIMIT_EXCEEDED":
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "1회 주문 수량 한도를 초과했습니다.", "allowance": res.Allowance})
+	case "SOLD_OUT":
+		writeJSON(w, http.StatusGone, Response{Success: false, Message: "매진되었습니다.", Stock: 0})
+	case "SEAT_REQUIRED":
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "지정석 이벤트입니다. 좌석을 선택해주세요."})
+	default:
+		writeSeatStatus(w, res.Status)
+	}
+}
+
+func (h *ReservationHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	userID, eventID, ok := userAndEvent(w, r)
+	if !ok {
+		return
+	}
+
+	status, remaining, orderID := h.Service.ConfirmReservation(userID, eventID, r.PathValue("hold_id"))
+	switch status {
+	case "SUCCESS":
+		writeJSON(w, http.StatusOK, Response{Success: true, Message: "예매 성공!", Stock: remaining, OrderID: orderID})
+	case "PAYMENT_FAILED":
+		// [402 Payment Required] 결제 거절 (홀드는 만료 전까지 유지되므로 재시도 가능)
+		writeJSON(w, http.StatusPaymentRequired, map[string]string{"error": "결제에 실패했습니다. 다시 시도해주세요.", "order_id": orderID})
+	case "EXPIRED":
+		// [410 Gone] 결제 대기 시간이 지나 홀드가 만료됨
+		writeJSON(w, http.StatusGone, map[string]string{"error": "예약 시간이 만료되었습니다."})
+	case "NOT_FOUND":
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "유효한 예약이 없습니다."})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "시스템 오류가 발생했습니다."})
+	}
+}
+
+func (h *ReservationHandler) Release(w http.ResponseWriter, r *http.Request) {
+	userID, eventID, ok := userAndEvent(w, r)
+	if !ok {
+		return
+	}
+
+	success, message := h.Service.ReleaseReservation(userID, eventID, r.PathValue("hold_id"))
+	if !success {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": message})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": message})
+}
+
+// userAndEvent: user_id, event_id 쿼리 파라미터 공통 검증
+func userAndEvent(w http.ResponseWriter, r *http.Request) (string, uint, bool) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "user_id가 필요합니다"})
+		return "", 0, false
+	}
+	eventID, ok := parseEventID(r.URL.Query().Get("event_id"))
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "event_id가 필요합니다"})
+		return "", 0, false
+	}
+	return userID, eventID, true
+}
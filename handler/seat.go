@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"ticket-system/service"
+)
+
+/*
+ * SeatHandler: 지정석 이벤트의 좌석 배치도 조회 및 좌석 단위 예매 API
+ * - GET  /events/{id}/seats           : 실시간 좌석 배치도
+ * - POST /events/{id}/seats/hold      : 좌석 선점 (여러 좌석 All-or-Nothing, ?queue_token=... 선택)
+ * - POST /events/{id}/seats/release   : 선점 해제
+ * - POST /events/{id}/seats/purchase  : 선점 좌석 구매 확정
+ * - POST /events/{id}/seats/cancel    : 구매 좌석 취소
+ * - POST /admin/events/{id}/seats     : 좌석 배치도 등록
+ */
+type SeatHandler struct {
+	Service *service.TicketService
+}
+
+func NewSeatHandler(s *service.TicketService) *SeatHandler {
+	return &SeatHandler{
+		Service: s,
+	}
+}
+
+// seatRequest: 좌석 선점/구매/취소 요청 본문
+type seatRequest struct {
+	UserID  string `json:"user_id"`
+	SeatIDs []uint `json:"seat_ids"`
+}
+
+func (h *SeatHandler) CreateSeatMap(w http.ResponseWriter, r *http.Request) {
+	eventID, ok := parseEventID(r.PathValue("id"))
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "잘못된 이벤트 ID입니다."})
+		return
+	}
+
+	var req service.SeatMapRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "요청 본문을 해석할 수 없습니다."})
+		return
+	}
+
+	count, err := h.Service.CreateSeatMap(r.Context(), eventID, req)
+	if err != nil {
+		writeSeatError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"event_id": eventID, "seats": count})
+}
+
+func (h *SeatHandler) GetSeatMap(w http.ResponseWriter, r *http.Request) {
+	eventID, ok := parseEventID(r.PathValue("id"))
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "잘못된 이벤트 ID입니다."})
+		return
+	}
+
+	seatMap, err := h.Service.GetSeatMap(r.Context(), eventID)
+	if err != nil {
+		writeSeatError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, seatMap)
+}
+
+func (h *SeatHandler) Hold(w http.ResponseWriter, r *http.Request) {
+	eventID, req, ok := decodeSeatRequest(w, r)
+	if !ok {
+		return
+	}
+
+	userID, ok := queueTokenUser(w, r, h.Service, req.UserID, eventID)
+	if !ok {
+		return
+	}
+
+	status, n := h.Service.HoldSeats(userID, eventID, req.SeatIDs)
+	switch status {
+	case "HELD":
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "좌석이 선점되었습니다. 제한 시간 내에 구매를 완료해주세요.",
+			"seats":   n,
+		})
+	case "LOBBY":
+		writeLobby(w, r, h.Service, userID, eventID)
+	case "WAITING":
+		writeWaiting(w, r, h.Service, userID, eventID, n)
+	case "SEAT_UNAVAILABLE":
+		// [409 Conflict] 요청한 좌석 중 이미 판매/선점된 좌석이 있음
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "이미 판매되었거나 선점된 좌석이 포함되어 있습니다."})
+	default:
+		writeSeatStatus(w, status)
+	}
+}
+
+func (h *SeatHandler) Purchase(w http.ResponseWriter, r *http.Request) {
+	eventID, req, ok := decodeSeatRequest(w, r)
+	if !ok {
+		return
+	}
+
+	status, remaining, orderID := h.Service.BuySeats(req.UserID, eventID, req.SeatIDs)
+	switch status {
+	case "SUCCESS":
+		writeJSON(w, http.StatusOK, Response{
+			Success: true,
+			Message: "예매 성공!",
+			Stock:   remaining,
+			OrderID: orderID,
+		})
+	case "PAYMENT_FAILED":
+		writeJSON(w, http.StatusPaymentRequired, map[string]string{"error": "결제에 실패했습니다.", "order_id": orderID})
+	case "NOT_HELD":
+		// [409 Conflict] 선점이 만료되었거나 본인이 선점한 좌석이 아님
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "선점 시간이 만료되었거나 선점하지 않은 좌석입니다."})
+	default:
+		writeSeatStatus(w, status)
+	}
+}
+
+func (h *SeatHandler) Release(w http.ResponseWriter, r *http.Request) {
+	eventID, req, ok := decodeSeatRequest(w, r)
+	if !ok {
+		return
+	}
+
+	success, message := h.Service.ReleaseSeats(req.UserID, eventID, req.SeatIDs)
+	if !success {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": message})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": message})
+}
+
+func (h *SeatHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	eventID, req, ok := decodeSeatRequest(w, r)
+	if !ok {
+		return
+	}
+
+	success, message := h.Service.CancelSeats(req.UserID, eventID, req.SeatIDs)
+	if !success {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": message})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": message})
+}
+
+func decodeSeatRequest(w http.ResponseWriter, r *http.Request) (uint, seatRequest, bool) {
+	var req seatRequest
+	eventID, ok := parseEventID(r.PathValue("id"))
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "잘못된 이벤트 ID입니다."})
+		return 0, req, false
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "user_id와 seat_ids가 필요합니다."})
+		return 0, req, false
+	}
+	return eventID, req, true
+}
+
+// writeSeatStatus: 좌석 API 공통 실패 상태를 HTTP 응답으로 변환
+func writeSeatStatus(w http.ResponseWriter, status string) {
+	switch status {
+	case "ALREADY_PURCHASED":
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "이미 좌석을 구매한 사용자입니다."})
+	case "INVALID_SEATS":
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "좌석 ID가 올바르지 않습니다. (최대 10석)"})
+	case "NOT_RESERVED":
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "지정석 이벤트가 아닙니다."})
+	case "NOT_FOUND":
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "존재하지 않는 이벤트입니다."})
+	case "NOT_ON_SALE":
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "판매 기간이 아닙니다."})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "시스템 오류가 발생했습니다."})
+	}
+}
+
+func writeSeatError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrSeatMapExists), errors.Is(err, service.ErrSeatMapHasSales), errors.Is(err, service.ErrEventBusy):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidSeatMap), errors.Is(err, service.ErrNotReservedSeat):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		writeError(w, err)
+	}
+}
@@ -11,6 +11,7 @@ type Response struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
 	Stock   int    `json:"stock"`
+	OrderID string `json:"order_id,omitempty"`
 }
 
 /*
@@ -28,20 +29,37 @@ func NewTicketHandler(s *service.TicketService) *TicketHandler {
 	}
 }
 
-// ServeHTTP: 티켓 예매 요청(GET /ticket?user_id=...) 처리 핸들러
+// ServeHTTP: 티켓 예매 요청(GET /ticket?user_id=...&event_id=...&quantity=...&queue_token=...) 처리 핸들러
+// 대기열을 거친 유저는 WAITING 응답으로 받은 queue_token을 함께 보냅니다. (토큰이 있으면 user_id 생략 가능, 둘 다 없으면 400)
 func (h *TicketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	eventID, ok := parseEventID(r.URL.Query().Get("event_id"))
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "event_id가 필요합니다"})
+		return
+	}
+
 	// 1. 유저 식별 (실제 서비스에서는 JWT나 세션 등을 활용하도록 확장 가능)
-	userID := r.URL.Query().Get("user_id")
-	if userID == "" {
-		userID = "anonymous"
+	userID, ok := queueTokenUser(w, r, h.Service, r.URL.Query().Get("user_id"), eventID)
+	if !ok {
+		return
+	}
+	quantity, ok := parseQuantity(r.URL.Query().Get("quantity"))
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "quantity는 1 이상이어야 합니다"})
+		return
 	}
 
 	// 2. 비즈니스 로직 호출 (대기열 기반 예매 처리)
-	// status: SUCCESS(성공), WAITING(대기), SOLD_OUT(매진), ALREADY_PURCHASED(중복)
-	// remaining: 남은 재고 수량 또는 대기열에서의 순번(rank)
-	status, remaining := h.Service.BuyTicket(userID)
+	// status: SUCCESS(성공), LOBBY(판매 전 대기실), WAITING(대기), SOLD_OUT(매진), ALREADY_PURCHASED(구매 한도 소진),
+	//         LIMIT_EXCEEDED(누적 한도 초과), ORDER_LIMIT_EXCEEDED(1회 주문 한도 초과),
+	//         NOT_FOUND(없는 이벤트), NOT_ON_SALE(판매 기간 아님)
+	// remaining: 남은 재고 수량, 대기열에서의 순번(rank) 또는 남은 구매 한도
+	// orderID: 결제를 시도한 주문 ID (SUCCESS, PAYMENT_FAILED)
+	status, remaining, orderID := h.Service.BuyTicket(userID, eventID, quantity)
 
 	// 3. 서비스 결과에 따른 HTTP 상태 코드 및 페이로드 구성
 	switch status {
@@ -50,21 +68,30 @@ func (h *TicketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			Success: true,
 			Message: "예매 성공!",
 			Stock:   remaining,
+			OrderID: orderID,
 		})
+	case "LOBBY":
+		writeLobby(w, r, h.Service, userID, eventID)
 	case "WAITING":
-		// [202 Accepted] 요청이 수락되었으나 대기 중임을 명시 (순번 정보 포함)
-		w.WriteHeader(http.StatusAccepted)
+		writeWaiting(w, r, h.Service, userID, eventID, remaining)
+
+	case "ALREADY_PURCHASED":
+		// [400 Bad Request] 1인 구매 한도를 모두 사용함
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "1인 구매 한도를 모두 사용했습니다."})
+
+	case "LIMIT_EXCEEDED":
+		// [400 Bad Request] 이번 수량을 더하면 1인 누적 한도를 넘음 (남은 한도 안내)
+		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"status":  "WAITING",
-			"message": "현재 대기열에 진입했습니다.",
-			"rank":    remaining,
+			"error":     "1인 구매 한도를 초과했습니다.",
+			"allowance": remaining,
 		})
 
-	case "ALREADY_PURCHASED":
-		// [400 Bad Request] 중복 구매 시도 제한
+	case "ORDER_LIMIT_EXCEEDED":
+		// [400 Bad Request] 1회 주문 수량 한도 초과
 		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "티켓은 1인 1매입니다."})
+		json.NewEncoder(w).Encode(map[string]string{"error": "1회 주문 수량 한도를 초과했습니다."})
 
 	case "SOLD_OUT":
 		// [410 Gone] 자원이 더 이상 존재하지 않음을 명시 (매진)
@@ -75,6 +102,26 @@ func (h *TicketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			Stock:   0,
 		})
 
+	case "PAYMENT_FAILED":
+		// [402 Payment Required] 결제 승인/매입 실패
+		w.WriteHeader(http.StatusPaymentRequired)
+		json.NewEncoder(w).Encode(map[string]string{"error": "결제에 실패했습니다.", "order_id": orderID})
+
+	case "SEAT_REQUIRED":
+		// [400 Bad Request] 지정석 이벤트는 좌석 API로 예매
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "지정석 이벤트입니다. 좌석을 선택해주세요."})
+
+	case "NOT_FOUND":
+		// [404 Not Found] 존재하지 않는 이벤트
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "존재하지 않는 이벤트입니다."})
+
+	case "NOT_ON_SALE":
+		// [403 Forbidden] 판매 시작 전이거나 판매가 종료된 이벤트
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "판매 기간이 아닙니다."})
+
 	default:
 		// [500 Internal Server Error] 시스템 예외 상황
 		w.WriteHeader(http.StatusInternalServerError)
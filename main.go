@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
 	"ticket-system/handler"
 	"ticket-system/metrics"
+	"ticket-system/payment"
 	"ticket-system/repository"
 	"ticket-system/service"
 	"ticket-system/worker"
@@ -25,13 +28,6 @@ func main() {
 	})
 
 	ctx := context.Background()
-	stockKey := "ticket_stock:concert_2026"
-
-	// 값이 없을 때만 1000으로 초기화
-	rdb.Set(ctx, stockKey, 1000, 0)
-	rdb.Del(ctx, "purchased_users:concert_2026")
-
-	metrics.TicketStockLevel.Set(1000)
 
 	// 2. MySQL 연결 설정 (docker-compose의 ticket-mysql 사용)
 	// 비밀번호와 DB명은 docker-compose.yml 설정과 동일하게 유지
@@ -53,12 +49,31 @@ func main() {
 	// 3. Repository 생성
 	redisRepo := &repository.RedisRepository{Client: rdb}
 	mysqlRepo := &repository.MySQLRepository{DB: db}
+	seatRepo := repository.NewMySQLSeatRepository(db)
 
 	// Kafka Repository 생성 (Producer 역할)
 	kafkaRepo := repository.NewKafkaRepository([]string{"localhost:9092"}, "ticket-topic")
 
 	// 4. Service 조립 (오류 해결: kafkaRepo 추가)
-	svc := service.NewTicketService(redisRepo, mysqlRepo, kafkaRepo)
+	// 실제 PG사 연동 전까지는 인프로세스 Fake 결제 게이트웨이 사용
+	paymentGateway := payment.NewFakeGateway()
+
+	svc := service.NewTicketService(redisRepo, mysqlRepo, seatRepo, kafkaRepo, redisRepo, paymentGateway)
+
+	// 대기열 토큰 서명 키 (여러 서버를 띄우면 같은 값을 설정, 미설정 시 서버마다 임의 생성)
+	if secret := os.Getenv("QUEUE_TOKEN_SECRET"); secret != "" {
+		svc.SetQueueSecret([]byte(secret))
+	}
+
+	// Redis 판매 상태 복원 (재고 키가 없는 이벤트만 MySQL + 미처리 Kafka 메시지 기준으로 복원)
+	if err := svc.WarmStart(ctx, "purchase-group"); err != nil {
+		log.Fatal("Redis 상태 복원 실패: ", err)
+	}
+
+	// Redis 연속 5회 실패 시 MySQL 직접 판매 모드로 전환, Redis 응답 확인 후 재동기화하여 복귀
+	svc.EnableFailover(repository.NewCircuitBreaker(5), func(ctx context.Context) error {
+		return rdb.Ping(ctx).Err()
+	}, "purchase-group")
 
 	// 5. Kafka Consumer Worker 실행
 	// 서버가 켜질 때 백그라운드에서 Kafka 메시지를 읽어 DB에 저장합니다.
@@ -67,21 +82,36 @@ func main() {
 		"ticket-topic",
 		"purchase-group",
 		mysqlRepo,
+		seatRepo,
 		kafkaRepo,
 	)
-	go purchaseWorker.Start()                       // 고루틴으로 실행
-	go svc.StartPromoter(context.Background(), 100) // 100명까지 동시 예매 허용
+	go purchaseWorker.Start() // 고루틴으로 실행
+
+	// Outbox Relay: Redis 확정과 함께 기록된 이벤트를 ticket-topic으로 발행
+	// 모든 서버에서 실행하지만 Redis 리더 리스(outbox:relay-leader)를 가진 한 대만 발행합니다. (유저별 이벤트 순서 보장)
+	outboxRelay := worker.NewOutboxRelay(redisRepo, kafkaRepo)
+	go outboxRelay.Start(context.Background())
+	go svc.StartPromoter(context.Background()) // 이벤트별 max_active 만큼 동시 예매 허용
+	go svc.StartQueueHub(context.Background()) // 승급 알림을 이 서버의 대기열 푸시 연결로 전달
+	go svc.StartReaper(context.Background())   // 만료된 결제 대기 홀드 재고 반환
+	go svc.StartFailoverMonitor(context.Background())
 	go func() {
 		for {
-			time.Sleep(500 * time.Millisecond) // 1초마다 Redis 실제 값 확인
-			val, err := rdb.Get(context.Background(), stockKey).Int()
-			if err == nil {
+			time.Sleep(500 * time.Millisecond) // 0.5초마다 이벤트별 Redis 실제 값 확인
+			events, err := svc.ListEvents()
+			if err != nil {
+				continue
+			}
+			for _, ev := range events {
+				val, err := redisRepo.GetStock(context.Background(), ev.Name)
+				if err != nil {
+					continue
+				}
 				// Redis의 진짜 값이 0보다 작으면(동시성 이슈 등) 0으로, 아니면 실제 값 그대로 세팅
 				if val < 0 {
-					metrics.TicketStockLevel.Set(0)
-				} else {
-					metrics.TicketStockLevel.Set(float64(val))
+					val = 0
 				}
+				metrics.TicketStockLevel.WithLabelValues(ev.Name).Set(float64(val))
 			}
 		}
 	}()
@@ -100,6 +130,44 @@ func main() {
 	mux := http.NewServeMux()
 	mux.Handle("/ticket", h)
 
+	// 이벤트 카탈로그 (조회 + 관리자 CRUD)
+	eh := handler.NewEventHandler(svc)
+	mux.HandleFunc("GET /events", eh.List)
+	mux.HandleFunc("GET /events/{id}", eh.Get)
+	mux.HandleFunc("GET /admin/events", eh.List)
+	mux.HandleFunc("POST /admin/events", eh.Create)
+	mux.HandleFunc("GET /admin/events/{id}", eh.Get)
+	mux.HandleFunc("PUT /admin/events/{id}", eh.Update)
+	mux.HandleFunc("DELETE /admin/events/{id}", eh.Delete)
+
+	// 대기열 상태 조회/푸시 (WAITING 응답의 queue_token으로 순번/입장 여부 확인)
+	qh := handler.NewQueueHandler(svc)
+	mux.HandleFunc("GET /queue/status", qh.Status)
+	mux.HandleFunc("GET /queue/events", qh.Events)
+
+	// 2단계 예매 (홀드 → 확정/해제)
+	rh := handler.NewReservationHandler(svc)
+	mux.HandleFunc("POST /reservations", rh.Reserve)
+	mux.HandleFunc("POST /reservations/{hold_id}/confirm", rh.Confirm)
+	mux.HandleFunc("DELETE /reservations/{hold_id}", rh.Release)
+
+	// 지정석 (좌석 배치도 + 좌석 단위 선점/구매/취소)
+	sh := handler.NewSeatHandler(svc)
+	mux.HandleFunc("POST /admin/events/{id}/seats", sh.CreateSeatMap)
+	mux.HandleFunc("GET /events/{id}/seats", sh.GetSeatMap)
+	mux.HandleFunc("POST /events/{id}/seats/hold", sh.Hold)
+	mux.HandleFunc("POST /events/{id}/seats/release", sh.Release)
+	mux.HandleFunc("POST /events/{id}/seats/purchase", sh.Purchase)
+	mux.HandleFunc("POST /events/{id}/seats/cancel", sh.Cancel)
+
+	// 주문 조회 + 주문/항목 단위 취소 + 양도
+	oh := handler.NewOrderHandler(svc)
+	mux.HandleFunc("GET /orders", oh.List)
+	mux.HandleFunc("GET /orders/{order_id}", oh.Get)
+	mux.HandleFunc("DELETE /orders/{order_id}", oh.Cancel)
+	mux.HandleFunc("DELETE /orders/{order_id}/items/{item_id}", oh.CancelItem)
+	mux.HandleFunc("POST /orders/{order_id}/transfer", oh.Transfer)
+
 	// 취소 핸들러 등록
 	mux.HandleFunc("/cancel", func(w http.ResponseWriter, r *http.Request) {
 		userID := r.URL.Query().Get("user_id")
@@ -108,8 +176,14 @@ func main() {
 			fmt.Fprint(w, `{"error": "user_id가 필요합니다"}`)
 			return
 		}
+		eventID, err := strconv.ParseUint(r.URL.Query().Get("event_id"), 10, 64)
+		if err != nil || eventID == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error": "event_id가 필요합니다"}`)
+			return
+		}
 
-		success, message := svc.CancelTicket(userID)
+		success, message := svc.CancelTicket(userID, uint(eventID))
 		if !success {
 			w.WriteHeader(http.StatusBadRequest)
 			fmt.Fprintf(w, `{"error": "%s"}`, message)
@@ -120,10 +194,20 @@ func main() {
 		fmt.Fprintf(w, `{"message": "%s"}`, message)
 	})
 
-	mux.HandleFunc("/admin/recover-dlq", func(w http.ResponseWriter, r *http.Request) {
-		go purchaseWorker.ProcessDLQ() // 별도 고루틴으로 실행
-		fmt.Fprint(w, `{"message": "DLQ 복구 프로세스가 시작되었습니다."}`)
-	})
+	// 구매 변경 이력 (감사 로그) 조회
+	ah := handler.NewAuditHandler(svc)
+	mux.HandleFunc("GET /admin/users/{user_id}/history", ah.History)
+
+	// DLQ 관리 (목록/상세 조회, 선택 재처리, 폐기, 정리)
+	dh := handler.NewDLQHandler(worker.NewDLQManager(kafkaRepo, redisRepo, purchaseWorker))
+	mux.HandleFunc("GET /admin/dlq", dh.Partitions)
+	mux.HandleFunc("GET /admin/dlq/messages", dh.List)
+	mux.HandleFunc("GET /admin/dlq/messages/{partition}/{offset}", dh.Get)
+	mux.HandleFunc("POST /admin/dlq/messages/{partition}/{offset}/discard", dh.Discard)
+	mux.HandleFunc("POST /admin/dlq/replay", dh.Replay)
+	mux.HandleFunc("GET /admin/dlq/jobs", dh.Jobs)
+	mux.HandleFunc("GET /admin/dlq/jobs/{id}", dh.Job)
+	mux.HandleFunc("POST /admin/dlq/purge", dh.Purge)
 
 	// 8. 서버 실행 설정
 	server := &http.Server{
@@ -134,8 +218,12 @@ func main() {
 	}
 
 	log.Println("🚀 비동기 티켓 시스템 서버 시작 (:8080)...")
-	log.Println("- 예매: /ticket")
-	log.Println("- 취소: /cancel")
+	log.Println("- 예매: /ticket?user_id=...&event_id=...")
+	log.Println("- 취소: /cancel?user_id=...&event_id=...")
+	log.Println("- 대기열: /queue/status?token=..., /queue/events?token=... (SSE)")
+	log.Println("- 예약: /reservations (홀드 → 확정/해제)")
+	log.Println("- 주문: /orders (조회, 주문/항목 단위 취소, 양도)")
+	log.Println("- 이벤트: /events, /admin/events")
 
 	if err := server.ListenAndServe(); err != nil {
 		log.Fatal("서버 시작 실패: ", err)
@@ -12,9 +12,39 @@ var (
 		Help: "Total number of ticket purchase requests",
 	})
 
-	// 2. 현재 Redis에 남아있는 재고 (Gauge: 올라갔다 내려갔다 하는 값)
-	TicketStockLevel = promauto.NewGauge(prometheus.GaugeOpts{
+	// 2. 현재 Redis에 남아있는 재고 (Gauge: 올라갔다 내려갔다 하는 값, 이벤트별 라벨)
+	TicketStockLevel = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "ticket_stock_level",
 		Help: "Current ticket stock level in Redis",
+	}, []string{"ticket"})
+
+	// 3. 결제 대기 시간 초과로 재고가 반환된 홀드 수
+	ReservationsExpired = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ticket_reservations_expired_total",
+		Help: "Total number of reservation holds released by the reaper",
+	}, []string{"ticket"})
+
+	// 4. Redis 차단기 상태 (1이면 MySQL 직접 판매 모드)
+	RedisCircuitOpen = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ticket_redis_circuit_open",
+		Help: "1 if the Redis circuit breaker is open and sales fall back to MySQL",
 	})
+
+	// 5. Redis 장애 중 MySQL에서 직접 판매한 건수
+	DegradedPurchases = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ticket_degraded_purchases_total",
+		Help: "Total number of purchases sold directly from MySQL while Redis was unavailable",
+	}, []string{"ticket"})
+
+	// 장애 모드 판매분을 Redis에 반영했더니 재고가 음수가 된 수량 (차단기가 일부 서버에서만 열린 경우 등)
+	FailoverOversold = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ticket_failover_oversold_total",
+		Help: "Tickets oversold because direct MySQL sales during a Redis outage overlapped Redis sales",
+	}, []string{"ticket"})
+
+	// 6. 만료 시각이 지나 Active Set에서 정리된 세션 수 (요청 중 프로세스 종료, 승급 후 미복귀 등)
+	QueueSessionsEvicted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ticket_queue_sessions_evicted_total",
+		Help: "Total number of expired active-set sessions evicted by the promoter",
+	}, []string{"ticket"})
 )
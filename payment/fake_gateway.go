@@ -0,0 +1,104 @@
+package payment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+)
+
+/*
+ * FakeGateway: 테스트 및 로컬 실행용 인프로세스 결제 게이트웨이
+ * 실제 PG사처럼 idempotencyKey 단위로 결과를 기억하며, Decline 훅으로 거절 상황을 재현할 수 있습니다.
+ */
+type FakeGateway struct {
+	// Decline이 true를 반환하면 승인을 거절합니다. (nil이면 항상 승인)
+	Decline func(req AuthorizeRequest) bool
+
+	mu       sync.Mutex
+	payments map[string]*Payment
+	results  map[string]Payment // idempotencyKey -> 처리 결과
+}
+
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{
+		payments: make(map[string]*Payment),
+		results:  make(map[string]Payment),
+	}
+}
+
+func (g *FakeGateway) Authorize(ctx context.Context, req AuthorizeRequest) (*Payment, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if res, ok := g.results[req.IdempotencyKey]; ok {
+		return &res, nil
+	}
+	if req.Amount < 0 || (g.Decline != nil && g.Decline(req)) {
+		return nil, ErrDeclined
+	}
+
+	p := &Payment{ID: "pay_" + randomHex(), Status: StatusAuthorized, Amount: req.Amount}
+	g.payments[p.ID] = p
+	g.results[req.IdempotencyKey] = *p
+	return p, nil
+}
+
+func (g *FakeGateway) Capture(ctx context.Context, paymentID string, idempotencyKey string) (*Payment, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if res, ok := g.results[idempotencyKey]; ok {
+		return &res, nil
+	}
+	p, ok := g.payments[paymentID]
+	if !ok {
+		return nil, ErrPaymentNotFound
+	}
+	if p.Status != StatusAuthorized {
+		return nil, ErrInvalidState
+	}
+
+	p.Status = StatusCaptured
+	g.results[idempotencyKey] = *p
+	return p, nil
+}
+
+// Refund: 매입 전이면 승인 취소, 매입 후면 amount만큼 환불 (누적 환불액이 결제액을 넘을 수 없음)
+func (g *FakeGateway) Refund(ctx context.Context, paymentID string, amount int, idempotencyKey string) (*Payment, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if res, ok := g.results[idempotencyKey]; ok {
+		return &res, nil
+	}
+	p, ok := g.payments[paymentID]
+	if !ok {
+		return nil, ErrPaymentNotFound
+	}
+
+	switch p.Status {
+	case StatusAuthorized:
+		p.Status = StatusRefunded
+	case StatusCaptured, StatusPartial:
+		if amount <= 0 || p.Refunded+amount > p.Amount {
+			return nil, ErrInvalidState
+		}
+		p.Refunded += amount
+		p.Status = StatusPartial
+		if p.Refunded == p.Amount {
+			p.Status = StatusRefunded
+		}
+	default:
+		return nil, ErrInvalidState
+	}
+
+	g.results[idempotencyKey] = *p
+	return p, nil
+}
+
+func randomHex() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,47 @@
+package payment
+
+import (
+	"context"
+	"errors"
+)
+
+// 결제 상태
+const (
+	StatusAuthorized = "AUTHORIZED" // 승인(한도 확보)만 된 상태
+	StatusCaptured   = "CAPTURED"   // 매입 완료 (실제 결제)
+	StatusRefunded   = "REFUNDED"   // 전액 환불 또는 승인 취소
+	StatusPartial    = "PARTIALLY_REFUNDED"
+)
+
+var (
+	ErrDeclined        = errors.New("결제가 거절되었습니다")
+	ErrPaymentNotFound = errors.New("결제 정보를 찾을 수 없습니다")
+	ErrInvalidState    = errors.New("현재 결제 상태에서 처리할 수 없는 요청입니다")
+)
+
+// AuthorizeRequest: 결제 승인 요청
+type AuthorizeRequest struct {
+	IdempotencyKey string // 같은 키로 재요청하면 같은 결과를 반환 (홀드/주문 ID 기반)
+	UserID         string
+	TicketName     string
+	Amount         int
+}
+
+// Payment: 결제 게이트웨이가 관리하는 결제 건
+type Payment struct {
+	ID       string `json:"payment_id"`
+	Status   string `json:"status"`
+	Amount   int    `json:"amount"`
+	Refunded int    `json:"refunded"`
+}
+
+/*
+ * PaymentGateway Interface
+ * 외부 PG사 연동을 추상화합니다. 모든 요청은 idempotencyKey를 받아
+ * 네트워크 재시도나 중복 요청이 발생해도 한 번만 처리되도록 보장해야 합니다.
+ */
+type PaymentGateway interface {
+	Authorize(ctx context.Context, req AuthorizeRequest) (*Payment, error)
+	Capture(ctx context.Context, paymentID string, idempotencyKey string) (*Payment, error)
+	Refund(ctx context.Context, paymentID string, amount int, idempotencyKey string) (*Payment, error)
+}
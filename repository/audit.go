@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"gorm.io/gorm"
+)
+
+// 감사 로그 변경 종류
+const (
+	AuditPurchase     = "PURCHASE"      // 구매 내역 저장
+	AuditCancel       = "CANCEL"        // 구매 내역 취소 (전체 또는 주문 항목/좌석 단위)
+	AuditRepair       = "REPAIR"        // 관리자/Reconciler의 데이터 복구
+	AuditTransfer     = "TRANSFER"      // 다른 유저에게 양도 (보낸 유저/받은 유저 각각 기록)
+	AuditRefundFailed = "REFUND_FAILED" // 취소는 반영되었지만 환불이 실패함 (운영자 재처리 대상)
+)
+
+// 감사 로그 변경 주체
+const (
+	ActorWorker     = "worker"       // Kafka 메시지 처리 (재시도 토픽 포함)
+	ActorDLQReplay  = "dlq-replay"   // 관리자 DLQ 재처리
+	ActorReconciler = "reconciler"   // 정합성 검사 복구
+	ActorFailover   = "api-failover" // Redis 장애 중 API 서버의 MySQL 직접 판매
+	ActorAPI        = "api"          // API 서버의 취소 요청 처리
+)
+
+// AuditSource: 변경 주체와 변경을 일으킨 원본 메시지 위치 (메시지가 아닌 변경은 Source가 빈 값)
+type AuditSource struct {
+	Actor  string
+	Source string
+}
+
+// MessageAudit: Kafka 메시지로 인한 변경의 감사 정보
+func MessageAudit(actor string, m kafka.Message) AuditSource {
+	return AuditSource{Actor: actor, Source: MessageSource(m)}
+}
+
+// MessageSource: 메시지의 원본 위치("topic/partition@offset")
+// 재시도/DLQ 토픽을 거친 메시지는 최초로 수신된 위치(original_* 헤더)를 사용합니다.
+func MessageSource(m kafka.Message) string {
+	headers := Headers(m)
+	if topic := headers[HeaderOriginalTopic]; topic != "" {
+		return fmt.Sprintf("%s/%s@%s", topic, headers[HeaderOriginalPartition], headers[HeaderOriginalOffset])
+	}
+	return fmt.Sprintf("%s/%d@%d", m.Topic, m.Partition, m.Offset)
+}
+
+// AuditLog 구매 상태 변경 이력 (추가만 하고 수정/삭제하지 않음)
+type AuditLog struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     string    `gorm:"column:user_id;index:idx_audit_user;not null" json:"user_id"`
+	TicketName string    `gorm:"column:ticket_name;index:idx_audit_user;index:idx_audit_actor,priority:2;not null" json:"ticket"`
+	Action     string    `gorm:"column:action;not null" json:"action"`
+	Quantity   int       `gorm:"column:quantity" json:"quantity"`
+	OrderID    string    `gorm:"column:order_id" json:"order_id,omitempty"`
+	PaymentID  string    `gorm:"column:payment_id" json:"payment_id,omitempty"`
+	Actor      string    `gorm:"column:actor;index:idx_audit_actor,priority:1;not null" json:"actor"`
+	Source     string    `gorm:"column:source" json:"source,omitempty"`
+	Detail     string    `gorm:"column:detail" json:"detail,omitempty"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+}
+
+func (AuditLog) TableName() string {
+	return "purchase_audit_logs"
+}
+
+// purchaseAudit: 구매 내역 한 건에 대한 감사 로그
+func purchaseAudit(action string, p *Purchase, src AuditSource) AuditLog {
+	return AuditLog{
+		UserID:     p.UserID,
+		TicketName: p.TicketName,
+		Action:     action,
+		Quantity:   p.Quantity,
+		OrderID:    p.OrderID,
+		PaymentID:  p.PaymentID,
+		Actor:      src.Actor,
+		Source:     src.Source,
+	}
+}
+
+// appendAudit: 상태 변경과 같은 트랜잭션에서 감사 로그 기록 (변경이 롤백되면 이력도 남지 않음)
+func appendAudit(tx *gorm.DB, logs ...AuditLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return tx.Create(&logs).Error
+}
+
+// AppendAudit: MySQL 밖(Redis)에서 일어난 변경의 감사 로그 기록 (Reconciler 구매자 명단 복구 등)
+func (r *MySQLRepository) AppendAudit(entry *AuditLog) error {
+	return r.DB.Create(entry).Error
+}
+
+// ListAudit: 유저의 변경 이력 조회 (오래된 순, ticketName이 비어 있으면 전체 이벤트)
+// ListFailoverSales: Redis 장애 중 MySQL에서 직접 판매한 주문의 구매 내역 (이후 취소된 주문 포함)
+// 감사 로그(api-failover)에 남은 판매 기록이 기준이므로, 판매한 서버가 재시작되어도 Redis 재동기화 대상을 잃지 않습니다.
+func (r *MySQLRepository) ListFailoverSales(ticketName string) ([]Purchase, error) {
+	return failoverSales(r.DB, ticketName)
+}
+
+func failoverSales(tx *gorm.DB, ticketName string) ([]Purchase, error) {
+	orders := tx.Model(&AuditLog{}).Select("order_id").
+		Where("actor = ? AND action = ? AND ticket_name = ? AND order_id <> ''", ActorFailover, AuditPurchase, ticketName)
+	var purchases []Purchase
+	err := tx.Unscoped().Where("ticket_name = ? AND order_id IN (?)", ticketName, orders).Order("id").Find(&purchases).Error
+	return purchases, err
+}
+
+func (r *MySQLRepository) ListAudit(userID string, ticketName string) ([]AuditLog, error) {
+	query := r.DB.Where("user_id = ?", userID)
+	if ticketName != "" {
+		query = query.Where("ticket_name = ?", ticketName)
+	}
+	var logs []AuditLog
+	err := query.Order("id").Find(&logs).Error
+	return logs, err
+}
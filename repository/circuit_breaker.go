@@ -0,0 +1,365 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrCircuitOpen: Redis 장애로 차단기가 열려 호출하지 않았음
+var ErrCircuitOpen = errors.New("Redis 차단기가 열려 있습니다")
+
+/*
+ * CircuitBreaker: Redis 연속 실패를 감지하는 차단기
+ * - Closed: 정상 호출, 연속 실패가 Threshold에 도달하면 Open
+ * - Open: 호출하지 않고 ErrCircuitOpen 반환 (서비스는 MySQL 직접 판매 모드로 전환)
+ * - Open → Closed는 자동으로 일어나지 않고, 헬스 체크와 상태 재동기화가 끝난 뒤 Close로만 복귀합니다.
+ *   (재동기화 전에 Redis로 돌아가면 장애 중 판매분이 반영되지 않은 재고로 초과 판매가 발생)
+ */
+type CircuitBreaker struct {
+	Threshold int
+
+	mu       sync.Mutex
+	failures int
+	open     bool
+	openedAt time.Time
+	onChange func(open bool)
+}
+
+func NewCircuitBreaker(threshold int) *CircuitBreaker {
+	return &CircuitBreaker{Threshold: threshold}
+}
+
+// OnChange: 차단기 상태가 바뀔 때 호출할 함수 등록 (메트릭/로그용)
+func (b *CircuitBreaker) OnChange(fn func(open bool)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onChange = fn
+}
+
+// Allow: 차단기가 열려 있으면 ErrCircuitOpen
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.open {
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// Record: 호출 결과 기록 (키 없음(redis.Nil)과 요청 취소는 Redis 장애로 보지 않음)
+func (b *CircuitBreaker) Record(err error) {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) || errors.Is(err, ErrCircuitOpen) {
+		b.mu.Lock()
+		if err == nil && !b.open {
+			b.failures = 0
+		}
+		b.mu.Unlock()
+		return
+	}
+
+	b.mu.Lock()
+	b.failures++
+	tripped := !b.open && b.failures >= b.Threshold
+	if tripped {
+		b.open, b.openedAt = true, time.Now()
+	}
+	onChange := b.onChange
+	b.mu.Unlock()
+
+	if tripped && onChange != nil {
+		onChange(true)
+	}
+}
+
+// IsOpen: 차단기가 열려 있는지 (장애 모드 여부)
+func (b *CircuitBreaker) IsOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open
+}
+
+// OpenedAt: 차단기가 열린 시각
+func (b *CircuitBreaker) OpenedAt() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.openedAt
+}
+
+// Close: 재동기화가 끝난 뒤 정상 모드로 복귀
+func (b *CircuitBreaker) Close() {
+	b.mu.Lock()
+	wasOpen := b.open
+	b.open, b.failures = false, 0
+	onChange := b.onChange
+	b.mu.Unlock()
+
+	if wasOpen && onChange != nil {
+		onChange(false)
+	}
+}
+
+/*
+ * BreakerLockRepository: LockRepository의 모든 호출을 CircuitBreaker로 감싼 구현
+ * 차단기가 열리면 Redis를 호출하지 않고 바로 ErrCircuitOpen을 반환하여 타임아웃 대기를 없앱니다.
+ */
+type BreakerLockRepository struct {
+	Next    LockRepository
+	Breaker *CircuitBreaker
+}
+
+func NewBreakerLockRepository(next LockRepository, breaker *CircuitBreaker) *BreakerLockRepository {
+	return &BreakerLockRepository{
+		Next:    next,
+		Breaker: breaker,
+	}
+}
+
+func guard[T any](b *CircuitBreaker, fn func() (T, error)) (T, error) {
+	if err := b.Allow(); err != nil {
+		var zero T
+		return zero, err
+	}
+	v, err := fn()
+	b.Record(err)
+	return v, err
+}
+
+func guardErr(b *CircuitBreaker, fn func() error) error {
+	if err := b.Allow(); err != nil {
+		return err
+	}
+	err := fn()
+	b.Record(err)
+	return err
+}
+
+func (r *BreakerLockRepository) GetStock(ctx context.Context, ticketName string) (int, error) {
+	return guard(r.Breaker, func() (int, error) { return r.Next.GetStock(ctx, ticketName) })
+}
+
+func (r *BreakerLockRepository) HasStock(ctx context.Context, ticketName string) (bool, error) {
+	return guard(r.Breaker, func() (bool, error) { return r.Next.HasStock(ctx, ticketName) })
+}
+
+func (r *BreakerLockRepository) DecreaseStock(ctx context.Context, ticketName string) (int, error) {
+	return guard(r.Breaker, func() (int, error) { return r.Next.DecreaseStock(ctx, ticketName) })
+}
+
+func (r *BreakerLockRepository) IncreaseStock(ctx context.Context, ticketName string) (int, error) {
+	return guard(r.Breaker, func() (int, error) { return r.Next.IncreaseStock(ctx, ticketName) })
+}
+
+func (r *BreakerLockRepository) InitStock(ctx context.Context, ticketName string, stock int) error {
+	return guardErr(r.Breaker, func() error { return r.Next.InitStock(ctx, ticketName, stock) })
+}
+
+func (r *BreakerLockRepository) ResizeStock(ctx context.Context, ticketName string, delta int) (int, error) {
+	return guard(r.Breaker, func() (int, error) { return r.Next.ResizeStock(ctx, ticketName, delta) })
+}
+
+func (r *BreakerLockRepository) AdjustStock(ctx context.Context, ticketName string, delta int) (int, error) {
+	return guard(r.Breaker, func() (int, error) { return r.Next.AdjustStock(ctx, ticketName, delta) })
+}
+
+func (r *BreakerLockRepository) SeedSaleState(ctx context.Context, ticketName string, stock int, purchased map[string]int, soldSeats map[uint]string, failoverOrders []string) (bool, error) {
+	return guard(r.Breaker, func() (bool, error) {
+		return r.Next.SeedSaleState(ctx, ticketName, stock, purchased, soldSeats, failoverOrders)
+	})
+}
+
+func (r *BreakerLockRepository) ApplyDirectSale(ctx context.Context, ticketName string, orderID string, userID string, quantity int, cancelled bool) (bool, int, error) {
+	var stock int
+	applied, err := guard(r.Breaker, func() (bool, error) {
+		var (
+			applied bool
+			err     error
+		)
+		applied, stock, err = r.Next.ApplyDirectSale(ctx, ticketName, orderID, userID, quantity, cancelled)
+		return applied, err
+	})
+	return applied, stock, err
+}
+
+func (r *BreakerLockRepository) DeleteStock(ctx context.Context, ticketName string) error {
+	return guardErr(r.Breaker, func() error { return r.Next.DeleteStock(ctx, ticketName) })
+}
+
+func (r *BreakerLockRepository) IsUserPurchased(ctx context.Context, ticketName string, userID string) (bool, error) {
+	return guard(r.Breaker, func() (bool, error) { return r.Next.IsUserPurchased(ctx, ticketName, userID) })
+}
+
+func (r *BreakerLockRepository) GetPurchasedUsers(ctx context.Context, ticketName string) ([]string, error) {
+	return guard(r.Breaker, func() ([]string, error) { return r.Next.GetPurchasedUsers(ctx, ticketName) })
+}
+
+func (r *BreakerLockRepository) GetPurchasedQuantity(ctx context.Context, ticketName string, userID string) (int, error) {
+	return guard(r.Breaker, func() (int, error) { return r.Next.GetPurchasedQuantity(ctx, ticketName, userID) })
+}
+
+func (r *BreakerLockRepository) GetPurchasedQuantities(ctx context.Context, ticketName string) (map[string]int, error) {
+	return guard(r.Breaker, func() (map[string]int, error) { return r.Next.GetPurchasedQuantities(ctx, ticketName) })
+}
+
+func (r *BreakerLockRepository) AddPurchased(ctx context.Context, ticketName string, userID string, quantity int) error {
+	return guardErr(r.Breaker, func() error { return r.Next.AddPurchased(ctx, ticketName, userID, quantity) })
+}
+
+func (r *BreakerLockRepository) RemovePurchasedUser(ctx context.Context, ticketName string, userID string) error {
+	return guardErr(r.Breaker, func() error { return r.Next.RemovePurchasedUser(ctx, ticketName, userID) })
+}
+
+func (r *BreakerLockRepository) CancelPurchase(ctx context.Context, ticketName string, userID string, quantity int, entry OutboxEntry) (int, error) {
+	return guard(r.Breaker, func() (int, error) { return r.Next.CancelPurchase(ctx, ticketName, userID, quantity, entry) })
+}
+
+func (r *BreakerLockRepository) CancelOrderItems(ctx context.Context, ticketName string, userID string, orderID string, itemIDs []uint, entry OutboxEntry) (int, error) {
+	return guard(r.Breaker, func() (int, error) { return r.Next.CancelOrderItems(ctx, ticketName, userID, orderID, itemIDs, entry) })
+}
+
+func (r *BreakerLockRepository) TransferOrder(ctx context.Context, ticketName string, fromUserID string, toUserID string, orderID string, itemIDs []uint, limit int, entry OutboxEntry) (int, error) {
+	return guard(r.Breaker, func() (int, error) {
+		return r.Next.TransferOrder(ctx, ticketName, fromUserID, toUserID, orderID, itemIDs, limit, entry)
+	})
+}
+
+func (r *BreakerLockRepository) TryEnterOrEnqueue(ctx context.Context, ticketName string, userID string, maxActive int, sessionTTL time.Duration) (string, int, error) {
+	var rank int
+	status, err := guard(r.Breaker, func() (string, error) {
+		var (
+			status string
+			err    error
+		)
+		status, rank, err = r.Next.TryEnterOrEnqueue(ctx, ticketName, userID, maxActive, sessionTTL)
+		return status, err
+	})
+	return status, rank, err
+}
+
+func (r *BreakerLockRepository) RemoveActiveUser(ctx context.Context, ticketName string, userID string) error {
+	return guardErr(r.Breaker, func() error { return r.Next.RemoveActiveUser(ctx, ticketName, userID) })
+}
+
+func (r *BreakerLockRepository) PromoteUsers(ctx context.Context, ticketName string, maxActive int, grace time.Duration) ([]string, int, error) {
+	var evicted int
+	users, err := guard(r.Breaker, func() ([]string, error) {
+		var (
+			users []string
+			err   error
+		)
+		users, evicted, err = r.Next.PromoteUsers(ctx, ticketName, maxActive, grace)
+		return users, err
+	})
+	return users, evicted, err
+}
+
+func (r *BreakerLockRepository) GetQueuePosition(ctx context.Context, ticketName string, userID string) (QueuePosition, error) {
+	return guard(r.Breaker, func() (QueuePosition, error) { return r.Next.GetQueuePosition(ctx, ticketName, userID) })
+}
+
+func (r *BreakerLockRepository) RecordQueueThroughput(ctx context.Context, ticketName string, kind string, n int) error {
+	return guardErr(r.Breaker, func() error { return r.Next.RecordQueueThroughput(ctx, ticketName, kind, n) })
+}
+
+func (r *BreakerLockRepository) GetQueueThroughput(ctx context.Context, ticketName string, window time.Duration) (QueueThroughput, error) {
+	return guard(r.Breaker, func() (QueueThroughput, error) { return r.Next.GetQueueThroughput(ctx, ticketName, window) })
+}
+
+func (r *BreakerLockRepository) JoinLobby(ctx context.Context, ticketName string, userID string) (int, error) {
+	return guard(r.Breaker, func() (int, error) { return r.Next.JoinLobby(ctx, ticketName, userID) })
+}
+
+func (r *BreakerLockRepository) GetLobbyMembers(ctx context.Context, ticketName string) ([]string, error) {
+	return guard(r.Breaker, func() ([]string, error) { return r.Next.GetLobbyMembers(ctx, ticketName) })
+}
+
+func (r *BreakerLockRepository) OpenLobby(ctx context.Context, ticketName string, order []string) (int, error) {
+	return guard(r.Breaker, func() (int, error) { return r.Next.OpenLobby(ctx, ticketName, order) })
+}
+
+func (r *BreakerLockRepository) ResetLobby(ctx context.Context, ticketName string) (bool, error) {
+	return guard(r.Breaker, func() (bool, error) { return r.Next.ResetLobby(ctx, ticketName) })
+}
+
+func (r *BreakerLockRepository) PublishQueueUpdate(ctx context.Context, update QueueUpdate) error {
+	return guardErr(r.Breaker, func() error { return r.Next.PublishQueueUpdate(ctx, update) })
+}
+
+func (r *BreakerLockRepository) SubscribeQueueUpdates(ctx context.Context) (<-chan QueueUpdate, error) {
+	return guard(r.Breaker, func() (<-chan QueueUpdate, error) { return r.Next.SubscribeQueueUpdates(ctx) })
+}
+
+func (r *BreakerLockRepository) ReserveStock(ctx context.Context, ticketName string, userID string, holdID string, quantity int, limit int, ttl time.Duration) (string, string, int, error) {
+	var (
+		id        string
+		remaining int
+	)
+	status, err := guard(r.Breaker, func() (string, error) {
+		var (
+			status string
+			err    error
+		)
+		status, id, remaining, err = r.Next.ReserveStock(ctx, ticketName, userID, holdID, quantity, limit, ttl)
+		return status, err
+	})
+	return status, id, remaining, err
+}
+
+func (r *BreakerLockRepository) GetHold(ctx context.Context, ticketName string, holdID string, userID string) (*Reservation, error) {
+	return guard(r.Breaker, func() (*Reservation, error) { return r.Next.GetHold(ctx, ticketName, holdID, userID) })
+}
+
+func (r *BreakerLockRepository) ConfirmHold(ctx context.Context, ticketName string, holdID string, userID string, entry OutboxEntry) (string, error) {
+	return guard(r.Breaker, func() (string, error) { return r.Next.ConfirmHold(ctx, ticketName, holdID, userID, entry) })
+}
+
+func (r *BreakerLockRepository) ReleaseHold(ctx context.Context, ticketName string, holdID string, userID string) (int, error) {
+	return guard(r.Breaker, func() (int, error) { return r.Next.ReleaseHold(ctx, ticketName, holdID, userID) })
+}
+
+func (r *BreakerLockRepository) ClaimExpiredHolds(ctx context.Context, ticketName string, now time.Time, limit int) ([]Reservation, error) {
+	return guard(r.Breaker, func() ([]Reservation, error) {
+		return r.Next.ClaimExpiredHolds(ctx, ticketName, now, limit)
+	})
+}
+
+func (r *BreakerLockRepository) CountHolds(ctx context.Context, ticketName string) (int, error) {
+	return guard(r.Breaker, func() (int, error) { return r.Next.CountHolds(ctx, ticketName) })
+}
+
+func (r *BreakerLockRepository) HoldSeats(ctx context.Context, ticketName string, userID string, seatIDs []uint, ttl time.Duration) (bool, error) {
+	return guard(r.Breaker, func() (bool, error) { return r.Next.HoldSeats(ctx, ticketName, userID, seatIDs, ttl) })
+}
+
+func (r *BreakerLockRepository) ConfirmSeats(ctx context.Context, ticketName string, userID string, seatIDs []uint, entry OutboxEntry) (int, error) {
+	return guard(r.Breaker, func() (int, error) { return r.Next.ConfirmSeats(ctx, ticketName, userID, seatIDs, entry) })
+}
+
+func (r *BreakerLockRepository) ReleaseSeats(ctx context.Context, ticketName string, userID string, seatIDs []uint) error {
+	return guardErr(r.Breaker, func() error { return r.Next.ReleaseSeats(ctx, ticketName, userID, seatIDs) })
+}
+
+func (r *BreakerLockRepository) CancelSeats(ctx context.Context, ticketName string, userID string, seatIDs []uint, seatEntry OutboxEntry, cancelEntry OutboxEntry) (int, error) {
+	return guard(r.Breaker, func() (int, error) {
+		return r.Next.CancelSeats(ctx, ticketName, userID, seatIDs, seatEntry, cancelEntry)
+	})
+}
+
+func (r *BreakerLockRepository) GetSeatStates(ctx context.Context, ticketName string, seatIDs []uint) (map[uint]string, error) {
+	return guard(r.Breaker, func() (map[uint]string, error) { return r.Next.GetSeatStates(ctx, ticketName, seatIDs) })
+}
+
+func (r *BreakerLockRepository) GetUserSeats(ctx context.Context, ticketName string, userID string) ([]uint, error) {
+	return guard(r.Breaker, func() ([]uint, error) { return r.Next.GetUserSeats(ctx, ticketName, userID) })
+}
+
+func (r *BreakerLockRepository) Lock(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+	return guard(r.Breaker, func() (bool, error) { return r.Next.Lock(ctx, key, expiration) })
+}
+
+func (r *BreakerLockRepository) Unlock(ctx context.Context, key string) error {
+	return guardErr(r.Breaker, func() error { return r.Next.Unlock(ctx, key) })
+}
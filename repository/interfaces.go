@@ -13,24 +13,91 @@ import (
 type LockRepository interface {
 	// Stock Management
 	GetStock(ctx context.Context, ticketName string) (int, error)
+	HasStock(ctx context.Context, ticketName string) (bool, error)
 	DecreaseStock(ctx context.Context, ticketName string) (int, error)
 	IncreaseStock(ctx context.Context, ticketName string) (int, error)
+	InitStock(ctx context.Context, ticketName string, stock int) error
+	AdjustStock(ctx context.Context, ticketName string, delta int) (int, error)
+	ResizeStock(ctx context.Context, ticketName string, delta int) (int, error) // 판매 수량 변경 (남은 재고가 음수가 되면 -1, 재고 키가 없으면 -2)
+	SeedSaleState(ctx context.Context, ticketName string, stock int, purchased map[string]int, soldSeats map[uint]string, failoverOrders []string) (bool, error)
+	ApplyDirectSale(ctx context.Context, ticketName string, orderID string, userID string, quantity int, cancelled bool) (bool, int, error) // 장애 모드 판매 1건을 주문당 한 번만 반영
+	DeleteStock(ctx context.Context, ticketName string) error
 
 	// User Verification
 	IsUserPurchased(ctx context.Context, ticketName string, userID string) (bool, error)
-	AddPurchasedUser(ctx context.Context, ticketName string, userID string) error
+	GetPurchasedQuantity(ctx context.Context, ticketName string, userID string) (int, error)
+	GetPurchasedUsers(ctx context.Context, ticketName string) ([]string, error)
+	GetPurchasedQuantities(ctx context.Context, ticketName string) (map[string]int, error)
+	AddPurchased(ctx context.Context, ticketName string, userID string, quantity int) error
 	RemovePurchasedUser(ctx context.Context, ticketName string, userID string) error
+	CancelPurchase(ctx context.Context, ticketName string, userID string, quantity int, entry OutboxEntry) (int, error)
+	CancelOrderItems(ctx context.Context, ticketName string, userID string, orderID string, itemIDs []uint, entry OutboxEntry) (int, error)
+	TransferOrder(ctx context.Context, ticketName string, fromUserID string, toUserID string, orderID string, itemIDs []uint, limit int, entry OutboxEntry) (int, error)
 
-	// Virtual Waiting Queue
-	TryEnterOrEnqueue(ctx context.Context, userID string, maxActive int) (string, int, error)
-	RemoveActiveUser(ctx context.Context, userID string) error
-	PromoteUsers(ctx context.Context, maxActive int) (int, error)
+	// Virtual Waiting Queue (이벤트별로 분리된 Active Set / Waiting Queue)
+	TryEnterOrEnqueue(ctx context.Context, ticketName string, userID string, maxActive int, sessionTTL time.Duration) (string, int, error)
+	RemoveActiveUser(ctx context.Context, ticketName string, userID string) error
+	PromoteUsers(ctx context.Context, ticketName string, maxActive int, grace time.Duration) ([]string, int, error) // 만료 세션 정리 + 승급 (승급 유저, 정리된 세션 수)
+	GetQueuePosition(ctx context.Context, ticketName string, userID string) (QueuePosition, error)                  // 대기 순번/입장 상태 조회 (재진입 없음)
+	RecordQueueThroughput(ctx context.Context, ticketName string, kind string, n int) error
+	GetQueueThroughput(ctx context.Context, ticketName string, window time.Duration) (QueueThroughput, error) // 최근 window 동안의 초당 승급/완료 인원
+	JoinLobby(ctx context.Context, ticketName string, userID string) (int, error)                             // 판매 전 대기실 입장 (대기실 인원 반환, 이미 열렸으면 -1)
+	GetLobbyMembers(ctx context.Context, ticketName string) ([]string, error)
+	OpenLobby(ctx context.Context, ticketName string, order []string) (int, error) // 대기실 유저를 order 순서로 대기열 맨 앞에 배정 (목록이 바뀌었으면 -1, 이미 열렸으면 -2)
+	ResetLobby(ctx context.Context, ticketName string) (bool, error)               // 판매 일정 변경 시 이미 열린 대기실을 다시 열 수 있도록 초기화
+	PublishQueueUpdate(ctx context.Context, update QueueUpdate) error              // 승급 결과를 모든 API 서버에 전파 (Pub/Sub)
+	SubscribeQueueUpdates(ctx context.Context) (<-chan QueueUpdate, error)         // 모든 이벤트의 승급 알림 구독 (ctx 종료 시 채널 닫힘)
+
+	// Reservation Hold (재고 선점 → 구매 확정/해제, 만료 시 Reaper가 회수)
+	ReserveStock(ctx context.Context, ticketName string, userID string, holdID string, quantity int, limit int, ttl time.Duration) (string, string, int, error)
+	GetHold(ctx context.Context, ticketName string, holdID string, userID string) (*Reservation, error)
+	ConfirmHold(ctx context.Context, ticketName string, holdID string, userID string, entry OutboxEntry) (string, error)
+	ReleaseHold(ctx context.Context, ticketName string, holdID string, userID string) (int, error)             // 홀드 해제 + 재고 반환 + 만료 이벤트 Outbox 기록 (반환 후 재고, 홀드가 없으면 -1)
+	ClaimExpiredHolds(ctx context.Context, ticketName string, now time.Time, limit int) ([]Reservation, error) // 만료 홀드 회수 + 재고 반환 + 만료 이벤트 Outbox 기록
+	CountHolds(ctx context.Context, ticketName string) (int, error)
+
+	// Reserved Seating (좌석 단위 원자적 선점/확정/취소)
+	HoldSeats(ctx context.Context, ticketName string, userID string, seatIDs []uint, ttl time.Duration) (bool, error)
+	ConfirmSeats(ctx context.Context, ticketName string, userID string, seatIDs []uint, entry OutboxEntry) (int, error)
+	ReleaseSeats(ctx context.Context, ticketName string, userID string, seatIDs []uint) error
+	CancelSeats(ctx context.Context, ticketName string, userID string, seatIDs []uint, seatEntry OutboxEntry, cancelEntry OutboxEntry) (int, error)
+	GetSeatStates(ctx context.Context, ticketName string, seatIDs []uint) (map[uint]string, error)
+	GetUserSeats(ctx context.Context, ticketName string, userID string) ([]uint, error)
 
 	// Distributed Locking
 	Lock(ctx context.Context, key string, expiration time.Duration) (bool, error)
 	Unlock(ctx context.Context, key string) error
 }
 
+/*
+ * OutboxRepository Interface
+ * Redis Stream 기반 Transactional Outbox. 상태 변경 Lua 스크립트가 기록한 메시지를
+ * Relay가 읽어 Kafka로 발행하고 완료 처리합니다.
+ */
+
+type OutboxRepository interface {
+	AppendOutbox(ctx context.Context, entry OutboxEntry) error
+	EnsureOutboxGroup(ctx context.Context) error
+	ReadOutbox(ctx context.Context, consumer string, count int, block time.Duration) ([]OutboxEntry, error)
+	ClaimStaleOutbox(ctx context.Context, consumer string, minIdle time.Duration, count int) ([]OutboxEntry, error)
+	AckOutbox(ctx context.Context, ids ...string) error
+	OutboxBacklog(ctx context.Context) (int64, error)
+	AcquireOutboxLease(ctx context.Context, owner string, ttl time.Duration) (bool, error) // 발행 리더 리스 획득/연장
+	ReleaseOutboxLease(ctx context.Context, owner string) error
+}
+
+/*
+ * DLQStateRepository Interface
+ * DLQ 메시지별 처리 결과(폐기/재처리)와 파티션별 정리 기준 offset을 관리합니다.
+ */
+
+type DLQStateRepository interface {
+	SetDLQStatus(ctx context.Context, ref DLQRef, status string, note string) error
+	GetDLQStatuses(ctx context.Context, refs []DLQRef) (map[DLQRef]DLQMark, error)
+	PurgeDLQ(ctx context.Context, partition int, before int64) error
+	GetDLQPurged(ctx context.Context) (map[int]int64, error)
+}
+
 /*
  * TicketRepository Interface
  * 최종적인 티켓 데이터 및 구매 이벤트를 RDBMS(MySQL)에 저장하는 역할을 담당합니다.
@@ -39,7 +106,57 @@ type LockRepository interface {
 type TicketRepository interface {
 	GetStock(name string) (int, error)
 	DecreaseStock(name string) error
-	SavePurchase(userID string, ticketName string) (bool, error)   // 구매 목록 저장
-	ExistsPurchase(userID string, ticketName string) (bool, error) //구매 여부 확인
-	DeletePurchase(userID string, ticketName string) error
+	SetStock(name string, stock int) error
+
+	// Event Catalog
+	CreateTicket(ticket *Ticket) error
+	GetTicket(id uint) (*Ticket, error)
+	ListTickets() ([]Ticket, error)
+	UpdateTicket(ticket *Ticket, stockDelta int) error // 이벤트 정보 수정 + 재고 증감
+	DeleteTicket(id uint) error
+
+	SavePurchase(purchase *Purchase) (bool, error)                          // 구매 목록 저장 (결제 정보 포함)
+	ListUserPurchases(userID string, ticketName string) ([]Purchase, error) // 유저의 구매 내역 조회 (환불 대상 확인)
+	ListPurchases(ticketName string) ([]Purchase, error)
+	SaleSnapshot(ticketName string) ([]Purchase, []string, error) // 구매 내역 + 장애 모드 판매 주문 ID (같은 시점, WarmStart용)
+	ListFailoverSales(ticketName string) ([]Purchase, error)      // 장애 모드 판매분 (취소된 주문 포함, Redis 재동기화용)
+	UpdatePaymentStatus(userID string, ticketName string, status string) error
+	ExistsPurchase(userID string, ticketName string) (bool, error)                               //구매 여부 확인
+	CountUserTickets(userID string, ticketName string) (int, error)                              // 유저의 누적 구매 수량
+	DeletePurchase(userID string, ticketName string, paymentIDs []string, src AuditSource) error // 구매 내역 취소 (Soft Delete, 이력 유지)
+	SellDirect(purchase *Purchase, limit int) (string, error)                                    // Redis 장애 시 재고 차감 + 구매 저장을 한 트랜잭션으로 처리
+
+	// Order (주문/주문 항목 이력, 취소해도 삭제하지 않고 상태만 변경)
+	CreateOrder(order *Order) error
+	GetOrder(orderID string) (*Order, error)
+	ListOrders(userID string, ticketName string) ([]Order, error)
+	RecordOrderPayment(orderID string, paymentID string, amount int) error // 매입된 결제 ID/금액을 결제 대기 주문에 기록
+	CancelPendingOrder(orderID string) error
+	CancelOrderItems(orderID string, itemIDs []uint, src AuditSource) (int, error) // 항목 환불 + 구매 수량 차감 + 재고 복구
+	CancelSeatItems(userID string, ticketName string, seatIDs []uint, src AuditSource) error
+	TransferOrder(orderID string, fromUserID string, toUserID string, src AuditSource) (bool, error) // 주문 + 구매 내역 소유자 변경
+
+	// Audit (구매 상태 변경 이력, 추가만 가능)
+	AppendAudit(entry *AuditLog) error
+	ListAudit(userID string, ticketName string) ([]AuditLog, error)
+
+	// Batch (워커 일괄 처리용)
+	SavePurchases(purchases []*Purchase) (int64, error) // 여러 건을 한 번의 INSERT로 저장 (이미 있는 건은 건너뜀)
+	DeletePurchases(keys []PurchaseKey) error           // 여러 건을 한 번에 취소 처리 (대상이 없는 건이 있으면 전체 롤백)
+}
+
+/*
+ * SeatRepository Interface
+ * 지정석 이벤트의 좌석 배치도(구역/열/좌석)와 판매 확정 상태를 RDBMS(MySQL)에 저장합니다.
+ */
+
+type SeatRepository interface {
+	CreateSeats(seats []Seat) error
+	ListSeats(ticketID uint) ([]Seat, error)
+	CountSeats(ticketID uint) (int, error)
+	CountSoldSeats(ticketID uint) (int, error)
+	SeatsExist(ticketID uint, seatIDs []uint) (bool, error)
+	MarkSeatsSold(seatIDs []uint, userID string) error
+	ReleaseSeats(seatIDs []uint, userID string) error
+	DeleteSeats(ticketID uint) error
 }
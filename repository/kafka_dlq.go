@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	// DLQTopic: 처리에 실패한 메시지를 보관하는 토픽
+	DLQTopic = "ticket-dlq-topic"
+	// ParkedTopic: DLQ 재처리에도 MaxDLQAttempts번 실패한 메시지(Poison Message)를 격리하는 토픽
+	ParkedTopic = "ticket-parked-topic"
+	// MaxDLQAttempts: 이 횟수만큼 DLQ에 들어온 메시지는 더 이상 재처리하지 않고 Parked 토픽으로 이동
+	MaxDLQAttempts = 5
+)
+
+// DLQ 메시지에 붙는 실패 정보 헤더
+const (
+	HeaderErrorReason       = "error_reason"
+	HeaderOriginalTopic     = "original_topic"
+	HeaderOriginalPartition = "original_partition"
+	HeaderOriginalOffset    = "original_offset"
+	HeaderAttemptCount      = "attempt_count"
+	HeaderFirstFailureAt    = "first_failure_at"
+	HeaderLastFailureAt     = "last_failure_at"
+)
+
+var failureHeaderKeys = map[string]bool{
+	HeaderErrorReason:       true,
+	HeaderOriginalTopic:     true,
+	HeaderOriginalPartition: true,
+	HeaderOriginalOffset:    true,
+	HeaderAttemptCount:      true,
+	HeaderFirstFailureAt:    true,
+	HeaderLastFailureAt:     true,
+}
+
+// FailureMeta: DLQ 메시지의 실패 이력
+type FailureMeta struct {
+	OriginalTopic     string    `json:"original_topic"`
+	OriginalPartition int       `json:"original_partition"`
+	OriginalOffset    int64     `json:"original_offset"`
+	Reason            string    `json:"error_reason"`
+	Attempts          int       `json:"attempt_count"` // DLQ에 들어온 횟수
+	FirstFailureAt    time.Time `json:"first_failure_at"`
+	LastFailureAt     time.Time `json:"last_failure_at"`
+}
+
+// ParseFailureMeta: 헤더에서 실패 이력 복원 (헤더가 없는 구버전 DLQ 메시지는 1회 실패로 간주)
+func ParseFailureMeta(headers map[string]string) FailureMeta {
+	meta := FailureMeta{
+		OriginalTopic: headers[HeaderOriginalTopic],
+		Reason:        headers[HeaderErrorReason],
+		Attempts:      1,
+	}
+	meta.OriginalPartition, _ = strconv.Atoi(headers[HeaderOriginalPartition])
+	meta.OriginalOffset, _ = strconv.ParseInt(headers[HeaderOriginalOffset], 10, 64)
+	if n, err := strconv.Atoi(headers[HeaderAttemptCount]); err == nil {
+		meta.Attempts = n
+	}
+	meta.FirstFailureAt, _ = time.Parse(time.RFC3339Nano, headers[HeaderFirstFailureAt])
+	meta.LastFailureAt, _ = time.Parse(time.RFC3339Nano, headers[HeaderLastFailureAt])
+	return meta
+}
+
+// failureHeaders: 원본 헤더는 유지하고 실패 이력을 갱신
+// 처음 실패한 메시지는 자신의 위치를 원본으로 기록하고, DLQ에서 재처리하다 다시 실패한 메시지는 기존 원본 위치를 유지합니다.
+func failureHeaders(m kafka.Message, reason string, now time.Time) ([]kafka.Header, int) {
+	prev := Headers(m)
+	meta := ParseFailureMeta(prev)
+	if prev[HeaderAttemptCount] == "" && m.Topic != DLQTopic {
+		meta.Attempts = 0
+	}
+	if meta.OriginalTopic == "" {
+		meta.OriginalTopic, meta.OriginalPartition, meta.OriginalOffset = m.Topic, m.Partition, m.Offset
+	}
+	if meta.FirstFailureAt.IsZero() {
+		meta.FirstFailureAt = now
+	}
+	attempts := meta.Attempts + 1
+
+	headers := make([]kafka.Header, 0, len(m.Headers)+len(failureHeaderKeys))
+	for _, h := range m.Headers {
+		if !failureHeaderKeys[h.Key] {
+			headers = append(headers, h)
+		}
+	}
+	headers = append(headers,
+		kafka.Header{Key: HeaderErrorReason, Value: []byte(reason)},
+		kafka.Header{Key: HeaderOriginalTopic, Value: []byte(meta.OriginalTopic)},
+		kafka.Header{Key: HeaderOriginalPartition, Value: []byte(strconv.Itoa(meta.OriginalPartition))},
+		kafka.Header{Key: HeaderOriginalOffset, Value: []byte(strconv.FormatInt(meta.OriginalOffset, 10))},
+		kafka.Header{Key: HeaderAttemptCount, Value: []byte(strconv.Itoa(attempts))},
+		kafka.Header{Key: HeaderFirstFailureAt, Value: []byte(meta.FirstFailureAt.UTC().Format(time.RFC3339Nano))},
+		kafka.Header{Key: HeaderLastFailureAt, Value: []byte(now.UTC().Format(time.RFC3339Nano))},
+	)
+	return headers, attempts
+}
+
+// PublishToDLQ: 원본 메시지(Key/Value/헤더)를 실패 이력과 함께 DLQ로 이동
+// DLQ 재처리 실패가 누적되어 MaxDLQAttempts에 도달하면 Parked 토픽으로 보내고 그 토픽 이름을 반환합니다.
+func (r *KafkaRepository) PublishToDLQ(ctx context.Context, m kafka.Message, reason string) (string, error) {
+	headers, attempts := failureHeaders(m, reason, time.Now())
+	topic := DLQTopic
+	if attempts >= MaxDLQAttempts {
+		topic = ParkedTopic
+	}
+
+	err := r.Writer.WriteMessages(ctx,
+		kafka.Message{
+			Topic:   topic,
+			Key:     m.Key,
+			Value:   m.Value,
+			Headers: headers,
+		},
+	)
+	return topic, err
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestFailureHeadersFirstFailure(t *testing.T) {
+	now := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	m := kafka.Message{
+		Topic:     "ticket-topic",
+		Partition: 2,
+		Offset:    41,
+		Headers:   []kafka.Header{{Key: "event_type", Value: []byte("PURCHASE")}},
+	}
+
+	headers, attempts := failureHeaders(m, "저장 실패", now)
+	if attempts != 1 {
+		t.Fatalf("attempts = %d, want 1", attempts)
+	}
+
+	got := Headers(kafka.Message{Headers: headers})
+	if got["event_type"] != "PURCHASE" {
+		t.Errorf("원본 헤더가 유지되지 않음: %v", got)
+	}
+	meta := ParseFailureMeta(got)
+	want := FailureMeta{
+		OriginalTopic:     "ticket-topic",
+		OriginalPartition: 2,
+		OriginalOffset:    41,
+		Reason:            "저장 실패",
+		Attempts:          1,
+		FirstFailureAt:    now,
+		LastFailureAt:     now,
+	}
+	if meta != want {
+		t.Errorf("meta = %+v, want %+v", meta, want)
+	}
+}
+
+func TestFailureHeadersRequeueKeepsOrigin(t *testing.T) {
+	first := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	now := first.Add(time.Hour)
+
+	// DLQ에서 재처리하다 다시 실패한 메시지 (DLQ 위치가 아니라 원본 위치를 유지해야 함)
+	m := kafka.Message{
+		Topic:     DLQTopic,
+		Partition: 0,
+		Offset:    7,
+		Headers: []kafka.Header{
+			{Key: "event_type", Value: []byte("CANCEL")},
+			{Key: HeaderErrorReason, Value: []byte("이전 사유")},
+			{Key: HeaderOriginalTopic, Value: []byte("ticket-topic")},
+			{Key: HeaderOriginalPartition, Value: []byte("3")},
+			{Key: HeaderOriginalOffset, Value: []byte("99")},
+			{Key: HeaderAttemptCount, Value: []byte("2")},
+			{Key: HeaderFirstFailureAt, Value: []byte(first.Format(time.RFC3339Nano))},
+			{Key: HeaderLastFailureAt, Value: []byte(first.Format(time.RFC3339Nano))},
+		},
+	}
+
+	headers, attempts := failureHeaders(m, "재처리 실패", now)
+	if attempts != 3 {
+		t.Fatalf("attempts = %d, want 3", attempts)
+	}
+
+	// 실패 이력 헤더는 중복 없이 한 번씩만 남아야 함
+	count := make(map[string]int)
+	for _, h := range headers {
+		count[h.Key]++
+	}
+	for key := range failureHeaderKeys {
+		if count[key] != 1 {
+			t.Errorf("헤더 %s가 %d번 포함됨", key, count[key])
+		}
+	}
+
+	meta := ParseFailureMeta(Headers(kafka.Message{Headers: headers}))
+	want := FailureMeta{
+		OriginalTopic:     "ticket-topic",
+		OriginalPartition: 3,
+		OriginalOffset:    99,
+		Reason:            "재처리 실패",
+		Attempts:          3,
+		FirstFailureAt:    first,
+		LastFailureAt:     now,
+	}
+	if meta != want {
+		t.Errorf("meta = %+v, want %+v", meta, want)
+	}
+}
+
+func TestFailureHeadersLegacyDLQMessage(t *testing.T) {
+	// 실패 이력 헤더가 없는 구버전 DLQ 메시지는 이미 한 번 실패한 것으로 간주
+	now := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	m := kafka.Message{Topic: DLQTopic, Partition: 1, Offset: 5}
+
+	headers, attempts := failureHeaders(m, "재처리 실패", now)
+	if attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", attempts)
+	}
+	got := Headers(kafka.Message{Headers: headers})
+	if got[HeaderOriginalTopic] != DLQTopic || got[HeaderOriginalOffset] != strconv.Itoa(5) {
+		t.Errorf("원본 위치 = %s/%s, want %s/5", got[HeaderOriginalTopic], got[HeaderOriginalOffset], DLQTopic)
+	}
+}
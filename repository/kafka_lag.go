@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"ticket-system/codec"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// TopicOffsets: 토픽의 파티션별 처음(FirstOffset)/다음 기록될(LastOffset) offset
+func (r *KafkaRepository) TopicOffsets(ctx context.Context, topic string) ([]kafka.PartitionOffsets, error) {
+	client := &kafka.Client{Addr: kafka.TCP(r.Brokers...)}
+
+	meta, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{topic}})
+	if err != nil {
+		return nil, err
+	}
+	if len(meta.Topics) == 0 || meta.Topics[0].Error != nil {
+		return nil, fmt.Errorf("토픽 %s 메타데이터 조회 실패", topic)
+	}
+
+	var requests []kafka.OffsetRequest
+	for _, p := range meta.Topics[0].Partitions {
+		requests = append(requests, kafka.FirstOffsetOf(p.ID), kafka.LastOffsetOf(p.ID))
+	}
+	offsets, err := client.ListOffsets(ctx, &kafka.ListOffsetsRequest{
+		Topics: map[string][]kafka.OffsetRequest{topic: requests},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range offsets.Topics[topic] {
+		if p.Error != nil {
+			return nil, p.Error
+		}
+	}
+	return offsets.Topics[topic], nil
+}
+
+// PendingEvents: groupID가 아직 커밋하지 않은 topic 메시지(Consumer Lag)를 읽어 이벤트로 반환
+// Redis 상태를 MySQL에서 복원할 때, 워커가 아직 반영하지 못한 구매/취소를 보정하는 데 사용합니다.
+func (r *KafkaRepository) PendingEvents(ctx context.Context, topic string, groupID string) ([]*codec.Event, error) {
+	offsets, err := r.TopicOffsets(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+	partitions := make([]int, 0, len(offsets))
+	for _, p := range offsets {
+		partitions = append(partitions, p.Partition)
+	}
+
+	client := &kafka.Client{Addr: kafka.TCP(r.Brokers...)}
+	committed, err := client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{
+		GroupID: groupID,
+		Topics:  map[string][]int{topic: partitions},
+	})
+	if err != nil {
+		return nil, err
+	}
+	start := make(map[int]int64)
+	for _, p := range committed.Topics[topic] {
+		start[p.Partition] = p.CommittedOffset
+	}
+
+	var events []*codec.Event
+	for _, p := range offsets {
+		from, ok := start[p.Partition]
+		if !ok || from < p.FirstOffset {
+			// 커밋 이력이 없으면 파티션의 처음부터가 Lag
+			from = p.FirstOffset
+		}
+
+		msgs, err := r.ReadPartition(ctx, topic, p.Partition, from, p.LastOffset, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range msgs {
+			ev, err := codec.Decode(m.Key, m.Value, Headers(m))
+			if err != nil {
+				log.Printf("⚠️ [Lag 조회] %s[%d]@%d 해석 불가 메시지 건너뜀: %v", topic, p.Partition, m.Offset, err)
+				continue
+			}
+			events = append(events, ev)
+		}
+	}
+	return events, nil
+}
+
+// ReadPartition: 파티션의 [from, to) 구간 메시지를 최대 limit개 읽음 (limit <= 0이면 구간 전체)
+func (r *KafkaRepository) ReadPartition(ctx context.Context, topic string, partition int, from int64, to int64, limit int) ([]kafka.Message, error) {
+	if from >= to {
+		return nil, nil
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   r.Brokers,
+		Topic:     topic,
+		Partition: partition,
+	})
+	defer reader.Close()
+
+	if err := reader.SetOffset(from); err != nil {
+		return nil, err
+	}
+
+	var msgs []kafka.Message
+	for {
+		m, err := reader.ReadMessage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, m)
+		if m.Offset >= to-1 || (limit > 0 && len(msgs) >= limit) {
+			return msgs, nil
+		}
+	}
+}
@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"strconv"
+	"strings"
+	"ticket-system/codec"
 
 	"github.com/segmentio/kafka-go"
 )
@@ -11,45 +14,140 @@ type KafkaRepository struct {
 	Brokers []string
 }
 
+// keyBalancer: 같은 Key(유저 ID)는 항상 같은 파티션으로 보내 워커 레인의 유저 단위 순서를 보장
+// (Java 클라이언트의 기본 파티셔너와 같은 murmur2 해시)
+func keyBalancer() kafka.Balancer {
+	return &kafka.Murmur2Balancer{}
+}
+
 func NewKafkaRepository(brokers []string, topic string) *KafkaRepository {
 	return &KafkaRepository{
 		Writer: &kafka.Writer{
 			Addr: kafka.TCP(brokers...),
 			//Topic:    topic,
-			Balancer: &kafka.LeastBytes{},
+			Balancer: keyBalancer(),
 		},
+		Brokers: brokers,
 	}
 }
 
-func (r *KafkaRepository) PublishPurchase(userID, ticketName string) error {
-	return r.Writer.WriteMessages(context.Background(),
-		kafka.Message{
-			Topic: "ticket-topic",
-			Key:   []byte(userID),
-			Value: []byte(ticketName),
-		},
-	)
+/*
+ * ticket-topic 메시지 생성
+ * 상태 변경과 함께 Outbox에 기록할 수 있도록 메시지를 OutboxEntry로 만들어 두고,
+ * 실제 발행은 OutboxRelay가 PublishEntries로 수행합니다.
+ * Value는 codec.Event JSON Envelope이며, traceID로 홀드/결제 흐름을 추적합니다.
+ */
+
+// PurchaseMessage: 예매 확정 이벤트 (주문 수량, 결제 정보 포함, 주문 ID = traceID)
+func PurchaseMessage(userID, ticketName string, quantity int, paymentID string, amount int, traceID string) OutboxEntry {
+	e := codec.New(codec.TypePurchase, userID, ticketName, traceID)
+	e.Quantity, e.OrderID = quantity, traceID
+	e.PaymentID, e.Amount = paymentID, amount
+	return eventEntry(e)
 }
 
-func (r *KafkaRepository) PublishCancel(userID string, ticketName string) error {
-	return r.Writer.WriteMessages(context.Background(), kafka.Message{
-		Topic: "ticket-topic",
-		Key:   []byte(userID),
-		Value: []byte("CANCEL:" + ticketName), // Value에 CANCEL 접두사를 붙여 구분
-	})
+// CancelMessage: 예매 취소 이벤트 (취소 시점에 유저가 구매한 전체 수량과 주문별 결제 ID)
+// 워커는 결제 ID에 해당하는 구매 내역만 취소하므로, 중복 수신되어도 이후 재구매한 주문은 취소되지 않음
+func CancelMessage(userID string, ticketName string, quantity int, paymentIDs []string, traceID string) OutboxEntry {
+	e := codec.New(codec.TypeCancel, userID, ticketName, traceID)
+	e.Quantity, e.PaymentIDs = quantity, paymentIDs
+	return eventEntry(e)
+}
+
+// CancelItemsMessage: 주문 항목 단위 취소 이벤트
+func CancelItemsMessage(userID string, ticketName string, orderID string, itemIDs []uint) OutboxEntry {
+	e := codec.New(codec.TypeCancel, userID, ticketName, orderID)
+	e.Quantity, e.OrderID, e.ItemIDs = len(itemIDs), orderID, itemIDs
+	return eventEntry(e)
+}
+
+// TransferMessage: 주문 양도 이벤트
+// Key는 양도하는 유저라 구매 이벤트와 같은 파티션/레인으로 가서 구매 이벤트 이후에 처리됨
+func TransferMessage(fromUserID string, toUserID string, ticketName string, orderID string, quantity int) OutboxEntry {
+	e := codec.New(codec.TypeTransfer, fromUserID, ticketName, orderID)
+	e.Quantity, e.OrderID, e.ToUserID = quantity, orderID, toUserID
+	return eventEntry(e)
+}
+
+// ExpireMessage: 결제 대기 홀드가 만료되어 재고가 반환되었음을 알리는 이벤트
+func ExpireMessage(userID string, ticketName string, holdID string) OutboxEntry {
+	e := codec.New(codec.TypeExpire, userID, ticketName, holdID)
+	e.HoldID = holdID
+	return eventEntry(e)
+}
+
+// SeatPurchaseMessage: 지정석 구매 확정 이벤트 (주문 ID = traceID)
+func SeatPurchaseMessage(userID string, ticketName string, seatIDs []uint, paymentID string, amount int, traceID string) OutboxEntry {
+	e := codec.New(codec.TypeSeatPurchase, userID, ticketName, traceID)
+	e.SeatIDs, e.Quantity, e.OrderID = seatIDs, len(seatIDs), traceID
+	e.PaymentID, e.Amount = paymentID, amount
+	return eventEntry(e)
+}
+
+// SeatCancelMessage: 지정석 취소 이벤트
+func SeatCancelMessage(userID string, ticketName string, seatIDs []uint, traceID string) OutboxEntry {
+	e := codec.New(codec.TypeSeatCancel, userID, ticketName, traceID)
+	e.SeatIDs, e.Quantity = seatIDs, len(seatIDs)
+	return eventEntry(e)
 }
 
-func (r *KafkaRepository) PublishToDLQ(ctx context.Context, key, value []byte, reason string) error {
-	return r.Writer.WriteMessages(ctx,
-		kafka.Message{
-			Topic: "ticket-dlq-topic",
-			Key:   key,
-			Value: value,
-			Headers: []kafka.Header{
-				{Key: "error_reason", Value: []byte(reason)},
-			},
+// eventEntry: Event를 Outbox 항목으로 변환
+// Key = 유저 ID이며, keyBalancer가 같은 유저를 같은 파티션으로 보내므로 같은 유저의 이벤트 순서가 보장됨
+func eventEntry(e *codec.Event) OutboxEntry {
+	value, _ := codec.Encode(e) // 고정 구조체라 인코딩 실패 없음
+	return OutboxEntry{
+		Key:   e.UserID,
+		Value: string(value),
+		Headers: map[string]string{
+			"content_type":   codec.ContentType,
+			"event_type":     string(e.Type),
+			"schema_version": strconv.Itoa(e.SchemaVersion),
 		},
-	)
+	}
+}
+
+// PublishEntries: Outbox 항목들을 ticket-topic으로 한 번에 발행
+func (r *KafkaRepository) PublishEntries(ctx context.Context, entries []OutboxEntry) error {
+	msgs := make([]kafka.Message, 0, len(entries))
+	for _, e := range entries {
+		msg := kafka.Message{
+			Topic: "ticket-topic",
+			Key:   []byte(e.Key),
+			Value: []byte(e.Value),
+		}
+		for k, v := range e.Headers {
+			msg.Headers = append(msg.Headers, kafka.Header{Key: k, Value: []byte(v)})
+		}
+		msgs = append(msgs, msg)
+	}
+	return r.Writer.WriteMessages(ctx, msgs...)
+}
+
+// PublishPurchase / PublishCancel: Outbox를 거치지 않는 즉시 발행 (운영 도구용)
+func (r *KafkaRepository) PublishPurchase(userID, ticketName string, quantity int, paymentID string, amount int) error {
+	return r.PublishEntries(context.Background(), []OutboxEntry{PurchaseMessage(userID, ticketName, quantity, paymentID, amount, "")})
+}
+
+func (r *KafkaRepository) PublishCancel(userID string, ticketName string, quantity int) error {
+	return r.PublishEntries(context.Background(), []OutboxEntry{CancelMessage(userID, ticketName, quantity, nil, "")})
+}
+
+// Headers: 메시지 헤더를 codec.Decode에 넘길 수 있도록 map으로 변환
+func Headers(m kafka.Message) map[string]string {
+	headers := make(map[string]string, len(m.Headers))
+	for _, h := range m.Headers {
+		headers[h.Key] = string(h.Value)
+	}
+	return headers
+}
+
+// JoinSeatIDs: 좌석 ID 목록을 문자열("1,2,3")로 변환 (환불 멱등성 키 등에 사용)
+func JoinSeatIDs(seatIDs []uint) string {
+	parts := make([]string, len(seatIDs))
+	for i, id := range seatIDs {
+		parts[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	return strings.Join(parts, ",")
 }
 
 // PublishToTopic: 특정 토픽으로 메시지를 발행합니다 (DLQ 전송 등에 사용)
@@ -57,7 +155,7 @@ func (r *KafkaRepository) PublishToTopic(ctx context.Context, topic string, key,
 	writer := &kafka.Writer{
 		Addr:     kafka.TCP(r.Brokers...),
 		Topic:    topic,
-		Balancer: &kafka.LeastBytes{},
+		Balancer: keyBalancer(),
 	}
 	defer writer.Close()
 
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+/*
+ * 재시도 토픽 (Retry Topic)
+ * 메인 Consumer는 실패한 메시지를 기다리지 않고 바로 다음 단계의 재시도 토픽으로 넘깁니다.
+ *   ticket-topic → ticket-retry-5s → ticket-retry-1m → ticket-dlq-topic
+ * 재시도 Consumer는 not_before 헤더의 시각까지 기다린 뒤 메시지를 다시 처리합니다.
+ */
+
+const (
+	RetryTopic5s = "ticket-retry-5s"
+	RetryTopic1m = "ticket-retry-1m"
+)
+
+// 재시도 메시지에 붙는 헤더
+const (
+	HeaderNotBefore    = "not_before"    // 이 시각(Unix ms) 이전에는 처리하지 않음
+	HeaderRetryAttempt = "retry_attempt" // 지금까지 거친 재시도 단계 수
+)
+
+// RetryTier: 재시도 단계 (토픽과 대기 시간)
+type RetryTier struct {
+	Topic string
+	Delay time.Duration
+}
+
+// DefaultRetryTiers: 5초 → 1분 순으로 대기 시간을 늘려가며 재시도
+var DefaultRetryTiers = []RetryTier{
+	{Topic: RetryTopic5s, Delay: 5 * time.Second},
+	{Topic: RetryTopic1m, Delay: time.Minute},
+}
+
+// RetryAttempt: 메시지가 지금까지 거친 재시도 단계 수 (메인 토픽에서 온 메시지는 0)
+func RetryAttempt(m kafka.Message) int {
+	for _, h := range m.Headers {
+		if h.Key == HeaderRetryAttempt {
+			n, _ := strconv.Atoi(string(h.Value))
+			return n
+		}
+	}
+	return 0
+}
+
+// NotBefore: 재시도 메시지를 처리해도 되는 시각 (헤더가 없으면 zero time)
+func NotBefore(m kafka.Message) time.Time {
+	for _, h := range m.Headers {
+		if h.Key == HeaderNotBefore {
+			if ms, err := strconv.ParseInt(string(h.Value), 10, 64); err == nil {
+				return time.UnixMilli(ms)
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// PublishToRetry: 원본 헤더를 유지한 채 재시도 단계, not_before, 실패 사유를 갱신하여 재시도 토픽으로 발행
+// 최초 실패 시에는 원본 위치와 최초 실패 시각도 기록하여 이후 DLQ로 넘어가도 유지되도록 합니다.
+func (r *KafkaRepository) PublishToRetry(ctx context.Context, m kafka.Message, topic string, notBefore time.Time, reason string) error {
+	prev := Headers(m)
+	skip := map[string]bool{HeaderNotBefore: true, HeaderRetryAttempt: true, HeaderErrorReason: true}
+
+	headers := make([]kafka.Header, 0, len(m.Headers)+6)
+	for _, h := range m.Headers {
+		if !skip[h.Key] {
+			headers = append(headers, h)
+		}
+	}
+	if prev[HeaderOriginalTopic] == "" {
+		headers = append(headers,
+			kafka.Header{Key: HeaderOriginalTopic, Value: []byte(m.Topic)},
+			kafka.Header{Key: HeaderOriginalPartition, Value: []byte(strconv.Itoa(m.Partition))},
+			kafka.Header{Key: HeaderOriginalOffset, Value: []byte(strconv.FormatInt(m.Offset, 10))},
+			kafka.Header{Key: HeaderFirstFailureAt, Value: []byte(time.Now().UTC().Format(time.RFC3339Nano))},
+		)
+	}
+	headers = append(headers,
+		kafka.Header{Key: HeaderErrorReason, Value: []byte(reason)},
+		kafka.Header{Key: HeaderRetryAttempt, Value: []byte(strconv.Itoa(RetryAttempt(m) + 1))},
+		kafka.Header{Key: HeaderNotBefore, Value: []byte(strconv.FormatInt(notBefore.UnixMilli(), 10))},
+	)
+
+	return r.Writer.WriteMessages(ctx,
+		kafka.Message{
+			Topic:   topic,
+			Key:     m.Key,
+			Value:   m.Value,
+			Headers: headers,
+		},
+	)
+}
+
+// RetryGroupID: 재시도 토픽을 소비하는 Consumer Group (메인 그룹과 분리하여 리밸런싱 영향을 막음)
+func RetryGroupID(groupID string, topic string) string {
+	return groupID + "-" + topic
+}
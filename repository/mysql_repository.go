@@ -1,32 +1,138 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
-// Ticket 도메인 모델
+// DefaultMaxActive: 이벤트에 동시 예매 인원이 지정되지 않았을 때 사용하는 Active Set 크기
+const DefaultMaxActive = 100
+
+// DefaultPurchaseLimit: 1인/1회 구매 한도가 지정되지 않았을 때의 수량 (1인 1매)
+const DefaultPurchaseLimit = 1
+
+var (
+	// ErrTicketNotFound: 조회한 이벤트(티켓)가 카탈로그에 존재하지 않음
+	ErrTicketNotFound = errors.New("이벤트를 찾을 수 없습니다")
+	// ErrPurchaseNotFound: 구매 내역이 아직 MySQL에 반영되지 않았거나 존재하지 않음
+	ErrPurchaseNotFound = errors.New("구매 내역을 찾을 수 없습니다")
+)
+
+// Ticket 도메인 모델 (이벤트 카탈로그의 한 공연)
+// Name은 Redis 키(ticket_stock:{name})와 purchases.ticket_name에 그대로 쓰이므로 생성 후 변경하지 않습니다.
 type Ticket struct {
-	ID    uint `gorm:"primaryKey"`
-	Name  string
-	Stock int
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"uniqueIndex;size:255;not null" json:"name"`
+	Venue       string    `json:"venue"`
+	Price       int       `json:"price"`
+	Capacity    int       `json:"capacity"` // 총 판매 수량
+	Stock       int       `json:"stock"`    // 남은 재고
+	SaleStartAt time.Time `json:"sale_start_at"`
+	SaleEndAt   time.Time `json:"sale_end_at"`
+	LobbyOpenAt time.Time `json:"lobby_open_at"` // 판매 전 대기실 입장 시각 (비어 있으면 대기실 없음)
+	MaxActive   int       `json:"max_active"`    // 대기열에서 동시에 예매를 진행할 수 있는 인원
+	Reserved    bool      `json:"reserved"`      // 지정석 여부 (true면 좌석 ID로만 예매 가능)
+	MaxPerUser  int       `json:"max_per_user"`  // 1인 누적 구매 한도
+	MaxPerOrder int       `json:"max_per_order"` // 1회 주문 수량 한도
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// IsOnSale: 판매 기간 안에 있는지 확인 (시작/종료 시각이 비어 있으면 제한 없음으로 간주)
+func (t *Ticket) IsOnSale(now time.Time) bool {
+	if !t.SaleStartAt.IsZero() && now.Before(t.SaleStartAt) {
+		return false
+	}
+	if !t.SaleEndAt.IsZero() && !now.Before(t.SaleEndAt) {
+		return false
+	}
+	return true
+}
+
+// InLobby: 판매 시작 전 대기실 입장 시간인지 확인 (대기실 입장 시각 ~ 판매 시작 시각)
+func (t *Ticket) InLobby(now time.Time) bool {
+	if t.LobbyOpenAt.IsZero() || t.SaleStartAt.IsZero() {
+		return false
+	}
+	return !now.Before(t.LobbyOpenAt) && now.Before(t.SaleStartAt)
+}
+
+// ActiveLimit: 이벤트별 Active Set 허용 인원 (미설정 시 기본값)
+func (t *Ticket) ActiveLimit() int {
+	if t.MaxActive <= 0 {
+		return DefaultMaxActive
+	}
+	return t.MaxActive
 }
 
-// Purchase 구매 내역 모델 (최종 데이터 영속화용)
+// UserLimit: 1인 누적 구매 한도 (미설정 시 1매)
+func (t *Ticket) UserLimit() int {
+	if t.MaxPerUser <= 0 {
+		return DefaultPurchaseLimit
+	}
+	return t.MaxPerUser
+}
+
+// OrderLimit: 1회 주문 수량 한도 (미설정 시 1인 한도와 같음)
+func (t *Ticket) OrderLimit() int {
+	if t.MaxPerOrder <= 0 || t.MaxPerOrder > t.UserLimit() {
+		return t.UserLimit()
+	}
+	return t.MaxPerOrder
+}
+
+// Purchase 구매 내역 모델 (주문 1건 = 1행, 결제 ID로 구분) (최종 데이터 영속화용)
+// 취소해도 행을 지우지 않고 deleted_at을 기록하며(Soft Delete), GORM 조회/집계에서는 자동으로 제외됩니다.
 type Purchase struct {
-	ID         uint      `gorm:"primaryKey;autoIncrement"`
-	UserID     string    `gorm:"column:user_id;not null"`
-	TicketName string    `gorm:"column:ticket_name;not null"`
-	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime"`
+	ID         uint   `gorm:"primaryKey;autoIncrement"`
+	UserID     string `gorm:"column:user_id;not null"`
+	TicketName string `gorm:"column:ticket_name;not null"`
+	Quantity   int    `gorm:"column:quantity;not null;default:1"`
+	OrderID    string `gorm:"column:order_id;index"` // 주문 ID (주문 도입 전 구매는 빈 값)
+	SeatIDs    []uint `gorm:"-"`                     // 지정석 주문 항목 생성용 (저장하지 않음)
+	// 결제 정보 (취소 시 환불 대상 확인용)
+	PaymentID     string         `gorm:"column:payment_id"`
+	PaymentStatus string         `gorm:"column:payment_status"`
+	Amount        int            `gorm:"column:amount"`
+	CreatedAt     time.Time      `gorm:"column:created_at;autoCreateTime"`
+	DeletedAt     gorm.DeletedAt `gorm:"column:deleted_at;index"`
+	Audit         AuditSource    `gorm:"-"` // 감사 로그에 남길 변경 주체/원본 메시지 (저장하지 않음)
 }
 
 func (Purchase) TableName() string {
 	return "purchases"
 }
 
+// PurchaseKey: 취소 메시지 1건이 가리키는 유저의 이벤트 구매 내역 (취소 단위)
+type PurchaseKey struct {
+	UserID     string
+	TicketName string
+	PaymentIDs []string    // 취소할 주문의 결제 ID (비어 있으면 유저의 이벤트 구매 전체, 구버전 메시지)
+	Audit      AuditSource // 감사 로그에 남길 변경 주체/원본 메시지
+}
+
+// matches: 구매 내역이 취소 대상인지 확인
+// 결제 ID가 없던 행은 취소 시 payment_id가 "deleted:{id}"로 바뀌므로, 중복 수신 시에도 같은 행으로 봅니다.
+func (k PurchaseKey) matches(p *Purchase) bool {
+	if p.UserID != k.UserID || p.TicketName != k.TicketName {
+		return false
+	}
+	if len(k.PaymentIDs) == 0 {
+		return true
+	}
+	paymentID := p.PaymentID
+	if p.DeletedAt.Valid && strings.HasPrefix(paymentID, "deleted:") {
+		paymentID = ""
+	}
+	return slices.Contains(k.PaymentIDs, paymentID)
+}
+
 type MySQLRepository struct {
 	DB *gorm.DB
 }
@@ -39,10 +145,75 @@ func NewMySQLRepository(db *gorm.DB) *MySQLRepository {
 
 // DecreaseStock: DB 수준의 원자적 재고 차감을 수행 (Redis 장애 대비용)
 func (r *MySQLRepository) DecreaseStock(name string) error {
-	// stock > 0 일 때만 1을 깎는 안전한 쿼리
-	return r.DB.Model(&Ticket{}).
-		Where("name = ? AND stock > 0", name).
-		Update("stock", gorm.Expr("stock - 1")).Error
+	_, err := decreaseStock(r.DB, name, 1)
+	return err
+}
+
+// decreaseStock: stock >= n 일 때만 n을 깎는 안전한 쿼리 (행 잠금으로 동시 요청 간 원자성 보장, 차감 여부 반환)
+func decreaseStock(tx *gorm.DB, name string, n int) (bool, error) {
+	result := tx.Model(&Ticket{}).
+		Where("name = ? AND stock >= ?", name, n).
+		Update("stock", gorm.Expr("stock - ?", n))
+	return result.RowsAffected > 0, result.Error
+}
+
+// errSellRollback: SellDirect 트랜잭션을 되돌리기 위한 내부 에러
+var errSellRollback = errors.New("rollback")
+
+// SellDirect: Redis 장애 시 MySQL만으로 판매 (재고 차감 + 구매 내역 저장을 하나의 트랜잭션으로 처리)
+// 재고 행을 잠근 뒤 누적 구매 수량을 확인하므로 같은 유저의 동시 요청도 limit을 넘지 못합니다.
+// status: SUCCESS, SOLD_OUT, ALREADY_PURCHASED(한도 소진), LIMIT_EXCEEDED
+func (r *MySQLRepository) SellDirect(purchase *Purchase, limit int) (string, error) {
+	purchase.Quantity = max(purchase.Quantity, 1)
+	status := "SUCCESS"
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		ok, err := decreaseStock(tx, purchase.TicketName, purchase.Quantity)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			status = "SOLD_OUT"
+			return errSellRollback
+		}
+
+		bought, err := countUserTickets(tx, purchase.UserID, purchase.TicketName)
+		if err != nil {
+			return err
+		}
+		if bought >= limit {
+			status = "ALREADY_PURCHASED"
+			return errSellRollback
+		}
+		if bought+purchase.Quantity > limit {
+			status = "LIMIT_EXCEEDED"
+			return errSellRollback
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(purchase)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			status = "ALREADY_PURCHASED"
+			return errSellRollback
+		}
+		if err := appendAudit(tx, purchaseAudit(AuditPurchase, purchase, purchase.Audit)); err != nil {
+			return err
+		}
+		return confirmOrder(tx, purchase)
+	})
+	if errors.Is(err, errSellRollback) {
+		return status, nil
+	}
+	if err != nil {
+		return "FAIL", err
+	}
+	return status, nil
+}
+
+// SetStock: 재고를 지정한 값으로 보정 (Reconciler 복구용)
+func (r *MySQLRepository) SetStock(name string, stock int) error {
+	return r.DB.Model(&Ticket{}).Where("name = ?", name).Update("stock", stock).Error
 }
 
 // 현재 재고 확인 (SELECT 쿼리 실행)
@@ -52,42 +223,341 @@ func (r *MySQLRepository) GetStock(name string) (int, error) {
 	return ticket.Stock, err
 }
 
-// SavePurchase: 중복 구매 방지를 위해 OnConflict(Ignore) 전략을 사용하여 구매 내역을 저장
-func (r *MySQLRepository) SavePurchase(userID string, ticketName string) (bool, error) {
-	result := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&Purchase{
-		UserID:     userID,
-		TicketName: ticketName,
+// CreateTicket: 새 이벤트를 카탈로그에 등록
+func (r *MySQLRepository) CreateTicket(ticket *Ticket) error {
+	return r.DB.Create(ticket).Error
+}
+
+// GetTicket: ID로 이벤트 조회
+func (r *MySQLRepository) GetTicket(id uint) (*Ticket, error) {
+	var ticket Ticket
+	err := r.DB.First(&ticket, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTicketNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// ListTickets: 전체 이벤트 목록 조회 (ID 순)
+func (r *MySQLRepository) ListTickets() ([]Ticket, error) {
+	var tickets []Ticket
+	err := r.DB.Order("id").Find(&tickets).Error
+	return tickets, err
+}
+
+// UpdateTicket: 이벤트 정보 수정 (Name은 키로 쓰이므로 수정 대상에서 제외)
+// 재고는 덮어쓰지 않고 stock = stock + stockDelta로 증감하여, 같은 시점에 워커가 반영한 판매분을 잃지 않습니다.
+func (r *MySQLRepository) UpdateTicket(ticket *Ticket, stockDelta int) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Ticket{ID: ticket.ID}).
+			Select("Venue", "Price", "Capacity", "SaleStartAt", "SaleEndAt", "LobbyOpenAt", "MaxActive", "Reserved", "MaxPerUser", "MaxPerOrder").
+			Updates(ticket)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTicketNotFound
+		}
+		if stockDelta == 0 {
+			return nil
+		}
+		return tx.Model(&Ticket{ID: ticket.ID}).Update("stock", gorm.Expr("stock + ?", stockDelta)).Error
 	})
+}
 
+// DeleteTicket: 이벤트 삭제
+func (r *MySQLRepository) DeleteTicket(id uint) error {
+	result := r.DB.Delete(&Ticket{}, id)
 	if result.Error != nil {
-		return false, result.Error
+		return result.Error
 	}
+	if result.RowsAffected == 0 {
+		return ErrTicketNotFound
+	}
+	return nil
+}
+
+// SavePurchase: 같은 주문(결제 ID)의 중복 저장 방지를 위해 OnConflict(Ignore) 전략을 사용하여 구매 내역을 저장
+// 새로 저장된 경우 같은 트랜잭션에서 tickets.stock을 주문 수량만큼 차감하여 MySQL 재고가 판매 내역과 항상 일치하도록 합니다.
+func (r *MySQLRepository) SavePurchase(purchase *Purchase) (bool, error) {
+	purchase.Quantity = max(purchase.Quantity, 1)
+	saved := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(purchase)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		saved = true
+		if err := adjustStock(tx, purchase.TicketName, -purchase.Quantity); err != nil {
+			return err
+		}
+		if err := appendAudit(tx, purchaseAudit(AuditPurchase, purchase, purchase.Audit)); err != nil {
+			return err
+		}
+		return confirmOrder(tx, purchase)
+	})
+	return saved, err
+}
+
+// adjustStock: 일반 예매 이벤트의 tickets.stock을 delta만큼 변경
+// 지정석 이벤트의 재고는 좌석 판매 상태(MarkSeatsSold/ReleaseSeats)에서 관리하므로 제외합니다.
+func adjustStock(tx *gorm.DB, ticketName string, delta int) error {
+	return tx.Model(&Ticket{}).
+		Where("name = ? AND reserved = ?", ticketName, false).
+		Update("stock", gorm.Expr("stock + ?", delta)).Error
+}
+
+// ListUserPurchases: 유저의 이벤트 구매 내역(주문별 결제 정보 포함) 조회 (없으면 ErrPurchaseNotFound)
+func (r *MySQLRepository) ListUserPurchases(userID string, ticketName string) ([]Purchase, error) {
+	var purchases []Purchase
+	err := r.DB.Where("user_id = ? AND ticket_name = ?", userID, ticketName).Order("id").Find(&purchases).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(purchases) == 0 {
+		return nil, ErrPurchaseNotFound
+	}
+	return purchases, nil
+}
+
+// ListPurchases: 이벤트의 전체 구매 내역 조회 (Reconciler용)
+func (r *MySQLRepository) ListPurchases(ticketName string) ([]Purchase, error) {
+	var purchases []Purchase
+	err := r.DB.Where("ticket_name = ?", ticketName).Find(&purchases).Error
+	return purchases, err
+}
+
+// SaleSnapshot: WarmStart용 이벤트의 구매 내역과 장애 모드 판매 주문 ID를 같은 시점 기준으로 조회
+// 한 트랜잭션(REPEATABLE READ)에서 읽으므로, 복원한 구매 내역과 재동기화 완료로 표시할 주문이 어긋나지 않습니다.
+func (r *MySQLRepository) SaleSnapshot(ticketName string) ([]Purchase, []string, error) {
+	var purchases []Purchase
+	var failoverOrders []string
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("ticket_name = ?", ticketName).Find(&purchases).Error; err != nil {
+			return err
+		}
+		sales, err := failoverSales(tx, ticketName)
+		if err != nil {
+			return err
+		}
+		for _, p := range sales {
+			failoverOrders = append(failoverOrders, p.OrderID)
+		}
+		return nil
+	})
+	return purchases, failoverOrders, err
+}
 
-	return result.RowsAffected > 0, nil
+// UpdatePaymentStatus: 환불 등 결제 상태 변경 반영
+func (r *MySQLRepository) UpdatePaymentStatus(userID string, ticketName string, status string) error {
+	return r.DB.Model(&Purchase{}).
+		Where("user_id = ? AND ticket_name = ?", userID, ticketName).
+		Update("payment_status", status).Error
 }
 
 func (r *MySQLRepository) ExistsPurchase(userID string, ticketName string) (bool, error) {
 	var count int64
-	// purchases 테이블에서 해당 유저와 티켓이 있는지 COUNT를 확인
-	err := r.DB.Table("purchases").
+	// purchases 테이블에서 해당 유저와 티켓이 있는지 COUNT를 확인 (취소된 행 제외)
+	err := r.DB.Model(&Purchase{}).
 		Where("user_id = ? AND ticket_name = ?", userID, ticketName).
 		Count(&count).Error
 
 	return count > 0, err
 }
 
-func (r *MySQLRepository) DeletePurchase(userID string, ticketName string) error {
-	// GORM을 사용하여 조건에 맞는 데이터를 삭제
-	// Unscoped()를 붙이지 않으면 Soft Delete가 설정된 경우 실제 삭제가 안 될 수 있으므로 확실히 지우기 위해 사용
-	result := r.DB.Unscoped().Where("user_id = ? AND ticket_name = ?", userID, ticketName).Delete(&Purchase{})
+// CountUserTickets: 유저가 이벤트에서 구매한 누적 수량
+func (r *MySQLRepository) CountUserTickets(userID string, ticketName string) (int, error) {
+	return countUserTickets(r.DB, userID, ticketName)
+}
 
-	if result.Error != nil {
-		return result.Error
+func countUserTickets(tx *gorm.DB, userID string, ticketName string) (int, error) {
+	var total int
+	err := tx.Model(&Purchase{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("user_id = ? AND ticket_name = ?", userID, ticketName).
+		Scan(&total).Error
+	return total, err
+}
+
+// DeletePurchase: 취소 메시지에 담긴 주문(결제 ID)의 구매 내역을 취소 처리(Soft Delete)하고 구매 수량만큼 재고 복구
+// paymentIDs가 비어 있으면(구버전 메시지) 유저의 이벤트 구매 내역 전체가 대상이며, 이미 취소된 주문뿐이면 적용된 것으로 봅니다.
+func (r *MySQLRepository) DeletePurchase(userID string, ticketName string, paymentIDs []string, src AuditSource) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		return cancelPurchases(tx, []PurchaseKey{{UserID: userID, TicketName: ticketName, PaymentIDs: paymentIDs, Audit: src}})
+	})
+}
+
+// cancelPurchases: 키별 취소 대상 구매 내역을 Soft Delete하고, 같은 트랜잭션에서 재고 복구 + 주문 환불 처리
+// 이미 취소된 행(Unscoped 조회)만 남은 키는 중복 수신으로 보고 건너뛰며, 대상 행이 전혀 없는 키가 있으면
+// (워커가 아직 구매를 저장하지 못함) 에러를 반환해 재시도되도록 합니다.
+func cancelPurchases(tx *gorm.DB, keys []PurchaseKey) error {
+	pairs := make([][]interface{}, len(keys))
+	for i, k := range keys {
+		pairs[i] = []interface{}{k.UserID, k.TicketName}
+	}
+	var rows []Purchase
+	if err := tx.Unscoped().Where("(user_id, ticket_name) IN ?", pairs).Find(&rows).Error; err != nil {
+		return err
 	}
 
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("취소할 내역이 없습니다 (유저: %s)", userID)
+	var ids []uint
+	var orderIDs []string
+	var logs []AuditLog
+	restock := make(map[string]int)
+	for _, k := range keys {
+		found := false
+		for i := range rows {
+			p := &rows[i]
+			if !k.matches(p) {
+				continue
+			}
+			found = true
+			if p.DeletedAt.Valid {
+				continue
+			}
+			ids = append(ids, p.ID)
+			if p.OrderID != "" {
+				orderIDs = append(orderIDs, p.OrderID)
+			}
+			restock[p.TicketName] += p.Quantity
+			logs = append(logs, purchaseAudit(AuditCancel, p, k.Audit))
+		}
+		if !found {
+			return fmt.Errorf("취소할 내역이 없습니다 (유저: %s)", k.UserID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
 	}
 
-	return nil
+	if _, err := softDeletePurchases(tx, "id IN ?", ids); err != nil {
+		return err
+	}
+	if err := appendAudit(tx, logs...); err != nil {
+		return err
+	}
+	for name, n := range restock {
+		if err := adjustStock(tx, name, n); err != nil {
+			return err
+		}
+	}
+	// 주문은 삭제하지 않고 환불 처리
+	return refundOrders(tx, orderIDs)
+}
+
+// softDeletePurchases: 구매 내역을 지우지 않고 deleted_at만 기록 (이후 조회/집계에서 제외)
+// 결제 정보가 없는 행(구버전 메시지, Reconciler 복구분)은 남아 있는 행이 uk_user_ticket_payment로
+// 같은 유저의 재구매 저장을 막지 않도록 payment_id를 행 ID 기반 값으로 바꿔 둡니다.
+func softDeletePurchases(tx *gorm.DB, query string, args ...interface{}) (int64, error) {
+	result := tx.Model(&Purchase{}).Where(query, args...).Updates(map[string]interface{}{
+		"deleted_at": time.Now(),
+		"payment_id": gorm.Expr("IF(payment_id = '', CONCAT('deleted:', id), payment_id)"),
+	})
+	return result.RowsAffected, result.Error
+}
+
+// SavePurchases: 여러 구매 내역을 multi-row INSERT로 저장하고 새로 저장된 건수를 반환
+// 이벤트별로 이미 저장된 주문(결제 ID)을 제외하고 INSERT하여, 새로 저장된 주문 수량의 합만큼 같은 트랜잭션에서 tickets.stock을 차감합니다.
+func (r *MySQLRepository) SavePurchases(purchases []*Purchase) (int64, error) {
+	if len(purchases) == 0 {
+		return 0, nil
+	}
+	var order []string
+	byTicket := make(map[string][]*Purchase)
+	for _, p := range purchases {
+		p.Quantity = max(p.Quantity, 1)
+		if _, ok := byTicket[p.TicketName]; !ok {
+			order = append(order, p.TicketName)
+		}
+		byTicket[p.TicketName] = append(byTicket[p.TicketName], p)
+	}
+
+	var saved int64
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		saved = 0
+		for _, name := range order {
+			fresh, err := unsavedPurchases(tx, name, byTicket[name])
+			if err != nil {
+				return err
+			}
+			if len(fresh) == 0 {
+				continue
+			}
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(fresh)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected != int64(len(fresh)) {
+				// 조회 이후 다른 곳에서 같은 주문을 저장함 (어느 건인지 알 수 없으므로 롤백 후 건별 처리)
+				return fmt.Errorf("%s 구매 내역 %d건 중 %d건만 저장되었습니다", name, len(fresh), result.RowsAffected)
+			}
+			quantity := 0
+			for _, p := range fresh {
+				quantity += p.Quantity
+			}
+			saved += result.RowsAffected
+			if err := adjustStock(tx, name, -quantity); err != nil {
+				return err
+			}
+			logs := make([]AuditLog, len(fresh))
+			for i, p := range fresh {
+				logs[i] = purchaseAudit(AuditPurchase, p, p.Audit)
+			}
+			if err := appendAudit(tx, logs...); err != nil {
+				return err
+			}
+			for _, p := range fresh {
+				if err := confirmOrder(tx, p); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	return saved, err
+}
+
+// unsavedPurchases: 아직 저장되지 않은 주문만 추림 (uk_user_ticket_payment 기준)
+// 이미 취소된 주문도 처리된 것으로 보므로, 취소 이후 다시 수신된 구매 메시지가 주문을 되살리지 않습니다.
+func unsavedPurchases(tx *gorm.DB, ticketName string, purchases []*Purchase) ([]*Purchase, error) {
+	pairs := make([][]interface{}, len(purchases))
+	for i, p := range purchases {
+		pairs[i] = []interface{}{p.UserID, p.PaymentID}
+	}
+	var existing []Purchase
+	err := tx.Unscoped().Select("user_id", "payment_id").
+		Where("ticket_name = ? AND (user_id, payment_id) IN ?", ticketName, pairs).
+		Find(&existing).Error
+	if err != nil {
+		return nil, err
+	}
+	saved := make(map[[2]string]bool, len(existing))
+	for _, p := range existing {
+		saved[[2]string{p.UserID, p.PaymentID}] = true
+	}
+
+	var fresh []*Purchase
+	for _, p := range purchases {
+		if !saved[[2]string{p.UserID, p.PaymentID}] {
+			fresh = append(fresh, p)
+		}
+	}
+	return fresh, nil
+}
+
+// DeletePurchases: 여러 유저의 취소 메시지를 한 트랜잭션에서 처리(Soft Delete)
+// 취소 대상이 없는 키가 하나라도 있으면 롤백하여, 호출자가 건별로 다시 처리해도 결과가 어긋나지 않도록 합니다.
+func (r *MySQLRepository) DeletePurchases(keys []PurchaseKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		return cancelPurchases(tx, keys)
+	})
 }
@@ -0,0 +1,338 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 주문/주문 항목 상태
+// PENDING(결제 진행 중) → CONFIRMED(워커가 구매 내역 저장) → REFUNDED(취소 후 환불)
+// PENDING에서 결제 실패, 홀드 만료 등으로 확정되지 못하면 CANCELLED
+const (
+	OrderPending   = "PENDING"
+	OrderConfirmed = "CONFIRMED"
+	OrderCancelled = "CANCELLED"
+	OrderRefunded  = "REFUNDED"
+)
+
+// ErrOrderNotFound: 주문이 존재하지 않거나 다른 유저의 주문
+var ErrOrderNotFound = errors.New("주문을 찾을 수 없습니다")
+
+// Order 주문 모델 (결제 1건 단위, ID는 홀드/결제 흐름 ID)
+// 취소해도 행을 지우지 않고 상태만 바꿔 이력을 남깁니다.
+type Order struct {
+	ID         string      `gorm:"primaryKey;size:64" json:"order_id"`
+	UserID     string      `gorm:"column:user_id;index:idx_order_user;not null" json:"user_id"`
+	TicketName string      `gorm:"column:ticket_name;index:idx_order_user;not null" json:"ticket"`
+	Status     string      `gorm:"column:status;not null" json:"status"`
+	PaymentID  string      `gorm:"column:payment_id" json:"payment_id,omitempty"`
+	Amount     int         `gorm:"column:amount" json:"amount"`
+	Items      []OrderItem `gorm:"foreignKey:OrderID" json:"items"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+func (Order) TableName() string {
+	return "orders"
+}
+
+// OrderItem 주문 항목 (티켓 1매 단위, 지정석은 좌석 1개)
+type OrderItem struct {
+	ID        uint      `gorm:"primaryKey" json:"item_id"`
+	OrderID   string    `gorm:"column:order_id;size:64;index;not null" json:"-"`
+	SeatID    uint      `gorm:"column:seat_id" json:"seat_id,omitempty"`
+	Price     int       `gorm:"column:price" json:"price"`
+	Status    string    `gorm:"column:status;not null" json:"status"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (OrderItem) TableName() string {
+	return "order_items"
+}
+
+// NewOrder: 결제 전 PENDING 주문 생성용 (수량만큼 항목 생성, seatIDs가 있으면 좌석별 항목)
+func NewOrder(orderID string, userID string, ticketName string, quantity int, price int, seatIDs []uint) *Order {
+	return newOrder(orderID, userID, ticketName, OrderPending, quantity, price, seatIDs)
+}
+
+func newOrder(orderID string, userID string, ticketName string, status string, quantity int, price int, seatIDs []uint) *Order {
+	order := &Order{ID: orderID, UserID: userID, TicketName: ticketName, Status: status, Amount: price * quantity}
+	if len(seatIDs) > 0 {
+		for _, id := range seatIDs {
+			order.Items = append(order.Items, OrderItem{SeatID: id, Price: price, Status: status})
+		}
+		order.Amount = price * len(seatIDs)
+		return order
+	}
+	for i := 0; i < quantity; i++ {
+		order.Items = append(order.Items, OrderItem{Price: price, Status: status})
+	}
+	return order
+}
+
+// CreateOrder: 주문 생성 (같은 주문 ID로 다시 요청하면 무시)
+func (r *MySQLRepository) CreateOrder(order *Order) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Omit("Items").Clauses(clause.OnConflict{DoNothing: true}).Create(order)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		for i := range order.Items {
+			order.Items[i].OrderID = order.ID
+		}
+		return tx.Create(&order.Items).Error
+	})
+}
+
+// GetOrder: 주문과 항목 조회
+func (r *MySQLRepository) GetOrder(orderID string) (*Order, error) {
+	var order Order
+	err := r.DB.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("id = ?", orderID).Take(&order).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// ListOrders: 유저의 주문 이력 조회 (최신 순, ticketName이 비어 있으면 전체 이벤트)
+func (r *MySQLRepository) ListOrders(userID string, ticketName string) ([]Order, error) {
+	query := r.DB.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("user_id = ?", userID)
+	if ticketName != "" {
+		query = query.Where("ticket_name = ?", ticketName)
+	}
+	var orders []Order
+	err := query.Order("created_at DESC").Find(&orders).Error
+	return orders, err
+}
+
+// RecordOrderPayment: 매입된 결제 정보를 결제 대기 주문에 기록
+// 워커가 구매 내역을 저장하기 전에도 Reconciler가 원래 결제 ID로 구매 내역을 복구할 수 있도록 Redis 확정 전에 남깁니다.
+func (r *MySQLRepository) RecordOrderPayment(orderID string, paymentID string, amount int) error {
+	return r.DB.Model(&Order{}).
+		Where("id = ? AND status = ?", orderID, OrderPending).
+		Updates(map[string]interface{}{"payment_id": paymentID, "amount": amount}).Error
+}
+
+// CancelPendingOrder: 확정되지 못한 주문을 CANCELLED로 변경 (이미 확정/취소된 주문은 그대로)
+func (r *MySQLRepository) CancelPendingOrder(orderID string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Order{}).
+			Where("id = ? AND status = ?", orderID, OrderPending).
+			Update("status", OrderCancelled)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&OrderItem{}).
+			Where("order_id = ? AND status = ?", orderID, OrderPending).
+			Update("status", OrderCancelled).Error
+	})
+}
+
+// confirmOrder: 구매 내역 저장과 같은 트랜잭션에서 주문을 CONFIRMED로 변경
+// API가 만든 PENDING 주문이 없으면(장애 모드 판매, 주문 도입 전 홀드) 확정 상태로 새로 만듭니다.
+func confirmOrder(tx *gorm.DB, p *Purchase) error {
+	if p.OrderID == "" {
+		return nil // 주문 도입 전에 발행된 메시지
+	}
+
+	var order Order
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", p.OrderID).Take(&order).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		price := 0
+		if n := max(p.Quantity, len(p.SeatIDs)); n > 0 {
+			price = p.Amount / n
+		}
+		created := newOrder(p.OrderID, p.UserID, p.TicketName, OrderConfirmed, p.Quantity, price, p.SeatIDs)
+		created.PaymentID, created.Amount = p.PaymentID, p.Amount
+		return tx.Create(created).Error
+	}
+	if err != nil {
+		return err
+	}
+	if order.Status != OrderPending && order.Status != OrderCancelled {
+		return nil
+	}
+
+	// 확정 메시지가 만료 처리보다 늦게 와도 Redis에서는 판매가 확정된 것이므로 CANCELLED도 확정으로 되돌림
+	err = tx.Model(&Order{}).Where("id = ?", p.OrderID).Updates(map[string]interface{}{
+		"status":     OrderConfirmed,
+		"payment_id": p.PaymentID,
+		"amount":     p.Amount,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&OrderItem{}).
+		Where("order_id = ? AND status IN ?", p.OrderID, []string{OrderPending, OrderCancelled}).
+		Update("status", OrderConfirmed).Error
+}
+
+// CancelOrderItems: 주문 항목을 REFUNDED로 변경하고, 같은 트랜잭션에서 구매 수량/금액 차감 + 재고 복구
+// 이미 취소된 항목은 건너뛰므로 같은 취소 메시지가 다시 와도 한 번만 반영됩니다. (반영한 항목 수 반환)
+func (r *MySQLRepository) CancelOrderItems(orderID string, itemIDs []uint, src AuditSource) (int, error) {
+	cancelled := 0
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var order Order
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", orderID).Take(&order).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOrderNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var items []OrderItem
+		err = tx.Where("order_id = ? AND id IN ? AND status = ?", orderID, itemIDs, OrderConfirmed).Find(&items).Error
+		if err != nil || len(items) == 0 {
+			return err
+		}
+		ids := make([]uint, len(items))
+		refund := 0
+		for i, item := range items {
+			ids[i] = item.ID
+			refund += item.Price
+		}
+		if err := tx.Model(&OrderItem{}).Where("id IN ?", ids).Update("status", OrderRefunded).Error; err != nil {
+			return err
+		}
+		cancelled = len(items)
+
+		// 주문에 해당하는 구매 내역에서 취소 수량만큼 차감 (모두 취소되면 Soft Delete)
+		err = tx.Model(&Purchase{}).Where("order_id = ?", orderID).Updates(map[string]interface{}{
+			"quantity": gorm.Expr("quantity - ?", cancelled),
+			"amount":   gorm.Expr("GREATEST(amount - ?, 0)", refund),
+		}).Error
+		if err != nil {
+			return err
+		}
+		if _, err := softDeletePurchases(tx, "order_id = ? AND quantity <= 0", orderID); err != nil {
+			return err
+		}
+		err = appendAudit(tx, AuditLog{
+			UserID:     order.UserID,
+			TicketName: order.TicketName,
+			Action:     AuditCancel,
+			Quantity:   cancelled,
+			OrderID:    orderID,
+			PaymentID:  order.PaymentID,
+			Actor:      src.Actor,
+			Source:     src.Source,
+			Detail:     "주문 항목 " + JoinSeatIDs(ids),
+		})
+		if err != nil {
+			return err
+		}
+		if err := adjustStock(tx, order.TicketName, cancelled); err != nil {
+			return err
+		}
+		return settleOrders(tx, "id = ?", orderID)
+	})
+	return cancelled, err
+}
+
+// TransferOrder: 주문과 주문의 구매 내역을 받는 유저에게 넘김 (재고/결제 정보는 그대로)
+// 이미 받는 유저의 주문이면 반영된 것으로 보고 false를 반환합니다.
+func (r *MySQLRepository) TransferOrder(orderID string, fromUserID string, toUserID string, src AuditSource) (bool, error) {
+	transferred := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var order Order
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", orderID).Take(&order).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOrderNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if order.UserID == toUserID {
+			return nil
+		}
+		if order.UserID != fromUserID {
+			return fmt.Errorf("주문 %s의 소유자가 %s가 아닙니다 (현재 %s)", orderID, fromUserID, order.UserID)
+		}
+
+		var purchase Purchase
+		err = tx.Where("order_id = ?", orderID).Take(&purchase).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 구매 메시지보다 양도 메시지가 먼저 온 경우 (재시도 후 처리)
+			return fmt.Errorf("주문 %s의 구매 내역이 아직 저장되지 않았습니다", orderID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&Order{}).Where("id = ?", orderID).Update("user_id", toUserID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&Purchase{}).Where("id = ?", purchase.ID).Update("user_id", toUserID).Error; err != nil {
+			return err
+		}
+		transferred = true
+
+		sent := purchaseAudit(AuditTransfer, &purchase, src)
+		sent.Detail = "양도 → " + toUserID
+		received := purchaseAudit(AuditTransfer, &purchase, src)
+		received.UserID, received.Detail = toUserID, "양도 ← "+fromUserID
+		return appendAudit(tx, sent, received)
+	})
+	return transferred, err
+}
+
+// CancelSeatItems: 취소된 좌석의 주문 항목을 REFUNDED로 변경
+func (r *MySQLRepository) CancelSeatItems(userID string, ticketName string, seatIDs []uint, src AuditSource) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		orders := tx.Model(&Order{}).Select("id").Where("user_id = ? AND ticket_name = ?", userID, ticketName)
+		result := tx.Model(&OrderItem{}).
+			Where("order_id IN (?) AND seat_id IN ? AND status = ?", orders, seatIDs, OrderConfirmed).
+			Update("status", OrderRefunded)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			err := appendAudit(tx, AuditLog{
+				UserID:     userID,
+				TicketName: ticketName,
+				Action:     AuditCancel,
+				Quantity:   int(result.RowsAffected),
+				Actor:      src.Actor,
+				Source:     src.Source,
+				Detail:     "좌석 " + JoinSeatIDs(seatIDs),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return settleOrders(tx, "user_id = ? AND ticket_name = ?", userID, ticketName)
+	})
+}
+
+// refundOrders: 구매 취소 시 취소된 구매 내역의 주문과 확정된 항목을 모두 REFUNDED로 변경
+func refundOrders(tx *gorm.DB, orderIDs []string) error {
+	if len(orderIDs) == 0 {
+		return nil
+	}
+	err := tx.Model(&OrderItem{}).
+		Where("order_id IN ? AND status = ?", orderIDs, OrderConfirmed).
+		Update("status", OrderRefunded).Error
+	if err != nil {
+		return err
+	}
+	return settleOrders(tx, "id IN ?", orderIDs)
+}
+
+// settleOrders: 확정된 항목이 하나도 남지 않은 CONFIRMED 주문을 REFUNDED로 변경
+func settleOrders(tx *gorm.DB, query string, args ...interface{}) error {
+	return tx.Model(&Order{}).
+		Where(query, args...).
+		Where("status = ?", OrderConfirmed).
+		Where("NOT EXISTS (SELECT 1 FROM order_items WHERE order_items.order_id = orders.id AND order_items.status = ?)", OrderConfirmed).
+		Update("status", OrderRefunded).Error
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -12,6 +13,17 @@ type RedisRepository struct {
 	Client *redis.Client
 }
 
+// 대기열 키는 이벤트별로 분리하여 한 공연의 트래픽이 다른 공연의 예매를 막지 않도록 합니다.
+// Active Set은 유저별 만료 시각(Unix ms)을 score로 가진 Sorted Set이며, 만료된 유저는 Promoter가 정리합니다.
+// (이전의 Set 타입 ticket:active_set:* 키와 섞이지 않도록 키 이름을 분리)
+func activeSetKey(ticketName string) string {
+	return "ticket:active_sessions:" + ticketName
+}
+
+func waitingQueueKey(ticketName string) string {
+	return "ticket:waiting_queue:" + ticketName
+}
+
 // Lock: SetNX를 이용해 열쇠를 획득 시도
 func (r *RedisRepository) Lock(ctx context.Context, key string, expiration time.Duration) (bool, error) {
 	return r.Client.SetNX(ctx, key, "locked", expiration).Result()
@@ -52,15 +64,68 @@ func (r *RedisRepository) DecreaseStock(ctx context.Context, ticketName string)
 	return val, nil
 }
 
-func (r *RedisRepository) AddPurchasedUser(ctx context.Context, ticketName string, userID string) error {
-	// 구매자 명단 Key 예시: "purchased_users:concert_2026"
-	key := "purchased_users:" + ticketName
+// purchasedQtyKey: 일반 예매 이벤트의 유저별 누적 구매 수량 (Hash userID -> 수량)
+// purchased_users 집합은 구매 여부 확인용으로 함께 유지합니다. (수량이 0이 되면 집합에서도 제거)
+func purchasedQtyKey(ticketName string) string {
+	return "purchased_qty:" + ticketName
+}
 
-	// Redis Set에 userID 추가
-	err := r.Client.SAdd(ctx, key, userID).Err()
+// AddPurchased: 유저의 구매 수량을 quantity만큼 늘리고 구매자 명단에 추가
+func (r *RedisRepository) AddPurchased(ctx context.Context, ticketName string, userID string, quantity int) error {
+	pipe := r.Client.TxPipeline()
+	pipe.HIncrBy(ctx, purchasedQtyKey(ticketName), userID, int64(quantity))
+	// 구매자 명단 Key 예시: "purchased_users:concert_2026"
+	pipe.SAdd(ctx, "purchased_users:"+ticketName, userID)
+	_, err := pipe.Exec(ctx)
 	return err
 }
 
+// GetPurchasedUsers: 이벤트의 구매자 명단 전체 조회 (Reconciler용)
+func (r *RedisRepository) GetPurchasedUsers(ctx context.Context, ticketName string) ([]string, error) {
+	return r.Client.SMembers(ctx, "purchased_users:"+ticketName).Result()
+}
+
+// GetPurchasedQuantities: 유저별 누적 구매 수량 조회 (Reconciler용)
+// 수량 도입 전에 구매자 명단에만 추가된 유저는 1매로 간주합니다.
+func (r *RedisRepository) GetPurchasedQuantities(ctx context.Context, ticketName string) (map[string]int, error) {
+	raw, err := r.Client.HGetAll(ctx, purchasedQtyKey(ticketName)).Result()
+	if err != nil {
+		return nil, err
+	}
+	members, err := r.GetPurchasedUsers(ctx, ticketName)
+	if err != nil {
+		return nil, err
+	}
+
+	quantities := make(map[string]int, len(members))
+	for _, userID := range members {
+		quantities[userID] = 1
+	}
+	for userID, v := range raw {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			quantities[userID] = n
+		}
+	}
+	return quantities, nil
+}
+
+// GetPurchasedQuantity: 유저의 누적 구매 수량 (구매자가 아니면 0)
+func (r *RedisRepository) GetPurchasedQuantity(ctx context.Context, ticketName string, userID string) (int, error) {
+	n, err := r.Client.HGet(ctx, purchasedQtyKey(ticketName), userID).Int()
+	if err == nil {
+		return n, nil
+	}
+	if err != redis.Nil {
+		return 0, err
+	}
+	// 수량 도입 전 구매자는 1매
+	purchased, err := r.IsUserPurchased(ctx, ticketName, userID)
+	if err != nil || !purchased {
+		return 0, err
+	}
+	return 1, nil
+}
+
 func (r *RedisRepository) IsUserPurchased(ctx context.Context, ticketName string, userID string) (bool, error) {
 	key := "purchased_users:" + ticketName
 	// Set에 해당 유저가 있는지 확인 (SIsMember)
@@ -74,44 +139,398 @@ func (r *RedisRepository) IncreaseStock(ctx context.Context, ticketName string)
 	return int(val), err
 }
 
+// InitStock: 이벤트 재고를 지정한 수량으로 설정하고 구매자 명단을 비웁니다.
+func (r *RedisRepository) InitStock(ctx context.Context, ticketName string, stock int) error {
+	pipe := r.Client.TxPipeline()
+	pipe.Set(ctx, "ticket_stock:"+ticketName, stock, 0)
+	pipe.Del(ctx, "purchased_users:"+ticketName, purchasedQtyKey(ticketName))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// 재고 키가 없을 때만 판매 상태(재고, 구매자 명단과 수량, 판매 좌석, 반영된 장애 모드 판매 주문)를 한 번에 복원
+// ARGV[4]개의 주문 ID, ARGV[3]개의 (유저 ID, 수량) 쌍 다음에는 (좌석 ID, 유저 ID) 쌍이 이어집니다.
+var seedSaleStateScript = redis.NewScript(`
+    local stock_key = KEYS[1]
+    local purchased_key = KEYS[2]
+    local sold_key = KEYS[3]
+    local qty_key = KEYS[4]
+    local applied_key = KEYS[5]
+    local user_seats_prefix = ARGV[2]
+    local user_count = tonumber(ARGV[3])
+    local order_count = tonumber(ARGV[4])
+
+    -- 이미 판매 중인 상태가 있으면 건드리지 않음
+    if redis.call("EXISTS", stock_key) == 1 then
+        return 0
+    end
+
+    redis.call("DEL", purchased_key, sold_key, qty_key, applied_key)
+    local users_from = 5 + order_count
+    for i = 5, users_from - 1 do
+        redis.call("SADD", applied_key, ARGV[i])
+    end
+    local seats_from = users_from + user_count * 2
+    for i = users_from, seats_from - 1, 2 do
+        redis.call("SADD", purchased_key, ARGV[i])
+        redis.call("HSET", qty_key, ARGV[i], ARGV[i + 1])
+    end
+    for i = seats_from, #ARGV, 2 do
+        redis.call("HSET", sold_key, ARGV[i], ARGV[i + 1])
+        redis.call("SADD", user_seats_prefix .. ARGV[i + 1], ARGV[i])
+    end
+    redis.call("SET", stock_key, ARGV[1])
+    return 1
+`)
+
+// SeedSaleState: Redis에 재고 키가 없는 이벤트만 MySQL 기준 상태로 복원 (복원했으면 true)
+// purchased는 유저별 구매 수량입니다. (지정석 이벤트는 좌석을 보유한 유저)
+// failoverOrders는 복원한 상태에 이미 포함된 장애 모드 판매 주문으로, 이후 재동기화에서 다시 차감하지 않습니다.
+func (r *RedisRepository) SeedSaleState(ctx context.Context, ticketName string, stock int, purchased map[string]int, soldSeats map[uint]string, failoverOrders []string) (bool, error) {
+	keys := []string{"ticket_stock:" + ticketName, "purchased_users:" + ticketName, seatSoldKey(ticketName), purchasedQtyKey(ticketName), failoverAppliedKey(ticketName)}
+	args := []interface{}{stock, userSeatsKey(ticketName, ""), len(purchased), len(failoverOrders)}
+	for _, orderID := range failoverOrders {
+		args = append(args, orderID)
+	}
+	for userID, quantity := range purchased {
+		args = append(args, userID, quantity)
+	}
+	for seatID, userID := range soldSeats {
+		args = append(args, seatID, userID)
+	}
+
+	seeded, err := seedSaleStateScript.Run(ctx, r.Client, keys, args...).Int()
+	return seeded == 1, err
+}
+
+// failoverAppliedKey: Redis에 반영한 장애 모드 판매 주문 ID (Set), 재동기화를 여러 번 수행해도 한 번만 차감
+func failoverAppliedKey(ticketName string) string {
+	return "failover_applied:" + ticketName
+}
+
+// 장애 모드 판매 1건 반영: 주문마다 한 번만 재고 차감 + 구매 수량 추가 (이미 취소된 주문은 반영 표시만)
+// 반환 값: {반영 여부(1/0), 반영 후 재고}, 재고 키가 없으면 {-1, 0}
+var applyDirectSaleScript = redis.NewScript(`
+    local stock_key = KEYS[1]
+    local purchased_key = KEYS[2]
+    local qty_key = KEYS[3]
+    local applied_key = KEYS[4]
+    local order_id = ARGV[1]
+    local user_id = ARGV[2]
+    local qty = tonumber(ARGV[3])
+    local cancelled = ARGV[4] == "1"
+
+    -- 데이터 유실로 재고 키가 없으면 WarmStart가 MySQL(장애 모드 판매분 포함) 기준으로 복원
+    if redis.call("EXISTS", stock_key) == 0 then
+        return {-1, 0}
+    end
+    if redis.call("SADD", applied_key, order_id) == 0 then
+        return {0, tonumber(redis.call("GET", stock_key))}
+    end
+    if cancelled then
+        return {0, tonumber(redis.call("GET", stock_key))}
+    end
+    redis.call("HINCRBY", qty_key, user_id, qty)
+    redis.call("SADD", purchased_key, user_id)
+    return {1, redis.call("DECRBY", stock_key, qty)}
+`)
+
+// ApplyDirectSale: 장애 모드 판매 주문을 Redis 재고/구매자 명단에 반영 (반영했으면 true와 반영 후 재고)
+// 재고 키가 없으면 false와 -1을 반환합니다.
+func (r *RedisRepository) ApplyDirectSale(ctx context.Context, ticketName string, orderID string, userID string, quantity int, cancelled bool) (bool, int, error) {
+	keys := []string{"ticket_stock:" + ticketName, "purchased_users:" + ticketName, purchasedQtyKey(ticketName), failoverAppliedKey(ticketName)}
+	flag := "0"
+	if cancelled {
+		flag = "1"
+	}
+	result, err := applyDirectSaleScript.Run(ctx, r.Client, keys, orderID, userID, quantity, flag).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	if result[0] < 0 {
+		return false, -1, nil
+	}
+	return result[0] == 1, int(result[1]), nil
+}
+
+// AdjustStock: 총 판매 수량 변경 시 남은 재고를 delta만큼 조정
+func (r *RedisRepository) AdjustStock(ctx context.Context, ticketName string, delta int) (int, error) {
+	val, err := r.Client.IncrBy(ctx, "ticket_stock:"+ticketName, int64(delta)).Result()
+	return int(val), err
+}
+
+// 판매 수량 변경: 이미 팔린 수량보다 작게 줄이지 못하도록 남은 재고가 음수가 되면 거절
+// 재고 키가 없으면(판매 상태 복원 전) 만들지 않고 -2를 반환하여, 복원 시 MySQL 기준으로 채워지도록 합니다.
+var resizeStockScript = redis.NewScript(`
+    local stock = redis.call("GET", KEYS[1])
+    if not stock then
+        return -2
+    end
+    local next_stock = tonumber(stock) + tonumber(ARGV[1])
+    if next_stock < 0 then
+        return -1
+    end
+    redis.call("SET", KEYS[1], next_stock)
+    return next_stock
+`)
+
+// ResizeStock: 총 판매 수량 변경에 따라 남은 재고를 delta만큼 조정 (재고가 부족하면 -1, 재고 키가 없으면 -2)
+func (r *RedisRepository) ResizeStock(ctx context.Context, ticketName string, delta int) (int, error) {
+	return resizeStockScript.Run(ctx, r.Client, []string{"ticket_stock:" + ticketName}, delta).Int()
+}
+
+// DeleteStock: 이벤트 삭제 시 재고, 구매자 명단, 대기열 키 제거
+func (r *RedisRepository) DeleteStock(ctx context.Context, ticketName string) error {
+	return r.Client.Del(ctx,
+		"ticket_stock:"+ticketName,
+		"purchased_users:"+ticketName,
+		purchasedQtyKey(ticketName),
+		seatSoldKey(ticketName),
+		reservationsKey(ticketName),
+		reservationUserKey(ticketName),
+		activeSetKey(ticketName),
+		waitingQueueKey(ticketName),
+		lobbyKey(ticketName),
+		lobbyOpenedKey(ticketName),
+		failoverAppliedKey(ticketName),
+	).Err()
+}
+
+// 구매 취소: 구매자 명단/수량 제거 + 구매 수량만큼 재고 복구 + 취소 이벤트 Outbox 기록을 원자적으로 처리
+var cancelPurchaseScript = redis.NewScript(`
+    local stock_key = KEYS[1]
+    local purchased_key = KEYS[2]
+    local qty_key = KEYS[3]
+    local outbox_key = KEYS[4]
+    local user_id = ARGV[1]
+    local expected = tonumber(ARGV[2])
+
+    if redis.call("SISMEMBER", purchased_key, user_id) == 0 then
+        return -1
+    end
+    -- 수량 도입 전 구매자는 1매
+    local qty = tonumber(redis.call("HGET", qty_key, user_id) or "1")
+    if qty ~= expected then
+        return -2
+    end
+    redis.call("SREM", purchased_key, user_id)
+    redis.call("HDEL", qty_key, user_id)
+    local stock = redis.call("INCRBY", stock_key, qty)
+    redis.call("XADD", outbox_key, "*", "key", ARGV[3], "value", ARGV[4], "headers", ARGV[5])
+    return stock
+`)
+
+// CancelPurchase: 유저의 구매 수량 전체를 취소하고 복구된 재고를 반환
+// 구매자가 아니면 -1, 구매 수량이 quantity(환불할 주문을 조회한 시점의 수량)와 다르면 -2
+func (r *RedisRepository) CancelPurchase(ctx context.Context, ticketName string, userID string, quantity int, entry OutboxEntry) (int, error) {
+	keys := []string{"ticket_stock:" + ticketName, "purchased_users:" + ticketName, purchasedQtyKey(ticketName), OutboxStream}
+	args := append([]interface{}{userID, quantity}, entry.args()...)
+	return cancelPurchaseScript.Run(ctx, r.Client, keys, args...).Int()
+}
+
+// orderCancelledKey: 주문에서 취소된 항목 ID (Set), 같은 항목의 중복 취소/환불 방지
+func orderCancelledKey(orderID string) string {
+	return "order_cancelled:" + orderID
+}
+
+// orderCancelledTTL: 워커가 MySQL에 취소를 반영할 때까지 넉넉히 유지
+const orderCancelledTTL = 7 * 24 * time.Hour
+
+// orderOwnerKey: 양도된 주문의 현재 소유자 (MySQL 반영 전에 이전 소유자가 다시 양도/취소하지 못하도록)
+func orderOwnerKey(orderID string) string {
+	return "order_owner:" + orderID
+}
+
+// 주문 항목 취소: 이미 취소된 항목이 없을 때만 구매 수량 차감 + 재고 복구 + 취소 이벤트 Outbox 기록
+// ARGV[3..5]는 Outbox 항목, ARGV[6..]은 취소할 항목 ID
+var cancelItemsScript = redis.NewScript(`
+    local stock_key = KEYS[1]
+    local purchased_key = KEYS[2]
+    local qty_key = KEYS[3]
+    local outbox_key = KEYS[4]
+    local cancelled_key = KEYS[5]
+    local owner_key = KEYS[6]
+    local user_id = ARGV[1]
+    local ttl = tonumber(ARGV[2])
+    local n = #ARGV - 5
+
+    -- 다른 유저에게 양도된 주문
+    local owner = redis.call("GET", owner_key)
+    if owner and owner ~= user_id then
+        return -1
+    end
+    for i = 6, #ARGV do
+        if redis.call("SISMEMBER", cancelled_key, ARGV[i]) == 1 then
+            return -1
+        end
+    end
+
+    -- 수량 도입 전 구매자는 1매
+    local bought = tonumber(redis.call("HGET", qty_key, user_id) or "0")
+    if bought == 0 and redis.call("SISMEMBER", purchased_key, user_id) == 1 then
+        bought = 1
+    end
+    if bought < n then
+        return -1
+    end
+
+    for i = 6, #ARGV do
+        redis.call("SADD", cancelled_key, ARGV[i])
+    end
+    redis.call("PEXPIRE", cancelled_key, ttl)
+    if bought == n then
+        redis.call("SREM", purchased_key, user_id)
+        redis.call("HDEL", qty_key, user_id)
+    else
+        redis.call("HSET", qty_key, user_id, bought - n)
+    end
+    local stock = redis.call("INCRBY", stock_key, n)
+    redis.call("XADD", outbox_key, "*", "key", ARGV[3], "value", ARGV[4], "headers", ARGV[5])
+    return stock
+`)
+
+// CancelOrderItems: 주문 항목 일부를 취소하고 복구된 재고를 반환 (이미 취소된 항목이 있거나 구매 수량이 부족하면 -1)
+func (r *RedisRepository) CancelOrderItems(ctx context.Context, ticketName string, userID string, orderID string, itemIDs []uint, entry OutboxEntry) (int, error) {
+	keys := []string{"ticket_stock:" + ticketName, "purchased_users:" + ticketName, purchasedQtyKey(ticketName), OutboxStream, orderCancelledKey(orderID), orderOwnerKey(orderID)}
+	args := append([]interface{}{userID, orderCancelledTTL.Milliseconds()}, entry.args()...)
+	for _, id := range itemIDs {
+		args = append(args, id)
+	}
+	return cancelItemsScript.Run(ctx, r.Client, keys, args...).Int()
+}
+
+// 주문 양도: 보내는 유저의 구매 수량을 받는 유저에게 옮기고 양도 이벤트 Outbox 기록
+// 반환 값: 1(성공), -1(소유하지 않은 주문/이미 취소된 항목), -2(받는 유저의 구매 한도 초과)
+var transferScript = redis.NewScript(`
+    local purchased_key = KEYS[1]
+    local qty_key = KEYS[2]
+    local outbox_key = KEYS[3]
+    local cancelled_key = KEYS[4]
+    local owner_key = KEYS[5]
+    local from_id = ARGV[1]
+    local to_id = ARGV[2]
+    local limit = tonumber(ARGV[3])
+    local ttl = tonumber(ARGV[4])
+    local n = #ARGV - 7
+
+    local owner = redis.call("GET", owner_key)
+    if owner and owner ~= from_id then
+        return -1
+    end
+    for i = 8, #ARGV do
+        if redis.call("SISMEMBER", cancelled_key, ARGV[i]) == 1 then
+            return -1
+        end
+    end
+
+    -- 수량 도입 전 구매자는 1매
+    local function held(user_id)
+        local q = tonumber(redis.call("HGET", qty_key, user_id) or "0")
+        if q == 0 and redis.call("SISMEMBER", purchased_key, user_id) == 1 then
+            q = 1
+        end
+        return q
+    end
+
+    local from_qty = held(from_id)
+    if from_qty < n then
+        return -1
+    end
+    local to_qty = held(to_id)
+    if to_qty + n > limit then
+        return -2
+    end
+
+    if from_qty == n then
+        redis.call("SREM", purchased_key, from_id)
+        redis.call("HDEL", qty_key, from_id)
+    else
+        redis.call("HSET", qty_key, from_id, from_qty - n)
+    end
+    redis.call("SADD", purchased_key, to_id)
+    redis.call("HSET", qty_key, to_id, to_qty + n)
+    redis.call("SET", owner_key, to_id, "PX", ttl)
+    redis.call("XADD", outbox_key, "*", "key", ARGV[5], "value", ARGV[6], "headers", ARGV[7])
+    return 1
+`)
+
+// TransferOrder: 주문의 남은 항목(itemIDs)을 받는 유저에게 양도 (limit: 받는 유저의 1인 누적 한도)
+func (r *RedisRepository) TransferOrder(ctx context.Context, ticketName string, fromUserID string, toUserID string, orderID string, itemIDs []uint, limit int, entry OutboxEntry) (int, error) {
+	keys := []string{"purchased_users:" + ticketName, purchasedQtyKey(ticketName), OutboxStream, orderCancelledKey(orderID), orderOwnerKey(orderID)}
+	args := append([]interface{}{fromUserID, toUserID, limit, orderCancelledTTL.Milliseconds()}, entry.args()...)
+	for _, id := range itemIDs {
+		args = append(args, id)
+	}
+	return transferScript.Run(ctx, r.Client, keys, args...).Int()
+}
+
 func (r *RedisRepository) RemovePurchasedUser(ctx context.Context, ticketName string, userID string) error {
-	key := "purchased_users:" + ticketName
-	return r.Client.SRem(ctx, key, userID).Err() // 구매 명단에서 유저 삭제
+	pipe := r.Client.TxPipeline()
+	pipe.SRem(ctx, "purchased_users:"+ticketName, userID) // 구매 명단에서 유저 삭제
+	pipe.HDel(ctx, purchasedQtyKey(ticketName), userID)
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
 var enqueueScript = redis.NewScript(`
     local active_set_key = KEYS[1]
     local waiting_queue_key = KEYS[2]
+    local lobby_key = KEYS[3]
     local user_id = ARGV[1]
     local max_active = tonumber(ARGV[2])
     local timestamp = ARGV[3]
+    local now = tonumber(ARGV[4])
+    local session_deadline = tonumber(ARGV[5])
+
+    -- 1. 이미 Active Set에 있는지 확인 (만료 시각 전이면 예매 진행 시간만큼 세션 연장)
+    local deadline = redis.call("ZSCORE", active_set_key, user_id)
+    if deadline then
+        if tonumber(deadline) >= now then
+            redis.call("ZADD", active_set_key, session_deadline, user_id)
+            return {"ACTIVE", 0}
+        end
+        -- 유효 시간이 지나면 자리를 내놓고 대기열 맨 뒤로 다시 진입
+        redis.call("ZREM", active_set_key, user_id)
+    end
 
-    -- 1. 이미 Active Set에 있는지 확인
-    if redis.call("SISMEMBER", active_set_key, user_id) == 1 then
-        return {"ACTIVE", 0}
+    -- 2. 이미 대기 중이면 순번 유지 (재요청해도 뒤로 밀리지 않음)
+    local rank = redis.call("ZRANK", waiting_queue_key, user_id)
+    if rank then
+        return {"WAITING", rank + 1}
     end
 
-    -- 2. Active Set 자리가 있는지 확인
-    local current_active_size = redis.call("SCARD", active_set_key)
-    if current_active_size < max_active then
-        redis.call("SADD", active_set_key, user_id)
+    -- 판매 시작 직후 대기실이 아직 열리기 전이면 대기실 유저는 배정을 기다림
+    local lobby_open = redis.call("EXISTS", lobby_key) == 1
+    if lobby_open and redis.call("SISMEMBER", lobby_key, user_id) == 1 then
+        return {"LOBBY", 0}
+    end
+
+    -- 3. 대기자가 없고 Active Set 자리가 있으면 바로 입장 (아직 정리되지 않은 만료 세션은 제외하고 계산)
+    --    배정 전인 대기실 유저가 있으면 새로 온 유저가 앞지르지 않도록 대기열로 보냄
+    local current_active_size = redis.call("ZCOUNT", active_set_key, now, "+inf")
+    if not lobby_open and current_active_size < max_active and redis.call("ZCARD", waiting_queue_key) == 0 then
+        redis.call("ZADD", active_set_key, session_deadline, user_id)
         return {"ACTIVE", 0}
     end
 
-    -- 3. 자리가 없으면 대기열 진입
-    redis.call("ZADD", waiting_queue_key, timestamp, user_id)
-    local rank = redis.call("ZRANK", waiting_queue_key, user_id)
-    
+    -- 4. 자리가 없으면 대기열 진입 (NX: 처음 진입한 시각 유지)
+    redis.call("ZADD", waiting_queue_key, "NX", timestamp, user_id)
+    rank = redis.call("ZRANK", waiting_queue_key, user_id)
+
     -- 리스트 형태로 반환 (상태, 순번)
     return {"WAITING", rank + 1}
 `)
 
-func (r *RedisRepository) TryEnterOrEnqueue(ctx context.Context, userID string, maxActive int) (string, int, error) {
-	keys := []string{"ticket:active_set", "ticket:waiting_queue"}
+// TryEnterOrEnqueue: Active Set 입장 또는 대기열 진입 (입장하면 sessionTTL 동안 자리 유지)
+func (r *RedisRepository) TryEnterOrEnqueue(ctx context.Context, ticketName string, userID string, maxActive int, sessionTTL time.Duration) (string, int, error) {
+	keys := []string{activeSetKey(ticketName), waitingQueueKey(ticketName), lobbyKey(ticketName)}
+	now := time.Now()
 	args := []interface{}{
 		userID,
 		maxActive,
-		time.Now().UnixNano(),
+		now.UnixNano(),
+		now.UnixMilli(),
+		now.Add(sessionTTL).UnixMilli(),
 	}
 
 	result, err := enqueueScript.Run(ctx, r.Client, keys, args...).Result()
@@ -140,46 +559,109 @@ func (r *RedisRepository) TryEnterOrEnqueue(ctx context.Context, userID string,
 	return status, rank, nil
 }
 
-// RemoveActiveUser: 예매 완료 또는 취소 시 Active Set에서 유저 제거
-func (r *RedisRepository) RemoveActiveUser(ctx context.Context, userID string) error {
-	return r.Client.SRem(ctx, "ticket:active_set", userID).Err()
+// RemoveActiveUser: 예매 완료 또는 취소 시 Active Set에서 유저 제거 (대기열 완료 처리량에 집계)
+func (r *RedisRepository) RemoveActiveUser(ctx context.Context, ticketName string, userID string) error {
+	keys := []string{activeSetKey(ticketName), queueRateKey(ticketName, QueueCompleted, time.Now().Unix())}
+	return removeActiveScript.Run(ctx, r.Client, keys, userID, int(queueRateRetention/time.Second)).Err()
+}
+
+// QueuePosition: 대기열에서의 유저 상태 (재진입 없이 조회만 함)
+// State: LOBBY(판매 시작 전 대기실), WAITING(Rank), ADMITTED(AdmittedUntil까지 예매 가능), EXPIRED(입장 유효 시간 경과, 아직 정리 전), NONE
+type QueuePosition struct {
+	State         string
+	Rank          int
+	AdmittedUntil time.Time
+}
+
+// GetQueuePosition: Active Set 만료 시각/대기 순번을 한 번에 조회
+func (r *RedisRepository) GetQueuePosition(ctx context.Context, ticketName string, userID string) (QueuePosition, error) {
+	pipe := r.Client.Pipeline()
+	deadline := pipe.ZScore(ctx, activeSetKey(ticketName), userID)
+	rank := pipe.ZRank(ctx, waitingQueueKey(ticketName), userID)
+	inLobby := pipe.SIsMember(ctx, lobbyKey(ticketName), userID)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return QueuePosition{}, err
+	}
+	if inLobby.Val() {
+		return QueuePosition{State: "LOBBY"}, nil
+	}
+
+	if ms, err := deadline.Result(); err == nil {
+		until := time.UnixMilli(int64(ms))
+		if time.Now().After(until) {
+			return QueuePosition{State: "EXPIRED"}, nil
+		}
+		return QueuePosition{State: "ADMITTED", AdmittedUntil: until}, nil
+	}
+	if n, err := rank.Result(); err == nil {
+		return QueuePosition{State: "WAITING", Rank: int(n) + 1}, nil
+	}
+	return QueuePosition{State: "NONE"}, nil
 }
 
 var promoteScript = redis.NewScript(`
     local waiting_queue_key = KEYS[1]
     local active_set_key = KEYS[2]
     local max_active = tonumber(ARGV[1])
+    local deadline = ARGV[2]
+    local now = ARGV[3]
+
+    -- 0. 만료 시각이 지난 세션 정리 (요청 도중 프로세스가 죽었거나 승급 후 돌아오지 않은 유저)
+    local evicted = redis.call("ZREMRANGEBYSCORE", active_set_key, "-inf", "(" .. now)
 
     -- 1. 현재 Active Set의 빈자리 계산
-    local current_active_size = redis.call("SCARD", active_set_key)
+    local current_active_size = redis.call("ZCARD", active_set_key)
     local seats_available = max_active - current_active_size
 
     if seats_available <= 0 then
-        return 0
+        return {evicted, {}}
     end
 
     -- 2. 빈자리만큼 대기열에서 가장 오래된 유저들을 뽑아옴
     local users = redis.call("ZPOPMIN", waiting_queue_key, seats_available)
-    local promoted_count = 0
+    local promoted = {}
 
     -- ZPOPMIN은 {user1, score1, user2, score2...} 형태로 반환하므로 인덱스 2개씩 점프
+    -- 승급된 유저는 deadline(입장 유효 시각)까지 예매를 시작해야 함
     for i = 1, #users, 2 do
-        redis.call("SADD", active_set_key, users[i])
-        promoted_count = promoted_count + 1
+        redis.call("ZADD", active_set_key, deadline, users[i])
+        table.insert(promoted, users[i])
     end
 
-    return promoted_count
+    -- 정리된 세션 수와 승급된 유저 목록 반환 (메트릭/입장 알림용)
+    return {evicted, promoted}
 `)
 
-func (r *RedisRepository) PromoteUsers(ctx context.Context, maxActive int) (int, error) {
-	keys := []string{"ticket:waiting_queue", "ticket:active_set"}
-	args := []interface{}{maxActive}
+// PromoteUsers: 만료된 세션을 정리한 뒤 빈자리만큼 대기열 앞쪽 유저를 승급하고 grace 동안 입장을 허용
+// 승급된 유저 목록과 정리된(만료) 세션 수를 반환합니다.
+func (r *RedisRepository) PromoteUsers(ctx context.Context, ticketName string, maxActive int, grace time.Duration) ([]string, int, error) {
+	keys := []string{waitingQueueKey(ticketName), activeSetKey(ticketName)}
+	now := time.Now()
+	args := []interface{}{maxActive, now.Add(grace).UnixMilli(), now.UnixMilli()}
 
-	result, err := promoteScript.Run(ctx, r.Client, keys, args...).Int()
+	result, err := promoteScript.Run(ctx, r.Client, keys, args...).Slice()
 	if err != nil {
-		return 0, err
+		return nil, 0, err
 	}
-	return result, nil
+	if len(result) != 2 {
+		return nil, 0, fmt.Errorf("unexpected lua script result format")
+	}
+	evicted, _ := result[0].(int64)
+	var users []string
+	if list, ok := result[1].([]interface{}); ok {
+		for _, u := range list {
+			if id, ok := u.(string); ok {
+				users = append(users, id)
+			}
+		}
+	}
+	return users, int(evicted), nil
+}
+
+// HasStock: 이벤트 재고 키 존재 여부 (GetStock은 키가 없으면 0을 반환하므로 구분용)
+func (r *RedisRepository) HasStock(ctx context.Context, ticketName string) (bool, error) {
+	n, err := r.Client.Exists(ctx, "ticket_stock:"+ticketName).Result()
+	return n == 1, err
 }
 
 func (r *RedisRepository) GetStock(ctx context.Context, ticketName string) (int, error) {
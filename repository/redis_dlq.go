@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+/*
+ * DLQ 관리 상태 (Kafka 메시지는 수정/삭제할 수 없으므로 처리 결과를 Redis에 별도로 기록)
+ * - dlq:status  (Hash) "{partition}:{offset}" -> DLQMark JSON (DISCARDED / REPLAYED / REQUEUED / PARKED / REPLAY_FAILED)
+ * - dlq:purged  (Hash) partition -> offset, 이 offset 이전 메시지는 목록/재처리 대상에서 제외
+ */
+
+const (
+	DLQPending      = "PENDING"
+	DLQDiscarded    = "DISCARDED"
+	DLQReplayed     = "REPLAYED"
+	DLQReplayFailed = "REPLAY_FAILED"
+	// 재처리 실패로 시도 횟수를 올려 DLQ에 다시 넣었거나(REQUEUED), 한도를 넘겨 Parked 토픽으로 격리함(PARKED)
+	DLQRequeued = "REQUEUED"
+	DLQParked   = "PARKED"
+)
+
+// DLQRef: DLQ 메시지 위치
+type DLQRef struct {
+	Partition int   `json:"partition"`
+	Offset    int64 `json:"offset"`
+}
+
+func (r DLQRef) field() string {
+	return fmt.Sprintf("%d:%d", r.Partition, r.Offset)
+}
+
+// DLQMark: 운영자가 남긴 처리 결과
+type DLQMark struct {
+	Status string    `json:"status"`
+	Note   string    `json:"note,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+func (r *RedisRepository) SetDLQStatus(ctx context.Context, ref DLQRef, status string, note string) error {
+	mark, _ := json.Marshal(DLQMark{Status: status, Note: note, At: time.Now()})
+	return r.Client.HSet(ctx, "dlq:status", ref.field(), mark).Err()
+}
+
+// GetDLQStatuses: 여러 메시지의 처리 결과 조회 (기록이 없는 메시지는 결과에 포함되지 않음)
+func (r *RedisRepository) GetDLQStatuses(ctx context.Context, refs []DLQRef) (map[DLQRef]DLQMark, error) {
+	marks := make(map[DLQRef]DLQMark)
+	if len(refs) == 0 {
+		return marks, nil
+	}
+	fields := make([]string, len(refs))
+	for i, ref := range refs {
+		fields[i] = ref.field()
+	}
+	values, err := r.Client.HMGet(ctx, "dlq:status", fields...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range values {
+		raw, ok := v.(string)
+		if !ok {
+			continue
+		}
+		var mark DLQMark
+		if json.Unmarshal([]byte(raw), &mark) == nil {
+			marks[refs[i]] = mark
+		}
+	}
+	return marks, nil
+}
+
+// 기존 값보다 클 때만 갱신 (이미 정리한 구간이 되살아나지 않도록)
+var purgeDLQScript = redis.NewScript(`
+    local current = tonumber(redis.call("HGET", KEYS[1], ARGV[1]) or "-1")
+    if tonumber(ARGV[2]) > current then
+        redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+        return 1
+    end
+    return 0
+`)
+
+// PurgeDLQ: partition의 before 이전 메시지를 정리된 것으로 표시
+func (r *RedisRepository) PurgeDLQ(ctx context.Context, partition int, before int64) error {
+	return purgeDLQScript.Run(ctx, r.Client, []string{"dlq:purged"}, partition, before).Err()
+}
+
+// GetDLQPurged: 파티션별 정리 기준 offset
+func (r *RedisRepository) GetDLQPurged(ctx context.Context) (map[int]int64, error) {
+	values, err := r.Client.HGetAll(ctx, "dlq:purged").Result()
+	if err != nil {
+		return nil, err
+	}
+	purged := make(map[int]int64, len(values))
+	for p, o := range values {
+		partition, err1 := strconv.Atoi(p)
+		offset, err2 := strconv.ParseInt(o, 10, 64)
+		if err1 == nil && err2 == nil {
+			purged[partition] = offset
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+/*
+ * 판매 전 대기실 (Lobby)
+ * - ticket:lobby:{ticket} (Set) 판매 시작 전에 도착한 유저 (도착 순서를 기록하지 않음)
+ * - ticket:lobby_opened:{ticket} (String) 대기실이 열렸다는 표시(배정 인원), 한 번 열린 대기실에는 다시 입장할 수 없음
+ * - 판매가 시작되면 서비스가 암호학적 난수로 섞은 순서대로 대기열 맨 앞(score 1..N)에 배정하고 대기실을 삭제합니다.
+ *   대기실은 이벤트마다 한 번만 열리므로 score 1..N은 항상 대기실 유저에게만 배정됩니다.
+ * - 판매 시작 후 도착한 유저는 도착 시각(ns)을 score로 가지므로 항상 대기실 유저 뒤에 FIFO로 줄을 섭니다.
+ * - 판매 일정이 바뀌면 열린 대기실을 초기화하여 새 판매 시작 전에 다시 입장할 수 있도록 합니다.
+ */
+
+func lobbyKey(ticketName string) string {
+	return "ticket:lobby:" + ticketName
+}
+
+func lobbyOpenedKey(ticketName string) string {
+	return "ticket:lobby_opened:" + ticketName
+}
+
+var joinLobbyScript = redis.NewScript(`
+    if redis.call("EXISTS", KEYS[2]) == 1 then
+        return -1
+    end
+    redis.call("SADD", KEYS[1], ARGV[1])
+    return redis.call("SCARD", KEYS[1])
+`)
+
+// JoinLobby: 대기실 입장 (이미 입장한 유저는 그대로) 후 현재 대기실 인원 반환 (이미 열린 대기실이면 -1)
+func (r *RedisRepository) JoinLobby(ctx context.Context, ticketName string, userID string) (int, error) {
+	keys := []string{lobbyKey(ticketName), lobbyOpenedKey(ticketName)}
+	return joinLobbyScript.Run(ctx, r.Client, keys, userID).Int()
+}
+
+// GetLobbyMembers: 대기실 유저 목록 (대기실이 없거나 이미 열렸으면 빈 목록)
+func (r *RedisRepository) GetLobbyMembers(ctx context.Context, ticketName string) ([]string, error) {
+	return r.Client.SMembers(ctx, lobbyKey(ticketName)).Result()
+}
+
+var openLobbyScript = redis.NewScript(`
+    local lobby_key = KEYS[1]
+    local waiting_queue_key = KEYS[2]
+    local opened_key = KEYS[3]
+
+    -- 다른 서버가 먼저 대기실을 열었으면 아무것도 하지 않음
+    if redis.call("EXISTS", opened_key) == 1 then
+        return -2
+    end
+
+    -- 목록을 읽은 뒤 들어온 유저가 있으면 섞지 않은 유저가 생기므로 다시 읽어서 섞도록 함
+    if redis.call("SCARD", lobby_key) ~= #ARGV then
+        return -1
+    end
+    for i = 1, #ARGV do
+        if redis.call("SISMEMBER", lobby_key, ARGV[i]) == 0 then
+            return -1
+        end
+    end
+
+    -- 섞인 순서대로 대기열 맨 앞에 배정 (판매 시작 후 도착한 유저보다 항상 앞)
+    for i = 1, #ARGV do
+        redis.call("ZADD", waiting_queue_key, i, ARGV[i])
+    end
+    redis.call("DEL", lobby_key)
+    redis.call("SET", opened_key, #ARGV)
+    return #ARGV
+`)
+
+// OpenLobby: 대기실 유저를 order 순서로 대기열에 배정하고 대기실을 닫음 (배정된 인원 반환)
+// order가 현재 대기실 유저 전체와 다르면 -1, 이미 열린 대기실이면 -2
+func (r *RedisRepository) OpenLobby(ctx context.Context, ticketName string, order []string) (int, error) {
+	keys := []string{lobbyKey(ticketName), waitingQueueKey(ticketName), lobbyOpenedKey(ticketName)}
+	args := make([]interface{}, len(order))
+	for i, userID := range order {
+		args[i] = userID
+	}
+	return openLobbyScript.Run(ctx, r.Client, keys, args...).Int()
+}
+
+var resetLobbyScript = redis.NewScript(`
+    -- 아직 열리지 않은 대기실은 입장한 유저를 그대로 유지
+    if redis.call("EXISTS", KEYS[2]) == 0 then
+        return 0
+    end
+    redis.call("DEL", KEYS[1], KEYS[2])
+    return 1
+`)
+
+// ResetLobby: 이미 열린 대기실의 표시와 잔여 유저를 지워 다시 열 수 있도록 함 (초기화했으면 true)
+func (r *RedisRepository) ResetLobby(ctx context.Context, ticketName string) (bool, error) {
+	keys := []string{lobbyKey(ticketName), lobbyOpenedKey(ticketName)}
+	n, err := resetLobbyScript.Run(ctx, r.Client, keys).Int()
+	return n == 1, err
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+/*
+ * Transactional Outbox (Redis Stream)
+ * 재고/구매자 명단을 바꾸는 Lua 스크립트 안에서 Kafka로 보낼 메시지를 같은 원자적 단위로 XADD 합니다.
+ * OutboxRelay가 스트림을 읽어 ticket-topic으로 발행한 뒤 XACK/XDEL 하므로,
+ * 발행 직전에 프로세스가 죽어도 메시지는 스트림에 남아 재발행됩니다. (At-Least-Once)
+ */
+
+const (
+	OutboxStream = "outbox:ticket-events"
+	OutboxGroup  = "outbox-relay"
+	// OutboxLeaderKey: 발행 중인 Relay(리더)의 Consumer 이름 (리더 한 대만 발행해야 같은 유저의 이벤트 순서가 유지됨)
+	OutboxLeaderKey = "outbox:relay-leader"
+)
+
+// OutboxEntry: Kafka 메시지 한 건 (Key = 유저 ID, Value/Headers = 메시지 본문)
+type OutboxEntry struct {
+	ID      string
+	Key     string
+	Value   string
+	Headers map[string]string
+}
+
+// args: Lua 스크립트에 넘길 ARGV 3개 (key, value, headers JSON)
+func (e OutboxEntry) args() []interface{} {
+	headers, _ := json.Marshal(e.Headers)
+	return []interface{}{e.Key, e.Value, string(headers)}
+}
+
+// AppendOutbox: 스크립트 밖에서 단독으로 발행 의도를 기록 (만료 이벤트 등)
+func (r *RedisRepository) AppendOutbox(ctx context.Context, entry OutboxEntry) error {
+	args := entry.args()
+	return r.Client.XAdd(ctx, &redis.XAddArgs{
+		Stream: OutboxStream,
+		Values: []interface{}{"key", args[0], "value", args[1], "headers", args[2]},
+	}).Err()
+}
+
+// EnsureOutboxGroup: Relay용 Consumer Group 생성 (이미 있으면 무시)
+func (r *RedisRepository) EnsureOutboxGroup(ctx context.Context) error {
+	err := r.Client.XGroupCreateMkStream(ctx, OutboxStream, OutboxGroup, "0").Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return err
+}
+
+// ReadOutbox: 아직 어떤 Relay에도 전달되지 않은 새 항목을 읽음
+func (r *RedisRepository) ReadOutbox(ctx context.Context, consumer string, count int, block time.Duration) ([]OutboxEntry, error) {
+	streams, err := r.Client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    OutboxGroup,
+		Consumer: consumer,
+		Streams:  []string{OutboxStream, ">"},
+		Count:    int64(count),
+		Block:    block,
+	}).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []OutboxEntry
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			entries = append(entries, toOutboxEntry(msg))
+		}
+	}
+	return entries, nil
+}
+
+// ClaimStaleOutbox: 다른 Relay가 읽고 minIdle 동안 ACK 하지 못한 항목(발행 실패/프로세스 종료)을 가져옴
+func (r *RedisRepository) ClaimStaleOutbox(ctx context.Context, consumer string, minIdle time.Duration, count int) ([]OutboxEntry, error) {
+	msgs, _, err := r.Client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   OutboxStream,
+		Group:    OutboxGroup,
+		Consumer: consumer,
+		MinIdle:  minIdle,
+		Start:    "0",
+		Count:    int64(count),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]OutboxEntry, 0, len(msgs))
+	for _, msg := range msgs {
+		entries = append(entries, toOutboxEntry(msg))
+	}
+	return entries, nil
+}
+
+// 리더 리스 획득/연장: 이미 owner가 리더면 만료 시각만 연장
+var acquireOutboxLeaseScript = redis.NewScript(`
+    if redis.call("GET", KEYS[1]) == ARGV[1] then
+        redis.call("PEXPIRE", KEYS[1], ARGV[2])
+        return 1
+    end
+    if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+        return 1
+    end
+    return 0
+`)
+
+// 리더 리스 반납: owner가 리더일 때만 삭제 (만료 후 다른 Relay가 얻은 리스를 지우지 않도록)
+var releaseOutboxLeaseScript = redis.NewScript(`
+    if redis.call("GET", KEYS[1]) == ARGV[1] then
+        return redis.call("DEL", KEYS[1])
+    end
+    return 0
+`)
+
+// AcquireOutboxLease: owner가 ttl 동안 발행을 전담하는 리더 리스를 얻거나 연장 (다른 Relay가 리더면 false)
+func (r *RedisRepository) AcquireOutboxLease(ctx context.Context, owner string, ttl time.Duration) (bool, error) {
+	n, err := acquireOutboxLeaseScript.Run(ctx, r.Client, []string{OutboxLeaderKey}, owner, ttl.Milliseconds()).Int()
+	return n == 1, err
+}
+
+// ReleaseOutboxLease: 종료하는 리더가 리스를 반납하여 대기 중인 Relay가 바로 이어받도록 함
+func (r *RedisRepository) ReleaseOutboxLease(ctx context.Context, owner string) error {
+	return releaseOutboxLeaseScript.Run(ctx, r.Client, []string{OutboxLeaderKey}, owner).Err()
+}
+
+// AckOutbox: Kafka 발행이 끝난 항목을 완료 처리하고 스트림에서 제거
+func (r *RedisRepository) AckOutbox(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	pipe := r.Client.TxPipeline()
+	pipe.XAck(ctx, OutboxStream, OutboxGroup, ids...)
+	pipe.XDel(ctx, OutboxStream, ids...)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// OutboxBacklog: 아직 발행 완료되지 않은 항목 수 (모니터링용)
+func (r *RedisRepository) OutboxBacklog(ctx context.Context) (int64, error) {
+	return r.Client.XLen(ctx, OutboxStream).Result()
+}
+
+func toOutboxEntry(msg redis.XMessage) OutboxEntry {
+	entry := OutboxEntry{ID: msg.ID}
+	entry.Key, _ = msg.Values["key"].(string)
+	entry.Value, _ = msg.Values["value"].(string)
+	if raw, ok := msg.Values["headers"].(string); ok && raw != "" && raw != "null" {
+		json.Unmarshal([]byte(raw), &entry.Headers)
+	}
+	return entry
+}
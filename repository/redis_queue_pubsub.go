@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+)
+
+/*
+ * 대기열 알림 (Redis Pub/Sub)
+ * - queue:updates:{ticket} 채널로 Promoter의 승급 결과를 발행합니다.
+ * - API 서버는 queue:updates:* 를 한 번만 구독하여 자신에게 연결된 대기 유저에게 전달하므로, 서버가 여러 대여도 같은 알림을 받습니다.
+ * - Pub/Sub은 유실될 수 있으므로 순번의 기준은 항상 Redis 대기열이며, 알림은 갱신 시점을 알려주는 용도입니다.
+ */
+
+// QueueUpdate: 한 번의 승급 결과
+type QueueUpdate struct {
+	TicketName    string   `json:"ticket"`
+	Admitted      []string `json:"admitted"`               // 이번에 승급된 유저
+	AdmittedUntil int64    `json:"admitted_until"`         // 승급된 유저의 입장 유효 시각 (Unix ms)
+	LobbyOpened   bool     `json:"lobby_opened,omitempty"` // 판매 시작으로 대기실 유저가 대기열에 배정됨 (구독자는 순번을 다시 조회)
+}
+
+func queueUpdatesChannel(ticketName string) string {
+	return "queue:updates:" + ticketName
+}
+
+// PublishQueueUpdate: 승급 결과 발행
+func (r *RedisRepository) PublishQueueUpdate(ctx context.Context, update QueueUpdate) error {
+	payload, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+	return r.Client.Publish(ctx, queueUpdatesChannel(update.TicketName), payload).Err()
+}
+
+// SubscribeQueueUpdates: 모든 이벤트의 승급 알림 구독 (연결이 끊기면 go-redis가 재구독)
+func (r *RedisRepository) SubscribeQueueUpdates(ctx context.Context) (<-chan QueueUpdate, error) {
+	pubsub := r.Client.PSubscribe(ctx, queueUpdatesChannel("*"))
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	out := make(chan QueueUpdate, 64)
+	go func() {
+		defer close(out)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var update QueueUpdate
+				if err := json.Unmarshal([]byte(msg.Payload), &update); err != nil {
+					continue
+				}
+				select {
+				case out <- update:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
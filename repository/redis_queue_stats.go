@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 대기열 처리량 종류
+const (
+	QueueAdmitted  = "admitted"  // Promoter가 대기열에서 Active Set으로 승급한 인원
+	QueueCompleted = "completed" // 예매 요청을 마치고 Active Set에서 나간 인원
+)
+
+/*
+ * 대기열 처리량 (예상 대기 시간 계산용)
+ * - ticket:queue_rate:{ticket}:{kind}:{unix초} 키에 초 단위로 인원을 누적합니다. (여러 API 서버의 Promoter 합산)
+ * - 키는 queueRateRetention 뒤 만료되므로 별도 정리가 필요 없습니다.
+ */
+
+const queueRateRetention = 10 * time.Minute
+
+func queueRateKey(ticketName string, kind string, sec int64) string {
+	return "ticket:queue_rate:" + ticketName + ":" + kind + ":" + strconv.FormatInt(sec, 10)
+}
+
+// QueueThroughput: 최근 구간의 초당 처리량
+type QueueThroughput struct {
+	Admitted  float64 `json:"admitted_per_second"`
+	Completed float64 `json:"completed_per_second"`
+}
+
+// RecordQueueThroughput: 현재 초 구간에 처리 인원 누적
+func (r *RedisRepository) RecordQueueThroughput(ctx context.Context, ticketName string, kind string, n int) error {
+	if n <= 0 {
+		return nil
+	}
+	key := queueRateKey(ticketName, kind, time.Now().Unix())
+	pipe := r.Client.TxPipeline()
+	pipe.IncrBy(ctx, key, int64(n))
+	pipe.Expire(ctx, key, queueRateRetention)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetQueueThroughput: 직전 window 동안의 초당 승급/완료 인원 (진행 중인 현재 초는 제외)
+func (r *RedisRepository) GetQueueThroughput(ctx context.Context, ticketName string, window time.Duration) (QueueThroughput, error) {
+	seconds := int64(window / time.Second)
+	if seconds <= 0 {
+		return QueueThroughput{}, nil
+	}
+
+	now := time.Now().Unix()
+	keys := make([]string, 0, 2*seconds)
+	for sec := now - seconds; sec < now; sec++ {
+		keys = append(keys, queueRateKey(ticketName, QueueAdmitted, sec), queueRateKey(ticketName, QueueCompleted, sec))
+	}
+	values, err := r.Client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return QueueThroughput{}, err
+	}
+
+	var admitted, completed int64
+	for i, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		n, _ := strconv.ParseInt(s, 10, 64)
+		if i%2 == 0 {
+			admitted += n
+		} else {
+			completed += n
+		}
+	}
+	return QueueThroughput{
+		Admitted:  float64(admitted) / float64(seconds),
+		Completed: float64(completed) / float64(seconds),
+	}, nil
+}
+
+// removeActiveScript: Active Set에서 실제로 나간 경우에만 완료 인원으로 집계
+var removeActiveScript = redis.NewScript(`
+    if redis.call("ZREM", KEYS[1], ARGV[1]) == 1 then
+        redis.call("INCR", KEYS[2])
+        redis.call("EXPIRE", KEYS[2], ARGV[2])
+        return 1
+    end
+    return 0
+`)
@@ -0,0 +1,343 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+/*
+ * 예약 홀드(결제 대기) Redis 키 구조
+ * - reservation:{holdID}          (Hash) user, ticket, qty, expires_at
+ * - reservations:{ticket}         (ZSet) holdID -> 만료 시각(ms), Reaper가 만료 순으로 회수
+ * - reservation_user:{ticket}     (Hash) userID -> holdID, 유저당 하나의 홀드만 허용
+ * - hold_confirmed:{holdID}       (String) 확정한 유저, 같은 홀드의 중복 확정 요청 판별용 (holdConfirmedTTL 후 만료)
+ */
+
+// holdConfirmedTTL: 확정된 홀드 표시 유지 시간 (홀드 유효 시간 동안 들어온 중복 확정 요청을 모두 판별할 수 있도록 충분히 길게)
+const holdConfirmedTTL = time.Hour
+
+// Reservation: 재고를 선점한 채 구매 확정을 기다리는 홀드
+type Reservation struct {
+	ID         string    `json:"hold_id"`
+	UserID     string    `json:"user_id"`
+	TicketName string    `json:"ticket_name"`
+	Quantity   int       `json:"quantity"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+func reservationKey(holdID string) string {
+	return "reservation:" + holdID
+}
+
+func reservationsKey(ticketName string) string {
+	return "reservations:" + ticketName
+}
+
+func reservationUserKey(ticketName string) string {
+	return "reservation_user:" + ticketName
+}
+
+func holdConfirmedKey(holdID string) string {
+	return "hold_confirmed:" + holdID
+}
+
+// 재고 N 차감 + 홀드 생성을 하나의 원자적 단위로 처리
+// 유저의 누적 구매 수량 + 이번 수량이 이벤트의 1인 한도를 넘지 않을 때만 차감합니다.
+var reserveScript = redis.NewScript(`
+    local stock_key = KEYS[1]
+    local purchased_key = KEYS[2]
+    local user_hold_key = KEYS[3]
+    local holds_key = KEYS[4]
+    local hold_key = KEYS[5]
+    local qty_key = KEYS[6]
+    local user_id = ARGV[1]
+    local hold_id = ARGV[2]
+    local expires_at = ARGV[3]
+    local ticket_name = ARGV[4]
+    local qty = tonumber(ARGV[5])
+    local limit = tonumber(ARGV[6])
+
+    -- 1. 누적 구매 수량 확인 (수량 도입 전 구매자는 1매)
+    local bought = tonumber(redis.call("HGET", qty_key, user_id) or "0")
+    if bought == 0 and redis.call("SISMEMBER", purchased_key, user_id) == 1 then
+        bought = 1
+    end
+    if bought >= limit then
+        return {"ALREADY_PURCHASED", "", 0}
+    end
+    if bought + qty > limit then
+        return {"LIMIT_EXCEEDED", "", limit - bought}
+    end
+
+    -- 2. 아직 유효한 홀드가 있으면 기존 홀드 ID 반환
+    local existing = redis.call("HGET", user_hold_key, user_id)
+    if existing and redis.call("ZSCORE", holds_key, existing) then
+        return {"ALREADY_RESERVED", existing, 0}
+    end
+
+    -- 3. 재고 확인 후 수량만큼 차감
+    local stock = tonumber(redis.call("GET", stock_key) or "0")
+    if stock < qty then
+        return {"SOLD_OUT", "", stock}
+    end
+    local remaining = redis.call("DECRBY", stock_key, qty)
+
+    -- 4. 홀드 생성 (Reaper가 정리하지 못한 경우를 대비해 키 자체에도 넉넉한 TTL 부여)
+    redis.call("HSET", hold_key, "user", user_id, "ticket", ticket_name, "qty", qty, "expires_at", expires_at)
+    redis.call("PEXPIREAT", hold_key, tonumber(expires_at) + 3600000)
+    redis.call("ZADD", holds_key, expires_at, hold_id)
+    redis.call("HSET", user_hold_key, user_id, hold_id)
+
+    return {"RESERVED", hold_id, remaining}
+`)
+
+// ReserveStock: 재고를 quantity만큼 차감하고 ttl 동안 유효한 홀드를 생성합니다.
+// limit은 유저의 누적 구매 한도입니다.
+// status: RESERVED, ALREADY_RESERVED(기존 holdID 반환), ALREADY_PURCHASED(한도 소진),
+// LIMIT_EXCEEDED(남은 한도를 세 번째 값으로 반환), SOLD_OUT(남은 재고를 세 번째 값으로 반환)
+func (r *RedisRepository) ReserveStock(ctx context.Context, ticketName string, userID string, holdID string, quantity int, limit int, ttl time.Duration) (string, string, int, error) {
+	keys := []string{
+		"ticket_stock:" + ticketName,
+		"purchased_users:" + ticketName,
+		reservationUserKey(ticketName),
+		reservationsKey(ticketName),
+		reservationKey(holdID),
+		purchasedQtyKey(ticketName),
+	}
+	expiresAt := time.Now().Add(ttl).UnixMilli()
+
+	result, err := reserveScript.Run(ctx, r.Client, keys, userID, holdID, expiresAt, ticketName, quantity, limit).Slice()
+	if err != nil {
+		return "", "", 0, err
+	}
+	if len(result) < 3 {
+		return "ERROR", "", 0, fmt.Errorf("unexpected lua script result format")
+	}
+
+	status, _ := result[0].(string)
+	id, _ := result[1].(string)
+	remaining, _ := result[2].(int64)
+	return status, id, int(remaining), nil
+}
+
+// GetHold: 유저의 홀드 조회 (없거나 다른 유저의 홀드면 nil)
+func (r *RedisRepository) GetHold(ctx context.Context, ticketName string, holdID string, userID string) (*Reservation, error) {
+	fields, err := r.Client.HGetAll(ctx, reservationKey(holdID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if fields["user"] != userID || fields["ticket"] != ticketName {
+		return nil, nil
+	}
+	return holdFromFields(holdID, ticketName, fields["user"], fields["qty"], fields["expires_at"]), nil
+}
+
+func holdFromFields(holdID string, ticketName string, userID string, qty string, expiresAt string) *Reservation {
+	quantity, err := strconv.Atoi(qty)
+	if err != nil || quantity <= 0 {
+		quantity = 1 // 수량 도입 전 홀드
+	}
+	ms, _ := strconv.ParseInt(expiresAt, 10, 64)
+	return &Reservation{
+		ID:         holdID,
+		UserID:     userID,
+		TicketName: ticketName,
+		Quantity:   quantity,
+		ExpiresAt:  time.UnixMilli(ms),
+	}
+}
+
+// 만료 전의 본인 홀드만 확정하고 구매자 명단에 홀드 수량을 더함 + 구매 이벤트를 Outbox에 기록
+var confirmHoldScript = redis.NewScript(`
+    local holds_key = KEYS[1]
+    local hold_key = KEYS[2]
+    local user_hold_key = KEYS[3]
+    local purchased_key = KEYS[4]
+    local outbox_key = KEYS[5]
+    local qty_key = KEYS[6]
+    local confirmed_key = KEYS[7]
+    local hold_id = ARGV[1]
+    local user_id = ARGV[2]
+    local now = tonumber(ARGV[3])
+
+    local expires_at = redis.call("ZSCORE", holds_key, hold_id)
+    if not expires_at or redis.call("HGET", hold_key, "user") ~= user_id then
+        -- 같은 홀드의 다른 확정 요청이 먼저 성공함 (같은 결제 키로 매입했으므로 환불하면 안 됨)
+        if redis.call("GET", confirmed_key) == user_id then
+            return "ALREADY_CONFIRMED"
+        end
+        return "NOT_FOUND"
+    end
+    if tonumber(expires_at) <= now then
+        -- 재고 반환은 Reaper가 담당
+        return "EXPIRED"
+    end
+
+    local qty = tonumber(redis.call("HGET", hold_key, "qty") or "1")
+    redis.call("ZREM", holds_key, hold_id)
+    redis.call("DEL", hold_key)
+    redis.call("HDEL", user_hold_key, user_id)
+    redis.call("SADD", purchased_key, user_id)
+    redis.call("HINCRBY", qty_key, user_id, qty)
+    redis.call("XADD", outbox_key, "*", "key", ARGV[4], "value", ARGV[5], "headers", ARGV[6])
+    redis.call("SET", confirmed_key, user_id, "PX", ARGV[7])
+    return "CONFIRMED"
+`)
+
+// ConfirmHold: 홀드를 구매로 확정하고 entry를 Outbox에 기록합니다. (CONFIRMED, ALREADY_CONFIRMED, EXPIRED, NOT_FOUND)
+// 이미 같은 유저가 확정한 홀드면 ALREADY_CONFIRMED를 반환하며 아무것도 바꾸지 않습니다.
+func (r *RedisRepository) ConfirmHold(ctx context.Context, ticketName string, holdID string, userID string, entry OutboxEntry) (string, error) {
+	keys := []string{
+		reservationsKey(ticketName),
+		reservationKey(holdID),
+		reservationUserKey(ticketName),
+		"purchased_users:" + ticketName,
+		OutboxStream,
+		purchasedQtyKey(ticketName),
+		holdConfirmedKey(holdID),
+	}
+	args := append([]interface{}{holdID, userID, time.Now().UnixMilli()}, entry.args()...)
+	args = append(args, holdConfirmedTTL.Milliseconds())
+	return confirmHoldScript.Run(ctx, r.Client, keys, args...).Text()
+}
+
+// 홀드 제거 (ZREM에 성공한 한 곳만 재고를 반환하도록 원자적으로 처리, 반환할 수량을 돌려줌)
+var releaseHoldScript = redis.NewScript(`
+    local holds_key = KEYS[1]
+    local hold_key = KEYS[2]
+    local user_hold_key = KEYS[3]
+    local stock_key = KEYS[4]
+    local outbox_key = KEYS[5]
+    local hold_id = ARGV[1]
+    local user_id = ARGV[2]
+
+    if redis.call("HGET", hold_key, "user") ~= user_id then
+        return -1
+    end
+    if redis.call("ZREM", holds_key, hold_id) == 0 then
+        return -1
+    end
+    local qty = tonumber(redis.call("HGET", hold_key, "qty") or "1")
+    redis.call("DEL", hold_key)
+    if redis.call("HGET", user_hold_key, user_id) == hold_id then
+        redis.call("HDEL", user_hold_key, user_id)
+    end
+    local stock = redis.call("INCRBY", stock_key, qty)
+    redis.call("XADD", outbox_key, "*", "key", ARGV[3], "value", ARGV[4], "headers", ARGV[5])
+    return stock
+`)
+
+// ReleaseHold: 유저가 직접 홀드를 취소하고, 같은 스크립트에서 홀드 수량만큼 재고 반환 + 만료 이벤트 Outbox 기록
+// 반환 값은 반환 후 재고이며, 해제할 홀드가 없으면 -1
+func (r *RedisRepository) ReleaseHold(ctx context.Context, ticketName string, holdID string, userID string) (int, error) {
+	keys := []string{
+		reservationsKey(ticketName),
+		reservationKey(holdID),
+		reservationUserKey(ticketName),
+		"ticket_stock:" + ticketName,
+		OutboxStream,
+	}
+	args := append([]interface{}{holdID, userID}, ExpireMessage(userID, ticketName, holdID).args()...)
+	return releaseHoldScript.Run(ctx, r.Client, keys, args...).Int()
+}
+
+// 진행 중인 홀드의 수량 합계
+var countHoldsScript = redis.NewScript(`
+    local total = 0
+    for _, hold_id in ipairs(redis.call("ZRANGE", KEYS[1], 0, -1)) do
+        total = total + tonumber(redis.call("HGET", ARGV[1] .. hold_id, "qty") or "1")
+    end
+    return total
+`)
+
+// CountHolds: 아직 확정/만료되지 않은 홀드의 수량 합계 (재고에서 차감되어 있는 수량)
+func (r *RedisRepository) CountHolds(ctx context.Context, ticketName string) (int, error) {
+	return countHoldsScript.Run(ctx, r.Client, []string{reservationsKey(ticketName)}, reservationKey("")).Int()
+}
+
+// 만료된 홀드 회수: 홀드 수량만큼 재고 반환 + 만료 이벤트 Outbox 기록을 홀드마다 원자적으로 처리
+// ARGV[3..]은 홀드마다 (hold ID, 조회한 유저, Outbox 항목 3개)이며, 그 사이 확정/해제되었거나 유저가 바뀐 홀드는 건너뜀
+// (여러 Reaper가 동시에 돌아도 하나의 홀드는 한 번만 회수됨)
+var claimExpiredScript = redis.NewScript(`
+    local holds_key = KEYS[1]
+    local user_hold_key = KEYS[2]
+    local stock_key = KEYS[3]
+    local outbox_key = KEYS[4]
+    local now = tonumber(ARGV[1])
+    local prefix = ARGV[2]
+
+    local claimed = {}
+    for i = 3, #ARGV, 5 do
+        local hold_id = ARGV[i]
+        local score = redis.call("ZSCORE", holds_key, hold_id)
+        local hold_key = prefix .. hold_id
+        local user_id = redis.call("HGET", hold_key, "user") or ""
+        if score and tonumber(score) <= now and user_id == ARGV[i + 1] then
+            local expires_at = redis.call("HGET", hold_key, "expires_at") or ARGV[1]
+            local qty = redis.call("HGET", hold_key, "qty") or "1"
+            redis.call("ZREM", holds_key, hold_id)
+            redis.call("DEL", hold_key)
+            if user_id ~= "" and redis.call("HGET", user_hold_key, user_id) == hold_id then
+                redis.call("HDEL", user_hold_key, user_id)
+            end
+            -- 수량 도입 전 홀드는 1매
+            local n = tonumber(qty)
+            if not n or n <= 0 then
+                n = 1
+            end
+            redis.call("INCRBY", stock_key, n)
+            redis.call("XADD", outbox_key, "*", "key", ARGV[i + 2], "value", ARGV[i + 3], "headers", ARGV[i + 4])
+            table.insert(claimed, hold_id)
+            table.insert(claimed, user_id)
+            table.insert(claimed, expires_at)
+            table.insert(claimed, qty)
+        end
+    end
+    return claimed
+`)
+
+// ClaimExpiredHolds: now 이전에 만료된 홀드를 최대 limit개 회수하여 재고를 반환하고, 회수한 홀드를 반환합니다.
+// 만료 이벤트 메시지는 codec으로 만들어야 하므로 만료 홀드의 유저를 먼저 읽고, 회수는 스크립트에서 다시 확인합니다.
+func (r *RedisRepository) ClaimExpiredHolds(ctx context.Context, ticketName string, now time.Time, limit int) ([]Reservation, error) {
+	ids, err := r.Client.ZRangeArgs(ctx, redis.ZRangeArgs{
+		Key:     reservationsKey(ticketName),
+		Start:   "-inf",
+		Stop:    now.UnixMilli(),
+		ByScore: true,
+		Count:   int64(limit),
+	}).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	pipe := r.Client.Pipeline()
+	users := make([]*redis.StringCmd, len(ids))
+	for i, id := range ids {
+		users[i] = pipe.HGet(ctx, reservationKey(id), "user")
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	keys := []string{reservationsKey(ticketName), reservationUserKey(ticketName), "ticket_stock:" + ticketName, OutboxStream}
+	args := []interface{}{now.UnixMilli(), reservationKey("")}
+	for i, id := range ids {
+		userID := users[i].Val()
+		args = append(args, id, userID)
+		args = append(args, ExpireMessage(userID, ticketName, id).args()...)
+	}
+	result, err := claimExpiredScript.Run(ctx, r.Client, keys, args...).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	var claimed []Reservation
+	for i := 0; i+3 < len(result); i += 4 {
+		claimed = append(claimed, *holdFromFields(result[i], ticketName, result[i+1], result[i+3], result[i+2]))
+	}
+	return claimed, nil
+}
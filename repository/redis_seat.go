@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+/*
+ * 지정석 Redis 키 구조
+ * - seat_sold:{ticket}          (Hash)   seatID -> 구매 유저
+ * - seat_hold:{ticket}:{seatID} (String) 선점 유저, TTL 만료 시 자동 해제
+ * - user_seats:{ticket}:{user}  (Set)    유저가 보유한 좌석 ID 목록
+ */
+
+func seatSoldKey(ticketName string) string {
+	return "seat_sold:" + ticketName
+}
+
+func seatHoldKey(ticketName string, seatID uint) string {
+	return fmt.Sprintf("seat_hold:%s:%d", ticketName, seatID)
+}
+
+func userSeatsKey(ticketName, userID string) string {
+	return "user_seats:" + ticketName + ":" + userID
+}
+
+func seatArgs(seatIDs []uint) []interface{} {
+	args := make([]interface{}, 0, len(seatIDs))
+	for _, id := range seatIDs {
+		args = append(args, id)
+	}
+	return args
+}
+
+// 여러 좌석을 한 번에 선점 (하나라도 판매/타인 선점 상태면 전체 실패)
+var holdSeatsScript = redis.NewScript(`
+    local sold_key = KEYS[1]
+    local user_id = ARGV[1]
+    local ttl_ms = ARGV[2]
+
+    -- 1. 모든 좌석이 선점 가능한지 먼저 확인 (All-or-Nothing)
+    for i = 2, #KEYS do
+        if redis.call("HEXISTS", sold_key, ARGV[i + 1]) == 1 then
+            return 0
+        end
+        local holder = redis.call("GET", KEYS[i])
+        if holder and holder ~= user_id then
+            return 0
+        end
+    end
+
+    -- 2. 전부 가능할 때만 TTL과 함께 선점
+    for i = 2, #KEYS do
+        redis.call("SET", KEYS[i], user_id, "PX", ttl_ms)
+    end
+    return 1
+`)
+
+func (r *RedisRepository) HoldSeats(ctx context.Context, ticketName string, userID string, seatIDs []uint, ttl time.Duration) (bool, error) {
+	keys := []string{seatSoldKey(ticketName)}
+	for _, id := range seatIDs {
+		keys = append(keys, seatHoldKey(ticketName, id))
+	}
+	args := append([]interface{}{userID, ttl.Milliseconds()}, seatArgs(seatIDs)...)
+
+	held, err := holdSeatsScript.Run(ctx, r.Client, keys, args...).Int()
+	if err != nil {
+		return false, err
+	}
+	return held == 1, nil
+}
+
+// 선점한 좌석을 구매 확정 + 좌석 구매 이벤트를 Outbox에 기록 (본인이 선점한 좌석이 아니면 -1)
+// KEYS[6..]는 좌석별 홀드 키, ARGV[5..]는 같은 순서의 좌석 ID
+var confirmSeatsScript = redis.NewScript(`
+    local sold_key = KEYS[1]
+    local stock_key = KEYS[2]
+    local user_seats_key = KEYS[3]
+    local purchased_key = KEYS[4]
+    local outbox_key = KEYS[5]
+    local user_id = ARGV[1]
+
+    for i = 6, #KEYS do
+        if redis.call("GET", KEYS[i]) ~= user_id then
+            return -1
+        end
+    end
+
+    for i = 6, #KEYS do
+        local seat_id = ARGV[i - 1]
+        redis.call("HSET", sold_key, seat_id, user_id)
+        redis.call("SADD", user_seats_key, seat_id)
+        redis.call("DEL", KEYS[i])
+    end
+    redis.call("SADD", purchased_key, user_id)
+    redis.call("XADD", outbox_key, "*", "key", ARGV[2], "value", ARGV[3], "headers", ARGV[4])
+
+    return redis.call("DECRBY", stock_key, #KEYS - 5)
+`)
+
+func (r *RedisRepository) ConfirmSeats(ctx context.Context, ticketName string, userID string, seatIDs []uint, entry OutboxEntry) (int, error) {
+	keys := []string{
+		seatSoldKey(ticketName),
+		"ticket_stock:" + ticketName,
+		userSeatsKey(ticketName, userID),
+		"purchased_users:" + ticketName,
+		OutboxStream,
+	}
+	for _, id := range seatIDs {
+		keys = append(keys, seatHoldKey(ticketName, id))
+	}
+	args := append([]interface{}{userID}, entry.args()...)
+	args = append(args, seatArgs(seatIDs)...)
+
+	return confirmSeatsScript.Run(ctx, r.Client, keys, args...).Int()
+}
+
+// 본인이 선점한 좌석만 선점 해제
+var releaseSeatsScript = redis.NewScript(`
+    local user_id = ARGV[1]
+    for i = 1, #KEYS do
+        if redis.call("GET", KEYS[i]) == user_id then
+            redis.call("DEL", KEYS[i])
+        end
+    end
+    return 1
+`)
+
+func (r *RedisRepository) ReleaseSeats(ctx context.Context, ticketName string, userID string, seatIDs []uint) error {
+	keys := make([]string, 0, len(seatIDs))
+	for _, id := range seatIDs {
+		keys = append(keys, seatHoldKey(ticketName, id))
+	}
+	return releaseSeatsScript.Run(ctx, r.Client, keys, userID).Err()
+}
+
+// 구매한 좌석 취소: 재고를 복구하고 남은 좌석 수를 반환 (본인 좌석이 아니면 -1)
+// 좌석 취소 이벤트(ARGV[2..4])를 Outbox에 기록하고, 남은 좌석이 없으면 구매자 명단에서 제거한 뒤
+// 구매 취소 이벤트(ARGV[5..7])도 함께 기록합니다. ARGV[8..]은 좌석 ID
+var cancelSeatsScript = redis.NewScript(`
+    local sold_key = KEYS[1]
+    local stock_key = KEYS[2]
+    local user_seats_key = KEYS[3]
+    local purchased_key = KEYS[4]
+    local outbox_key = KEYS[5]
+    local user_id = ARGV[1]
+
+    for i = 8, #ARGV do
+        if redis.call("HGET", sold_key, ARGV[i]) ~= user_id then
+            return -1
+        end
+    end
+
+    for i = 8, #ARGV do
+        redis.call("HDEL", sold_key, ARGV[i])
+        redis.call("SREM", user_seats_key, ARGV[i])
+    end
+    redis.call("INCRBY", stock_key, #ARGV - 7)
+    redis.call("XADD", outbox_key, "*", "key", ARGV[2], "value", ARGV[3], "headers", ARGV[4])
+
+    local remaining = redis.call("SCARD", user_seats_key)
+    if remaining == 0 then
+        redis.call("SREM", purchased_key, user_id)
+        redis.call("XADD", outbox_key, "*", "key", ARGV[5], "value", ARGV[6], "headers", ARGV[7])
+    end
+    return remaining
+`)
+
+func (r *RedisRepository) CancelSeats(ctx context.Context, ticketName string, userID string, seatIDs []uint, seatEntry OutboxEntry, cancelEntry OutboxEntry) (int, error) {
+	keys := []string{
+		seatSoldKey(ticketName),
+		"ticket_stock:" + ticketName,
+		userSeatsKey(ticketName, userID),
+		"purchased_users:" + ticketName,
+		OutboxStream,
+	}
+	args := append([]interface{}{userID}, seatEntry.args()...)
+	args = append(args, cancelEntry.args()...)
+	args = append(args, seatArgs(seatIDs)...)
+
+	return cancelSeatsScript.Run(ctx, r.Client, keys, args...).Int()
+}
+
+// GetUserSeats: 유저가 구매한 좌석 ID 목록 (Reconciler용)
+func (r *RedisRepository) GetUserSeats(ctx context.Context, ticketName string, userID string) ([]uint, error) {
+	members, err := r.Client.SMembers(ctx, userSeatsKey(ticketName, userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	seatIDs := make([]uint, 0, len(members))
+	for _, m := range members {
+		if id, err := strconv.ParseUint(m, 10, 64); err == nil {
+			seatIDs = append(seatIDs, uint(id))
+		}
+	}
+	return seatIDs, nil
+}
+
+// GetSeatStates: 좌석별 실시간 상태(SOLD/HELD) 조회. 결과에 없는 좌석은 AVAILABLE입니다.
+func (r *RedisRepository) GetSeatStates(ctx context.Context, ticketName string, seatIDs []uint) (map[uint]string, error) {
+	states := make(map[uint]string)
+	if len(seatIDs) == 0 {
+		return states, nil
+	}
+
+	sold, err := r.Client.HGetAll(ctx, seatSoldKey(ticketName)).Result()
+	if err != nil {
+		return nil, err
+	}
+	for seat := range sold {
+		id, err := strconv.ParseUint(seat, 10, 64)
+		if err == nil {
+			states[uint(id)] = SeatSold
+		}
+	}
+
+	holdKeys := make([]string, 0, len(seatIDs))
+	for _, id := range seatIDs {
+		holdKeys = append(holdKeys, seatHoldKey(ticketName, id))
+	}
+	holders, err := r.Client.MGet(ctx, holdKeys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, holder := range holders {
+		if holder == nil {
+			continue
+		}
+		if _, ok := states[seatIDs[i]]; !ok {
+			states[seatIDs[i]] = SeatHeld
+		}
+	}
+	return states, nil
+}
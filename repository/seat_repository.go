@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 좌석 상태 (HELD는 Redis에만 존재하는 임시 선점 상태)
+const (
+	SeatAvailable = "AVAILABLE"
+	SeatHeld      = "HELD"
+	SeatSold      = "SOLD"
+)
+
+// Seat 지정석 모델 (구역 > 열 > 번호 구조의 좌석 배치도 한 칸)
+type Seat struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TicketID  uint      `gorm:"column:ticket_id;index;not null" json:"event_id"`
+	Section   string    `gorm:"column:section;not null" json:"section"`
+	Row       string    `gorm:"column:seat_row;not null" json:"row"` // ROW는 MySQL 예약어라 seat_row 사용
+	Number    int       `gorm:"column:number;not null" json:"number"`
+	Status    string    `gorm:"column:status;default:AVAILABLE" json:"status"`
+	UserID    string    `gorm:"column:user_id" json:"-"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"-"`
+}
+
+func (Seat) TableName() string {
+	return "seats"
+}
+
+/*
+ * MySQLSeatRepository
+ * 좌석 배치도와 판매 확정된 좌석 상태를 MySQL에 영속화합니다.
+ * 선점(HOLD)처럼 짧게 유지되는 상태는 Redis(RedisRepository)에서 관리합니다.
+ */
+type MySQLSeatRepository struct {
+	DB *gorm.DB
+}
+
+func NewMySQLSeatRepository(db *gorm.DB) *MySQLSeatRepository {
+	return &MySQLSeatRepository{
+		DB: db,
+	}
+}
+
+// CreateSeats: 좌석 배치도를 일괄 등록
+func (r *MySQLSeatRepository) CreateSeats(seats []Seat) error {
+	return r.DB.CreateInBatches(seats, 500).Error
+}
+
+// ListSeats: 이벤트의 전체 좌석 조회 (구역, 열, 번호 순)
+func (r *MySQLSeatRepository) ListSeats(ticketID uint) ([]Seat, error) {
+	var seats []Seat
+	err := r.DB.Where("ticket_id = ?", ticketID).
+		Order("section, seat_row, number").
+		Find(&seats).Error
+	return seats, err
+}
+
+// CountSeats: 이벤트에 등록된 좌석 수
+func (r *MySQLSeatRepository) CountSeats(ticketID uint) (int, error) {
+	var count int64
+	err := r.DB.Model(&Seat{}).Where("ticket_id = ?", ticketID).Count(&count).Error
+	return int(count), err
+}
+
+// CountSoldSeats: MySQL에 판매 확정(SOLD)으로 반영된 좌석 수
+func (r *MySQLSeatRepository) CountSoldSeats(ticketID uint) (int, error) {
+	var count int64
+	err := r.DB.Model(&Seat{}).Where("ticket_id = ? AND status = ?", ticketID, SeatSold).Count(&count).Error
+	return int(count), err
+}
+
+// SeatsExist: 요청한 좌석이 모두 해당 이벤트의 배치도에 속하는지 확인
+func (r *MySQLSeatRepository) SeatsExist(ticketID uint, seatIDs []uint) (bool, error) {
+	var count int64
+	err := r.DB.Model(&Seat{}).
+		Where("ticket_id = ? AND id IN ?", ticketID, seatIDs).
+		Count(&count).Error
+	return int(count) == len(seatIDs), err
+}
+
+// MarkSeatsSold: 판매 확정 좌석을 SOLD로 변경 (같은 메시지가 다시 와도 결과가 같도록 멱등하게 처리)
+// 새로 SOLD가 된 좌석 수만큼 같은 트랜잭션에서 tickets.stock을 차감합니다.
+func (r *MySQLSeatRepository) MarkSeatsSold(seatIDs []uint, userID string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Seat{}).
+			Where("id IN ? AND status <> ?", seatIDs, SeatSold).
+			Updates(map[string]interface{}{"status": SeatSold, "user_id": userID})
+		if result.Error != nil {
+			return result.Error
+		}
+		return adjustSeatStock(tx, seatIDs, -int(result.RowsAffected))
+	})
+}
+
+// ReleaseSeats: 취소된 좌석을 다시 AVAILABLE로 변경 (해당 유저 소유 좌석만)
+// 실제로 반환된 좌석 수만큼 같은 트랜잭션에서 tickets.stock을 복구합니다.
+func (r *MySQLSeatRepository) ReleaseSeats(seatIDs []uint, userID string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Seat{}).
+			Where("id IN ? AND user_id = ? AND status = ?", seatIDs, userID, SeatSold).
+			Updates(map[string]interface{}{"status": SeatAvailable, "user_id": ""})
+		if result.Error != nil {
+			return result.Error
+		}
+		return adjustSeatStock(tx, seatIDs, int(result.RowsAffected))
+	})
+}
+
+// adjustSeatStock: 좌석이 속한 이벤트의 tickets.stock을 delta만큼 변경
+func adjustSeatStock(tx *gorm.DB, seatIDs []uint, delta int) error {
+	if delta == 0 {
+		return nil
+	}
+	var ticketIDs []uint
+	if err := tx.Model(&Seat{}).Where("id IN ?", seatIDs).Distinct().Pluck("ticket_id", &ticketIDs).Error; err != nil {
+		return err
+	}
+	if len(ticketIDs) != 1 {
+		return fmt.Errorf("좌석 %v이 한 이벤트에 속하지 않습니다", seatIDs)
+	}
+	return tx.Model(&Ticket{}).
+		Where("id = ?", ticketIDs[0]).
+		Update("stock", gorm.Expr("stock + ?", delta)).Error
+}
+
+// DeleteSeats: 이벤트 삭제 시 좌석 배치도 제거
+func (r *MySQLSeatRepository) DeleteSeats(ticketID uint) error {
+	return r.DB.Where("ticket_id = ?", ticketID).Delete(&Seat{}).Error
+}
@@ -0,0 +1,17 @@
+package service
+
+import "ticket-system/repository"
+
+// UserHistory: 유저의 구매 상태 변경 이력 (관리자 조회용, eventID가 0이면 전체 이벤트)
+// 취소된 구매도 감사 로그에는 구매/취소 기록이 모두 남아 있으므로 전체 이력을 확인할 수 있습니다.
+func (s *TicketService) UserHistory(userID string, eventID uint) ([]repository.AuditLog, error) {
+	ticketName := ""
+	if eventID != 0 {
+		event, err := s.TicketRepo.GetTicket(eventID)
+		if err != nil {
+			return nil, err
+		}
+		ticketName = event.Name
+	}
+	return s.TicketRepo.ListAudit(userID, ticketName)
+}
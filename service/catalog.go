@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"ticket-system/metrics"
+	"ticket-system/repository"
+	"time"
+)
+
+// 카탈로그 캐시 유지 시간 (예매 요청마다 MySQL을 조회하지 않도록 메모리에 보관)
+const eventCacheTTL = 3 * time.Second
+
+var ErrInvalidEvent = errors.New("이벤트 정보가 올바르지 않습니다")
+
+// ErrEventBusy: 같은 이벤트의 수정 요청이 동시에 들어옴
+var ErrEventBusy = errors.New("다른 요청이 이벤트를 수정하고 있습니다")
+
+// eventLockTTL: 같은 이벤트의 판매 수량 변경을 직렬화하는 락 유지 시간
+const eventLockTTL = 10 * time.Second
+
+/*
+ * eventCache: 이벤트 카탈로그의 인메모리 스냅샷
+ * 관리자 API로 변경되면 즉시 무효화되고, 다른 서버에서 변경된 내용은 TTL 이후 반영됩니다.
+ */
+type eventCache struct {
+	mu       sync.RWMutex
+	events   map[uint]repository.Ticket
+	loadedAt time.Time
+}
+
+func newEventCache() *eventCache {
+	return &eventCache{events: make(map[uint]repository.Ticket)}
+}
+
+func (c *eventCache) invalidate() {
+	c.mu.Lock()
+	c.loadedAt = time.Time{}
+	c.mu.Unlock()
+}
+
+// snapshot: 캐시가 만료되었으면 MySQL에서 다시 읽어온 뒤 현재 카탈로그를 반환
+func (c *eventCache) snapshot(repo repository.TicketRepository) (map[uint]repository.Ticket, error) {
+	c.mu.RLock()
+	if time.Since(c.loadedAt) < eventCacheTTL {
+		events := c.events
+		c.mu.RUnlock()
+		return events, nil
+	}
+	c.mu.RUnlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(c.loadedAt) < eventCacheTTL {
+		return c.events, nil
+	}
+
+	tickets, err := repo.ListTickets()
+	if err != nil {
+		return nil, err
+	}
+	events := make(map[uint]repository.Ticket, len(tickets))
+	for _, t := range tickets {
+		events[t.ID] = t
+	}
+	c.events = events
+	c.loadedAt = time.Now()
+	return events, nil
+}
+
+// getEvent: 캐시된 카탈로그에서 이벤트 조회
+func (s *TicketService) getEvent(eventID uint) (*repository.Ticket, error) {
+	events, err := s.events.snapshot(s.TicketRepo)
+	if err != nil {
+		return nil, err
+	}
+	ev, ok := events[eventID]
+	if !ok {
+		return nil, repository.ErrTicketNotFound
+	}
+	return &ev, nil
+}
+
+// ListEvents: 전체 이벤트 목록 (ID 순)
+func (s *TicketService) ListEvents() ([]repository.Ticket, error) {
+	events, err := s.events.snapshot(s.TicketRepo)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]repository.Ticket, 0, len(events))
+	for _, ev := range events {
+		list = append(list, ev)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+// GetEvent: 단건 조회 (관리자 API는 캐시를 거치지 않고 DB 원본을 반환)
+func (s *TicketService) GetEvent(eventID uint) (*repository.Ticket, error) {
+	return s.TicketRepo.GetTicket(eventID)
+}
+
+// CreateEvent: 이벤트를 등록하고 Redis 재고를 총 판매 수량으로 초기화
+func (s *TicketService) CreateEvent(ctx context.Context, ev *repository.Ticket) error {
+	if err := validateEvent(ev); err != nil {
+		return err
+	}
+	ev.ID = 0
+	ev.Stock = ev.Capacity
+
+	if err := s.TicketRepo.CreateTicket(ev); err != nil {
+		return err
+	}
+	s.events.invalidate()
+
+	if err := s.LockRepo.InitStock(ctx, ev.Name, ev.Stock); err != nil {
+		return err
+	}
+	metrics.TicketStockLevel.WithLabelValues(ev.Name).Set(float64(ev.Stock))
+	return nil
+}
+
+// UpdateEvent: 이벤트 정보 수정. 총 판매 수량이 바뀌면 남은 재고도 차이만큼 조정합니다.
+// 대기실/판매 시작 시각이 미래로 바뀌면 이전 일정에 열린 대기실을 초기화합니다.
+func (s *TicketService) UpdateEvent(ctx context.Context, ev *repository.Ticket) error {
+	current, err := s.TicketRepo.GetTicket(ev.ID)
+	if err != nil {
+		return err
+	}
+	ev.Name = current.Name
+	ev.Reserved = current.Reserved
+	if current.Reserved {
+		// 지정석 이벤트의 판매 수량은 좌석 배치도가 결정합니다.
+		ev.Capacity = current.Capacity
+	}
+	if err := validateEvent(ev); err != nil {
+		return err
+	}
+
+	delta := ev.Capacity - current.Capacity
+	if delta != 0 {
+		// 판매 수량 변경이 겹치면 delta 계산 기준(current.Capacity)이 어긋나므로 이벤트 단위로 직렬화
+		lockKey := "lock:event:" + current.Name
+		if ok, err := s.LockRepo.Lock(ctx, lockKey, eventLockTTL); err != nil {
+			return err
+		} else if !ok {
+			return ErrEventBusy
+		}
+		defer s.LockRepo.Unlock(ctx, lockKey)
+		if current, err = s.TicketRepo.GetTicket(ev.ID); err != nil {
+			return err
+		}
+		delta = ev.Capacity - current.Capacity
+
+		// 실시간 판매 현황은 Redis 재고가 기준 (MySQL 재고는 워커 반영 전 판매분만큼 늦음)
+		newStock, err := s.LockRepo.ResizeStock(ctx, ev.Name, delta)
+		if err != nil {
+			return err
+		}
+		switch {
+		case newStock == -1:
+			return ErrInvalidEvent
+		case newStock == -2 && current.Stock+delta < 0:
+			// 판매 상태 복원 전에는 MySQL 재고가 기준
+			return ErrInvalidEvent
+		case newStock >= 0:
+			metrics.TicketStockLevel.WithLabelValues(ev.Name).Set(float64(newStock))
+		}
+		if err := s.TicketRepo.UpdateTicket(ev, delta); err != nil {
+			if newStock >= 0 {
+				s.rollbackRedis(ctx, ev.Name, -delta)
+			}
+			return err
+		}
+		s.events.invalidate()
+		return s.resetLobby(ctx, current, ev)
+	}
+
+	if err := s.TicketRepo.UpdateTicket(ev, 0); err != nil {
+		return err
+	}
+	s.events.invalidate()
+	return s.resetLobby(ctx, current, ev)
+}
+
+// DeleteEvent: 이벤트 삭제 및 Redis 판매 상태 정리
+func (s *TicketService) DeleteEvent(ctx context.Context, eventID uint) error {
+	current, err := s.TicketRepo.GetTicket(eventID)
+	if err != nil {
+		return err
+	}
+	if err := s.TicketRepo.DeleteTicket(eventID); err != nil {
+		return err
+	}
+	if current.Reserved {
+		if err := s.SeatRepo.DeleteSeats(eventID); err != nil {
+			return err
+		}
+	}
+	s.events.invalidate()
+
+	metrics.TicketStockLevel.DeleteLabelValues(current.Name)
+	return s.LockRepo.DeleteStock(ctx, current.Name)
+}
+
+func validateEvent(ev *repository.Ticket) error {
+	if ev.Name == "" || ev.Capacity < 0 || ev.Price < 0 || ev.MaxActive < 0 || ev.MaxPerUser < 0 || ev.MaxPerOrder < 0 {
+		return ErrInvalidEvent
+	}
+	if !ev.SaleStartAt.IsZero() && !ev.SaleEndAt.IsZero() && !ev.SaleEndAt.After(ev.SaleStartAt) {
+		return ErrInvalidEvent
+	}
+	// 대기실은 판매 시작 시각에 열리므로 판매 시작 시각보다 먼저여야 함
+	if !ev.LobbyOpenAt.IsZero() && (ev.SaleStartAt.IsZero() || !ev.LobbyOpenAt.Before(ev.SaleStartAt)) {
+		return ErrInvalidEvent
+	}
+	return nil
+}
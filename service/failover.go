@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"ticket-system/metrics"
+	"ticket-system/payment"
+	"ticket-system/repository"
+	"time"
+)
+
+// failoverCheckInterval: 장애 모드에서 Redis 복구 여부를 확인하는 주기
+const failoverCheckInterval = 2 * time.Second
+
+/*
+ * Redis 장애 대비 (MySQL 직접 판매 모드)
+ * - LockRepository를 CircuitBreaker로 감싸 Redis 연속 실패 시 차단기를 엽니다.
+ * - 차단기가 열리면 BuyTicket은 MySQL 행 단위 원자적 차감 + 구매 내역 동기 저장(SellDirect)으로 판매합니다.
+ * - 모니터가 Redis 응답을 확인하면, 장애 중 판매분을 Redis 재고/구매자 명단에 반영(재동기화)한 뒤 차단기를 닫습니다.
+ *   재동기화 대상은 MySQL 감사 로그(api-failover)에 남은 판매 기록이며, Redis의 failover_applied:{ticket}으로 주문마다 한 번만 반영하므로
+ *   판매한 서버가 재시작되거나 다른 서버가 대신 반영해도 판매분을 잃거나 두 번 차감하지 않습니다. (서버 시작 시에도 한 번 수행)
+ * - 차단기는 서버마다 따로 열립니다. 일부 서버만 Redis에 접근하지 못하면 그 서버는 MySQL 재고로, 나머지는 Redis 재고로 판매하며,
+ *   MySQL 재고에는 Outbox/워커가 아직 반영하지 못한 판매분이 빠져 있으므로 그만큼 초과 판매될 수 있습니다.
+ *   재동기화 후 Redis 재고가 음수가 되면 초과 판매로 기록(ticket_failover_oversold_total)하여 운영자가 확인하도록 합니다.
+ */
+type failover struct {
+	breaker *repository.CircuitBreaker
+	direct  repository.LockRepository // 차단기를 거치지 않는 Redis (헬스 체크 이후 재동기화용)
+	ping    func(ctx context.Context) error
+	groupID string
+
+	mu    sync.Mutex
+	dirty bool // 아직 재동기화하지 않은 장애 모드 판매가 있을 수 있음 (서버 시작 시 true)
+}
+
+// EnableFailover: LockRepository를 차단기로 감싸고 Redis 장애 시 MySQL 직접 판매를 활성화
+// groupID는 Redis 데이터가 유실된 경우 WarmStart로 복원할 때 사용하는 Consumer Group입니다.
+func (s *TicketService) EnableFailover(breaker *repository.CircuitBreaker, ping func(ctx context.Context) error, groupID string) {
+	s.failover = &failover{
+		breaker: breaker,
+		direct:  s.LockRepo,
+		ping:    ping,
+		groupID: groupID,
+		dirty:   true, // 이전 프로세스가 장애 중 판매하고 반영하지 못한 주문이 있을 수 있음
+	}
+	s.LockRepo = repository.NewBreakerLockRepository(s.LockRepo, breaker)
+
+	breaker.OnChange(func(open bool) {
+		if open {
+			metrics.RedisCircuitOpen.Set(1)
+			fmt.Println("🚧 [Failover] Redis 장애 감지, MySQL 직접 판매 모드로 전환합니다.")
+			return
+		}
+		metrics.RedisCircuitOpen.Set(0)
+		fmt.Println("✅ [Failover] Redis 재동기화 완료, 정상 모드로 복귀합니다.")
+	})
+}
+
+// degraded: MySQL 직접 판매 모드 여부
+func (s *TicketService) degraded() bool {
+	return s.failover != nil && s.failover.breaker.IsOpen()
+}
+
+// buyDirect: Redis 없이 MySQL만으로 예매 (대기열/홀드 없이 결제 후 재고 차감 + 구매 저장)
+func (s *TicketService) buyDirect(userID string, eventID uint, quantity int) (string, int, string) {
+	ctx := context.Background()
+	quantity = max(quantity, 1)
+
+	event, err := s.getEvent(eventID)
+	if err != nil {
+		return "NOT_FOUND", 0, ""
+	}
+	if !event.IsOnSale(time.Now()) {
+		return "NOT_ON_SALE", 0, ""
+	}
+	if event.Reserved {
+		// 좌석 선점 상태는 Redis에만 있으므로 지정석은 장애 모드에서 판매하지 않음
+		return "SEAT_REQUIRED", 0, ""
+	}
+	if quantity > event.OrderLimit() {
+		return "ORDER_LIMIT_EXCEEDED", 0, ""
+	}
+	ticketName := event.Name
+
+	// 1. 빠른 확인 (최종 판단은 SellDirect 트랜잭션)
+	if bought, err := s.TicketRepo.CountUserTickets(userID, ticketName); err != nil {
+		return "FAIL", 0, ""
+	} else if bought >= event.UserLimit() {
+		return "ALREADY_PURCHASED", 0, ""
+	} else if bought+quantity > event.UserLimit() {
+		return "LIMIT_EXCEEDED", event.UserLimit() - bought, ""
+	}
+	if stock, err := s.TicketRepo.GetStock(ticketName); err != nil || stock < quantity {
+		return "SOLD_OUT", 0, ""
+	}
+	metrics.PurchaseRequests.Inc()
+
+	// 2. 결제 승인 + 매입
+	key := newID()
+	auth, err := s.authorizePayment(ctx, key, userID, ticketName, event.Price*quantity)
+	if err != nil {
+		return "PAYMENT_FAILED", 0, ""
+	}
+	captured, err := s.capturePayment(ctx, key, auth.ID)
+	if err != nil {
+		s.voidPayment(ctx, key, auth)
+		return "PAYMENT_FAILED", 0, ""
+	}
+
+	// 3. 재고 차감 + 구매 내역 저장 + 주문 확정 (한 트랜잭션)
+	status, err := s.TicketRepo.SellDirect(&repository.Purchase{
+		UserID:        userID,
+		TicketName:    ticketName,
+		Quantity:      quantity,
+		OrderID:       key,
+		PaymentID:     captured.ID,
+		PaymentStatus: payment.StatusCaptured,
+		Amount:        captured.Amount,
+		Audit:         repository.AuditSource{Actor: repository.ActorFailover},
+	}, event.UserLimit())
+	if err != nil || status != "SUCCESS" {
+		s.refundPayment(ctx, captured.ID, captured.Amount, key)
+		if err != nil {
+			return "FAIL", 0, ""
+		}
+		return status, 0, ""
+	}
+
+	s.failover.markDirty()
+	metrics.DegradedPurchases.WithLabelValues(ticketName).Inc()
+	remaining, _ := s.TicketRepo.GetStock(ticketName)
+	return "SUCCESS", remaining, key
+}
+
+func (f *failover) markDirty() {
+	f.mu.Lock()
+	f.dirty = true
+	f.mu.Unlock()
+}
+
+// takeDirty: 재동기화가 필요한지 확인하고 표시를 지움 (재동기화 중 새로 판매되면 다시 표시됨)
+func (f *failover) takeDirty() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	dirty := f.dirty
+	f.dirty = false
+	return dirty
+}
+
+// StartFailoverMonitor: 장애 모드에서 Redis 복구를 확인하여 재동기화 후 정상 모드로 복귀
+// 복귀 직전에 진행 중이던 직접 판매가 남아 있으면 다음 주기에 이어서 반영합니다.
+func (s *TicketService) StartFailoverMonitor(ctx context.Context) {
+	if s.failover == nil {
+		return
+	}
+	ticker := time.NewTicker(failoverCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.recoverRedis(ctx); err != nil {
+				fmt.Printf("[Failover 에러] Redis 재동기화 실패: %v\n", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *TicketService) recoverRedis(ctx context.Context) error {
+	f := s.failover
+	open := f.breaker.IsOpen()
+	if !open && !f.takeDirty() {
+		return nil
+	}
+	if err := f.ping(ctx); err != nil {
+		f.markDirty()
+		if open {
+			return nil // 아직 장애 중
+		}
+		return err
+	}
+
+	if err := s.resync(ctx); err != nil {
+		f.markDirty()
+		return err
+	}
+	if open {
+		// Redis 데이터가 유실되었다면 재고 키가 없으므로 MySQL 기준으로 복원
+		if err := s.warmStart(ctx, f.direct, f.groupID); err != nil {
+			f.markDirty()
+			return err
+		}
+		fmt.Printf("♻️ [Failover] %s 동안의 장애 모드 판매분을 Redis에 반영했습니다.\n", time.Since(f.breaker.OpenedAt()).Round(time.Second))
+		f.breaker.Close()
+		// 차단기를 닫는 사이 진행 중이던 직접 판매는 다음 주기에 반영
+		f.markDirty()
+	}
+	return nil
+}
+
+// resync: MySQL에 남은 장애 모드 판매분을 Redis 구매자 명단/수량과 재고에 반영
+// 주문마다 한 번만 반영되므로(ApplyDirectSale) 여러 번, 여러 서버에서 수행해도 됩니다.
+// 재고 키가 없는(데이터 유실) 이벤트는 WarmStart가 MySQL(직접 판매분 포함) 기준으로 복원합니다.
+func (s *TicketService) resync(ctx context.Context) error {
+	f := s.failover
+	events, err := s.TicketRepo.ListTickets()
+	if err != nil {
+		return err
+	}
+	for _, ev := range events {
+		if ev.Reserved {
+			continue // 지정석은 장애 모드에서 판매하지 않음
+		}
+		sales, err := s.TicketRepo.ListFailoverSales(ev.Name)
+		if err != nil {
+			return err
+		}
+		for _, sale := range sales {
+			applied, remaining, err := f.direct.ApplyDirectSale(ctx, ev.Name, sale.OrderID, sale.UserID, sale.Quantity, sale.DeletedAt.Valid)
+			if err != nil {
+				return err
+			}
+			if !applied {
+				continue
+			}
+			metrics.TicketStockLevel.WithLabelValues(ev.Name).Set(float64(remaining))
+			if remaining < 0 {
+				metrics.FailoverOversold.WithLabelValues(ev.Name).Add(float64(min(sale.Quantity, -remaining)))
+				fmt.Printf("🚨 [Failover] %s 장애 모드 판매분 반영 후 재고가 %d입니다. (주문 %s, 초과 판매 확인 필요)\n", ev.Name, remaining, sale.OrderID)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"math/big"
+	"ticket-system/repository"
+	"time"
+)
+
+/*
+ * 판매 전 대기실 (Lobby)
+ * - 이벤트에 lobby_open_at이 설정되어 있으면 그 시각부터 판매 시작 전까지 도착한 유저는 대기실에 모입니다.
+ * - 판매가 시작되면 Promoter가 대기실 유저를 암호학적 난수(crypto/rand)로 섞어 대기열 맨 앞에 배정합니다.
+ *   (도착 시각 경쟁이 없으므로 지연 시간이 짧은 봇이 유리하지 않음)
+ * - 판매 시작 후 도착한 유저는 기존처럼 도착 순서대로 대기실 유저 뒤에 줄을 섭니다.
+ * - 대기실은 한 번만 열리며, 열린 뒤에는 (서버 간 시각 차이로 아직 판매 전으로 보여도) 입장하지 않고 대기열로 보냅니다.
+ */
+
+// lobbyOpenAttempts: 대기실 목록을 읽는 사이 유저가 들어와 배정이 거절될 때 다시 섞어서 시도하는 횟수 (넘으면 다음 주기에 재시도)
+const lobbyOpenAttempts = 3
+
+// joinLobby: 판매 전 대기실 입장 (status LOBBY, 대기실 인원)
+// 대기실이 이미 열렸으면 빈 status를 반환하며, 호출자는 판매가 시작된 것으로 보고 대기열 진입을 진행합니다.
+func (s *TicketService) joinLobby(ctx context.Context, event *repository.Ticket, userID string) (string, int) {
+	size, err := s.LockRepo.JoinLobby(ctx, event.Name, userID)
+	if err != nil {
+		return "FAIL", 0
+	}
+	if size < 0 {
+		return "", 0
+	}
+	return "LOBBY", size
+}
+
+// LobbyStatus: 대기실 입장 응답에 담을 상태와 대기열 토큰 (같은 유저/이벤트의 토큰이 있으면 그대로 사용)
+// 대기실에서는 순번이 없으므로 토큰의 진입 순번은 0이며, 판매 시작 후 대기열에 배정되면 순번이 정해집니다.
+func (s *TicketService) LobbyStatus(userID string, eventID uint, token string) (*QueueStatus, string) {
+	claims, err := s.parseQueueToken(token)
+	if err != nil || claims.UserID != userID || claims.EventID != eventID {
+		token = s.IssueQueueToken(userID, eventID, 0)
+	}
+
+	status := &QueueStatus{EventID: eventID, State: "LOBBY"}
+	if event, err := s.getEvent(eventID); err == nil {
+		status.OpensAt = &event.SaleStartAt
+	}
+	return status, token
+}
+
+// openLobby: 판매가 시작된 이벤트의 대기실 유저 전체를 무작위 순서로 대기열에 배정하고, 대기실이 열렸는지 반환
+// 여러 서버의 Promoter가 동시에 시도해도 대기실은 한 번만 열립니다. (빈 대기실도 열린 것으로 표시)
+func (s *TicketService) openLobby(ctx context.Context, ev *repository.Ticket) bool {
+	count := -1
+	for attempt := 0; attempt < lobbyOpenAttempts && count == -1; attempt++ {
+		users, err := s.LockRepo.GetLobbyMembers(ctx, ev.Name)
+		if err != nil {
+			fmt.Printf("[Promoter 에러] %s 대기실 조회 중 오류: %v\n", ev.Name, err)
+			return false
+		}
+		if err := shuffleUsers(users); err != nil {
+			fmt.Printf("[Promoter 에러] %s 대기실 순서 생성 중 오류: %v\n", ev.Name, err)
+			return false
+		}
+		if count, err = s.LockRepo.OpenLobby(ctx, ev.Name, users); err != nil {
+			fmt.Printf("[Promoter 에러] %s 대기실 배정 중 오류: %v\n", ev.Name, err)
+			return false
+		}
+	}
+	switch {
+	case count == -1:
+		return false // 계속 유저가 들어오는 중 (다음 주기에 재시도)
+	case count == -2:
+		return true // 다른 서버가 먼저 배정함
+	case count == 0:
+		return true
+	}
+	fmt.Printf("[Promoter] %s 대기실 %d명을 무작위 순서로 대기열에 배정했습니다.\n", ev.Name, count)
+
+	// 대기실에서 기다리던 푸시 연결이 배정된 순번을 받도록 알림
+	update := repository.QueueUpdate{TicketName: ev.Name, LobbyOpened: true}
+	if err := s.LockRepo.PublishQueueUpdate(ctx, update); err != nil {
+		fmt.Printf("[Promoter 에러] %s 대기실 배정 알림 발행 중 오류: %v\n", ev.Name, err)
+	}
+	return true
+}
+
+// shuffleUsers: crypto/rand 기반 Fisher-Yates 셔플 (순서를 예측할 수 없도록 math/rand를 사용하지 않음)
+func shuffleUsers(users []string) error {
+	for i := len(users) - 1; i > 0; i-- {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return err
+		}
+		j := int(n.Int64())
+		users[i], users[j] = users[j], users[i]
+	}
+	return nil
+}
+
+// lobbyPending: 대기실이 설정된 이벤트의 판매 시작 이후인지 (Promoter가 대기실을 열어야 하는지)
+func lobbyPending(ev *repository.Ticket, now time.Time) bool {
+	return !ev.LobbyOpenAt.IsZero() && !ev.SaleStartAt.IsZero() && !now.Before(ev.SaleStartAt)
+}
+
+// lobbyRescheduled: 대기실/판매 시작 시각이 바뀌어 새 판매 시작 전에 대기실을 다시 열어야 하는지
+// 새 판매 시작 시각이 이미 지났으면 대기실에 들어올 유저가 없으므로 초기화하지 않습니다.
+func lobbyRescheduled(current, next *repository.Ticket, now time.Time) bool {
+	if current.LobbyOpenAt.Equal(next.LobbyOpenAt) && current.SaleStartAt.Equal(next.SaleStartAt) {
+		return false
+	}
+	return !next.SaleStartAt.IsZero() && now.Before(next.SaleStartAt)
+}
+
+// resetLobby: 판매 일정이 바뀌었으면 이미 열린 대기실을 초기화 (이전 일정에 열린 표시 때문에 입장이 막히지 않도록)
+func (s *TicketService) resetLobby(ctx context.Context, current, next *repository.Ticket) error {
+	if !lobbyRescheduled(current, next, time.Now()) {
+		return nil
+	}
+	reset, err := s.LockRepo.ResetLobby(ctx, current.Name)
+	if err != nil {
+		return fmt.Errorf("대기실 초기화 실패: %w", err)
+	}
+	if reset {
+		log.Printf("🔄 [대기실 초기화] %s 판매 일정이 변경되어 대기실을 다시 엽니다. (판매 시작 %s)", current.Name, next.SaleStartAt.Format(time.RFC3339))
+	}
+	return nil
+}
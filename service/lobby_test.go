@@ -0,0 +1,97 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"ticket-system/repository"
+	"time"
+)
+
+func TestShuffleUsersKeepsMembers(t *testing.T) {
+	users := make([]string, 50)
+	for i := range users {
+		users[i] = fmt.Sprintf("user_%d", i)
+	}
+	original := slices.Clone(users)
+
+	if err := shuffleUsers(users); err != nil {
+		t.Fatalf("shuffleUsers: %v", err)
+	}
+	got := slices.Clone(users)
+	slices.Sort(got)
+	want := slices.Clone(original)
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Fatalf("셔플 후 구성원이 바뀜: %v", users)
+	}
+}
+
+func TestShuffleUsersChangesOrder(t *testing.T) {
+	users := make([]string, 50)
+	for i := range users {
+		users[i] = fmt.Sprintf("user_%d", i)
+	}
+	original := slices.Clone(users)
+
+	// 50명이 원래 순서 그대로 나올 확률은 1/50!이므로 여러 번 모두 같으면 셔플되지 않은 것
+	for i := 0; i < 5; i++ {
+		if err := shuffleUsers(users); err != nil {
+			t.Fatalf("shuffleUsers: %v", err)
+		}
+		if !slices.Equal(users, original) {
+			return
+		}
+	}
+	t.Fatal("셔플해도 순서가 바뀌지 않음")
+}
+
+func TestShuffleUsersMovesEveryPosition(t *testing.T) {
+	// 모든 유저가 맨 앞 자리에 올 수 있어야 함 (마지막 원소를 빼먹는 등 범위 오류 검출)
+	seen := make(map[string]bool)
+	for i := 0; i < 500 && len(seen) < 4; i++ {
+		users := []string{"a", "b", "c", "d"}
+		if err := shuffleUsers(users); err != nil {
+			t.Fatalf("shuffleUsers: %v", err)
+		}
+		seen[users[0]] = true
+	}
+	if len(seen) != 4 {
+		t.Fatalf("맨 앞 자리에 온 유저 = %v, want a, b, c, d 모두", seen)
+	}
+}
+
+func TestShuffleUsersSmallInputs(t *testing.T) {
+	for _, users := range [][]string{nil, {}, {"only"}} {
+		before := slices.Clone(users)
+		if err := shuffleUsers(users); err != nil {
+			t.Fatalf("shuffleUsers(%v): %v", users, err)
+		}
+		if !slices.Equal(users, before) {
+			t.Errorf("shuffleUsers(%v) = %v", before, users)
+		}
+	}
+}
+
+func TestLobbyRescheduled(t *testing.T) {
+	now := time.Date(2027, 3, 1, 21, 0, 0, 0, time.UTC)
+	sale := now.Add(-time.Hour) // 이미 대기실이 열린 이전 일정
+	current := &repository.Ticket{LobbyOpenAt: sale.Add(-30 * time.Minute), SaleStartAt: sale}
+
+	tests := []struct {
+		name string
+		next repository.Ticket
+		want bool
+	}{
+		{"일정 변경 없음", *current, false},
+		{"판매 연기", repository.Ticket{LobbyOpenAt: now.Add(time.Hour), SaleStartAt: now.Add(2 * time.Hour)}, true},
+		{"대기실 시각만 변경", repository.Ticket{LobbyOpenAt: sale.Add(-time.Hour), SaleStartAt: sale}, false},
+		{"대기실 없이 판매만 연기", repository.Ticket{SaleStartAt: now.Add(time.Hour)}, true},
+		{"판매 시작 시각 삭제", repository.Ticket{}, false},
+	}
+	for _, tt := range tests {
+		if got := lobbyRescheduled(current, &tt.next, now); got != tt.want {
+			t.Errorf("%s: lobbyRescheduled = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ticket-system/metrics"
+	"ticket-system/repository"
+	"time"
+)
+
+// orderLockTTL: 같은 주문에 대한 취소/양도 요청을 직렬화하는 락 유지 시간
+const orderLockTTL = 10 * time.Second
+
+/*
+ * 주문 (Order → 주문 항목)
+ * - 주문은 결제 1건 단위이며 항목은 티켓 1매(지정석은 좌석 1개) 단위입니다.
+ * - 취소는 주문 전체 또는 항목 단위로 가능하며, 취소된 주문/항목은 삭제하지 않고 REFUNDED로 남습니다.
+ * - 주문 상태는 워커가 MySQL에 반영한 결과이므로, 확정 직후에는 잠시 PENDING으로 보일 수 있습니다.
+ */
+
+// GetOrder: 유저 본인의 주문 조회
+func (s *TicketService) GetOrder(userID string, orderID string) (*repository.Order, error) {
+	order, err := s.TicketRepo.GetOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, repository.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+// ListOrders: 유저의 주문 이력 (eventID가 0이면 전체 이벤트)
+func (s *TicketService) ListOrders(userID string, eventID uint) ([]repository.Order, error) {
+	ticketName := ""
+	if eventID != 0 {
+		event, err := s.getEvent(eventID)
+		if err != nil {
+			return nil, err
+		}
+		ticketName = event.Name
+	}
+	return s.TicketRepo.ListOrders(userID, ticketName)
+}
+
+// CancelOrder: 주문 전체(itemIDs가 비어 있으면) 또는 일부 항목 취소
+// 지정석 주문은 항목의 좌석으로 좌석 취소(CancelSeats)를 수행합니다.
+func (s *TicketService) CancelOrder(userID string, orderID string, itemIDs []uint) (bool, string) {
+	ctx := context.Background()
+
+	if s.degraded() {
+		return false, "시스템 점검 중으로 취소할 수 없습니다. 잠시 후 다시 시도해주세요."
+	}
+
+	order, err := s.GetOrder(userID, orderID)
+	if errors.Is(err, repository.ErrOrderNotFound) {
+		return false, "주문을 찾을 수 없습니다."
+	} else if err != nil {
+		return false, "주문 조회 중 오류가 발생했습니다."
+	}
+	switch order.Status {
+	case repository.OrderPending:
+		return false, "결제 정보를 확인 중입니다. 잠시 후 다시 시도해주세요."
+	case repository.OrderCancelled, repository.OrderRefunded:
+		return false, "이미 취소된 주문입니다."
+	}
+
+	items, ok := cancellableItems(order, itemIDs)
+	if !ok {
+		return false, "취소할 수 없는 주문 항목입니다."
+	}
+
+	event, err := s.eventByName(order.TicketName)
+	if err != nil {
+		return false, "존재하지 않는 이벤트입니다."
+	}
+	if event.Reserved {
+		seatIDs := make([]uint, len(items))
+		for i, item := range items {
+			seatIDs[i] = item.SeatID
+		}
+		return s.CancelSeats(userID, event.ID, seatIDs)
+	}
+
+	// 같은 주문의 취소 요청이 동시에 들어와도 항목이 두 번 환불되지 않도록 직렬화
+	lockKey := "lock:order:" + orderID
+	if ok, err := s.LockRepo.Lock(ctx, lockKey, orderLockTTL); err != nil || !ok {
+		return false, "다른 요청을 처리 중입니다. 잠시 후 다시 시도해주세요."
+	}
+	defer s.LockRepo.Unlock(ctx, lockKey)
+
+	ids := make([]uint, len(items))
+	refund := 0
+	for i, item := range items {
+		ids[i] = item.ID
+		refund += item.Price
+	}
+
+	// 1. 구매 수량 차감 + 재고 복구 + 취소 이벤트 Outbox 기록 (Lua Script)
+	// 티켓을 먼저 회수해야 양도/전체 취소와 겹쳐 회수에 실패했을 때 이미 환불된 상태가 되지 않음 (CancelTicket과 같은 순서)
+	// 이미 취소된 항목이 있거나 주문 소유자가 바뀌었으면(양도) 거절합니다.
+	newStock, err := s.LockRepo.CancelOrderItems(ctx, order.TicketName, userID, orderID, ids, repository.CancelItemsMessage(userID, order.TicketName, orderID, ids))
+	if err != nil {
+		return false, "재고 복구 중 오류가 발생했습니다."
+	}
+	if newStock < 0 {
+		return false, "이미 취소되었거나 취소할 수 없는 주문 항목입니다."
+	}
+	metrics.TicketStockLevel.WithLabelValues(order.TicketName).Set(float64(newStock))
+
+	// 2. 취소 항목 금액만큼 부분 환불 (환불 키에 항목 목록을 포함하여 같은 취소는 한 번만 환불)
+	// 실패하면 감사 로그에 REFUND_FAILED로 남겨 운영자가 같은 환불 키로 재처리하도록 함
+	refundKey := orderID + ":" + repository.JoinSeatIDs(ids)
+	if err := s.refundPayment(ctx, order.PaymentID, refund, refundKey); err != nil {
+		s.recordRefundFailure(&repository.Purchase{
+			UserID:     userID,
+			TicketName: order.TicketName,
+			Quantity:   len(ids),
+			OrderID:    orderID,
+			PaymentID:  order.PaymentID,
+			Amount:     refund,
+		}, fmt.Errorf("환불 키 %s: %w", refundKey, err))
+		return true, "취소되었지만 환불 처리가 지연되고 있습니다. 확인 후 환불해드리겠습니다."
+	}
+	return true, "취소 요청이 접수되었습니다."
+}
+
+// TransferOrder: 주문의 남은 티켓 전체를 다른 유저에게 양도 (재고를 풀지 않으므로 대기열과 무관)
+// 받는 유저의 1인 누적 한도를 넘으면 거절하며, 지정석 주문은 좌석 선점 정보가 유저 단위라 양도할 수 없습니다.
+// 양도된 티켓을 취소하면 원 결제 건으로 환불됩니다.
+func (s *TicketService) TransferOrder(fromUserID string, toUserID string, orderID string) (bool, string) {
+	ctx := context.Background()
+
+	if s.degraded() {
+		return false, "시스템 점검 중으로 양도할 수 없습니다. 잠시 후 다시 시도해주세요."
+	}
+	if toUserID == "" || toUserID == fromUserID {
+		return false, "받는 사람이 올바르지 않습니다."
+	}
+
+	order, err := s.GetOrder(fromUserID, orderID)
+	if errors.Is(err, repository.ErrOrderNotFound) {
+		return false, "주문을 찾을 수 없습니다."
+	} else if err != nil {
+		return false, "주문 조회 중 오류가 발생했습니다."
+	}
+	switch order.Status {
+	case repository.OrderPending:
+		return false, "결제 정보를 확인 중입니다. 잠시 후 다시 시도해주세요."
+	case repository.OrderCancelled, repository.OrderRefunded:
+		return false, "이미 취소된 주문입니다."
+	}
+
+	event, err := s.eventByName(order.TicketName)
+	if err != nil {
+		return false, "존재하지 않는 이벤트입니다."
+	}
+	if event.Reserved {
+		return false, "지정석 티켓은 양도할 수 없습니다."
+	}
+
+	items, ok := cancellableItems(order, nil)
+	if !ok {
+		return false, "이미 취소된 주문입니다."
+	}
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+
+	// 같은 주문의 취소/양도 요청과 직렬화
+	lockKey := "lock:order:" + orderID
+	if ok, err := s.LockRepo.Lock(ctx, lockKey, orderLockTTL); err != nil || !ok {
+		return false, "다른 요청을 처리 중입니다. 잠시 후 다시 시도해주세요."
+	}
+	defer s.LockRepo.Unlock(ctx, lockKey)
+
+	// 구매자 명단/수량 이동 + 양도 이벤트 Outbox 기록 (받는 유저 한도 확인 포함)
+	entry := repository.TransferMessage(fromUserID, toUserID, order.TicketName, orderID, len(ids))
+	result, err := s.LockRepo.TransferOrder(ctx, order.TicketName, fromUserID, toUserID, orderID, ids, event.UserLimit(), entry)
+	if err != nil {
+		return false, "양도 처리 중 오류가 발생했습니다."
+	}
+	switch result {
+	case -1:
+		return false, "이미 양도되었거나 취소된 주문입니다."
+	case -2:
+		return false, "받는 사람의 구매 한도를 초과합니다."
+	}
+	return true, "양도 요청이 접수되었습니다."
+}
+
+// cancellableItems: 취소 대상 항목 (itemIDs가 비어 있으면 확정된 전체 항목, 하나라도 취소할 수 없으면 false)
+func cancellableItems(order *repository.Order, itemIDs []uint) ([]repository.OrderItem, bool) {
+	var items []repository.OrderItem
+	if len(itemIDs) == 0 {
+		for _, item := range order.Items {
+			if item.Status == repository.OrderConfirmed {
+				items = append(items, item)
+			}
+		}
+		return items, len(items) > 0
+	}
+
+	byID := make(map[uint]repository.OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		byID[item.ID] = item
+	}
+	seen := make(map[uint]bool, len(itemIDs))
+	for _, id := range itemIDs {
+		item, ok := byID[id]
+		if !ok || seen[id] || item.Status != repository.OrderConfirmed {
+			return nil, false
+		}
+		seen[id] = true
+		items = append(items, item)
+	}
+	return items, true
+}
+
+// eventByName: 주문에 기록된 공연 이름으로 이벤트 조회
+func (s *TicketService) eventByName(name string) (*repository.Ticket, error) {
+	events, err := s.ListEvents()
+	if err != nil {
+		return nil, err
+	}
+	for i := range events {
+		if events[i].Name == name {
+			return &events[i], nil
+		}
+	}
+	return nil, repository.ErrTicketNotFound
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ticket-system/payment"
+	"ticket-system/repository"
+)
+
+// ErrPaymentPending: 구매 내역이 아직 MySQL에 반영되지 않아 환불 대상을 확인할 수 없음
+var ErrPaymentPending = errors.New("결제 정보를 확인 중입니다")
+
+/*
+ * 결제 흐름: 재고 홀드 → Authorize(승인) → Capture(매입) → Redis 확정 + Outbox 기록
+ * Redis 확정에 실패하면(홀드 만료 등) 매입 건을 환불합니다. Kafka 발행은 OutboxRelay가 담당합니다.
+ * 모든 PG 요청의 멱등성 키는 홀드 ID(또는 결제 ID)에서 파생하여,
+ * 같은 요청이 재시도되어도 PG에서는 한 번만 처리되도록 합니다.
+ */
+
+// authorizePayment: 결제 승인 (한도만 확보하고 실제 매입은 확정 이후 진행)
+func (s *TicketService) authorizePayment(ctx context.Context, key string, userID string, ticketName string, amount int) (*payment.Payment, error) {
+	return s.Payments.Authorize(ctx, payment.AuthorizeRequest{
+		IdempotencyKey: "auth:" + key,
+		UserID:         userID,
+		TicketName:     ticketName,
+		Amount:         amount,
+	})
+}
+
+// capturePayment: 승인된 결제 건을 매입
+func (s *TicketService) capturePayment(ctx context.Context, key string, paymentID string) (*payment.Payment, error) {
+	return s.Payments.Capture(ctx, paymentID, "capture:"+key)
+}
+
+// voidPayment: 매입에 실패한 승인 건을 취소 (매입 전이므로 환불이 아닌 승인 취소로 처리됨)
+func (s *TicketService) voidPayment(ctx context.Context, key string, auth *payment.Payment) {
+	if _, err := s.Payments.Refund(ctx, auth.ID, auth.Amount, "void:"+key); err != nil {
+		fmt.Printf("[승인 취소 실패] 결제 %s: %v\n", auth.ID, err)
+	}
+}
+
+// refundPayment: 매입된 결제 건의 amount만큼 환불 (무료 공연처럼 결제 금액이 0이면 생략)
+func (s *TicketService) refundPayment(ctx context.Context, paymentID string, amount int, key string) error {
+	if paymentID == "" || amount <= 0 {
+		return nil
+	}
+	if _, err := s.Payments.Refund(ctx, paymentID, amount, "refund:"+key); err != nil {
+		fmt.Printf("[환불 실패] 결제 %s 금액 %d: %v\n", paymentID, amount, err)
+		return err
+	}
+	return nil
+}
+
+// purchasePayments: 취소 요청 시 MySQL 구매 내역에서 환불할 주문별 결제 정보를 조회
+// MySQL 구매 수량이 Redis에 기록된 수량과 같아야(워커가 구매/양도/부분 취소를 모두 반영해야) 전체 주문을 환불할 수 있습니다.
+// quantity가 0이면 수량을 비교하지 않습니다. (지정석)
+func (s *TicketService) purchasePayments(userID string, ticketName string, quantity int) ([]repository.Purchase, error) {
+	purchases, err := s.TicketRepo.ListUserPurchases(userID, ticketName)
+	if errors.Is(err, repository.ErrPurchaseNotFound) {
+		// Redis에는 구매자로 있지만 워커가 아직 저장하지 못한 경우
+		return nil, ErrPaymentPending
+	}
+	if err != nil {
+		return nil, err
+	}
+	saved := 0
+	for _, p := range purchases {
+		saved += p.Quantity
+	}
+	if quantity > 0 && saved != quantity {
+		// 일부 주문만 저장되었거나, 양도/부분 취소가 아직 반영되지 않음
+		return nil, ErrPaymentPending
+	}
+	return purchases, nil
+}
@@ -3,32 +3,76 @@ package service
 import (
 	"context"
 	"fmt"
+	"ticket-system/metrics"
+	"ticket-system/repository"
 	"time"
 )
 
-// StartPromoter는 백그라운드에서 주기적으로 대기열의 유저를 Active Set으로 이동시킵니다.
-func (s *TicketService) StartPromoter(ctx context.Context, maxActive int) {
+// StartPromoter는 백그라운드에서 주기적으로 판매 중인 모든 이벤트의 대기열 유저를 Active Set으로 이동시킵니다.
+// 이벤트별 동시 예매 허용 인원(maxActive)은 카탈로그의 max_active 설정을 따릅니다.
+func (s *TicketService) StartPromoter(ctx context.Context) {
 	ticker := time.NewTicker(100 * time.Millisecond) // 0.1초 주기로 실행
 	defer ticker.Stop()
 
-	fmt.Println("Promoter 워커가 가동되었습니다. (대상: ticket:active_set)")
+	fmt.Println("Promoter 워커가 가동되었습니다. (대상: 판매 중인 이벤트별 ticket:active_sessions)")
 
+	// 대기실을 연(또는 다른 서버가 연 것을 확인한) 이벤트는 다시 대기실을 조회하지 않음
+	openedLobbies := make(map[string]bool)
 	for {
 		select {
 		case <-ticker.C:
-			// Repository에 추가한 PromoteUsers 호출
-			count, err := s.LockRepo.PromoteUsers(ctx, maxActive)
-			if err != nil {
-				fmt.Printf("[Promoter 에러] 유저 승급 중 오류: %v\n", err)
-				continue
-			}
-
-			if count > 0 {
-				fmt.Printf("[Promoter] 대기열에서 %d명을 Active Set으로 승급시켰습니다.\n", count)
-			}
+			s.promoteOnSaleEvents(ctx, openedLobbies)
 		case <-ctx.Done():
 			fmt.Println("Promoter 워커를 종료합니다.")
 			return
 		}
 	}
 }
+
+// promoteOnSaleEvents: 판매 기간 중인 이벤트마다 빈 자리만큼 대기열 유저를 승급
+func (s *TicketService) promoteOnSaleEvents(ctx context.Context, openedLobbies map[string]bool) {
+	if s.degraded() {
+		return // Redis 장애 중에는 대기열을 사용하지 않음
+	}
+	events, err := s.ListEvents()
+	if err != nil {
+		fmt.Printf("[Promoter 에러] 이벤트 목록 조회 중 오류: %v\n", err)
+		return
+	}
+
+	now := time.Now()
+	for _, ev := range events {
+		if !ev.IsOnSale(now) {
+			continue
+		}
+		if lobbyPending(&ev, now) && !openedLobbies[ev.Name] {
+			// 판매 시작 전 대기실에 모인 유저를 먼저 무작위 순서로 대기열에 배정
+			openedLobbies[ev.Name] = s.openLobby(ctx, &ev)
+		}
+
+		// Repository에 추가한 PromoteUsers 호출 (만료 세션 정리 후 승급, 승급된 유저는 admissionGrace 안에 예매해야 함)
+		admittedUntil := time.Now().Add(admissionGrace)
+		users, evicted, err := s.LockRepo.PromoteUsers(ctx, ev.Name, ev.ActiveLimit(), admissionGrace)
+		if err != nil {
+			fmt.Printf("[Promoter 에러] %s 유저 승급 중 오류: %v\n", ev.Name, err)
+			continue
+		}
+		if evicted > 0 {
+			metrics.QueueSessionsEvicted.WithLabelValues(ev.Name).Add(float64(evicted))
+			fmt.Printf("[Promoter] %s 만료된 세션 %d개를 Active Set에서 정리했습니다.\n", ev.Name, evicted)
+		}
+		if len(users) == 0 {
+			continue
+		}
+		fmt.Printf("[Promoter] %s 대기열에서 %d명을 Active Set으로 승급시켰습니다.\n", ev.Name, len(users))
+		if err := s.LockRepo.RecordQueueThroughput(ctx, ev.Name, repository.QueueAdmitted, len(users)); err != nil {
+			fmt.Printf("[Promoter 에러] %s 승급 처리량 기록 중 오류: %v\n", ev.Name, err)
+		}
+
+		// 모든 API 서버의 푸시 연결로 입장 알림 + 남은 대기자 순번 갱신 전파
+		update := repository.QueueUpdate{TicketName: ev.Name, Admitted: users, AdmittedUntil: admittedUntil.UnixMilli()}
+		if err := s.LockRepo.PublishQueueUpdate(ctx, update); err != nil {
+			fmt.Printf("[Promoter 에러] %s 승급 알림 발행 중 오류: %v\n", ev.Name, err)
+		}
+	}
+}
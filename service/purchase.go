@@ -2,90 +2,150 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"ticket-system/metrics"
+	"ticket-system/payment"
 	"ticket-system/repository"
 )
 
 type TicketService struct {
 	LockRepo   repository.LockRepository
 	TicketRepo repository.TicketRepository
+	SeatRepo   repository.SeatRepository
 	KafkaRepo  *repository.KafkaRepository
+	Outbox     repository.OutboxRepository
+	Payments   payment.PaymentGateway
+
+	events      *eventCache
+	failover    *failover // Redis 장애 시 MySQL 직접 판매 (EnableFailover로 활성화)
+	queueSecret []byte    // 대기열 토큰 서명 키 (SetQueueSecret으로 변경)
+	queueHub    *queueHub // 이 서버에 연결된 대기열 푸시 구독자 (StartQueueHub로 Redis 알림 수신)
+	throughput  *throughputCache
 }
 
-func NewTicketService(lr repository.LockRepository, tr repository.TicketRepository, kr *repository.KafkaRepository) *TicketService {
-	return &TicketService{LockRepo: lr, TicketRepo: tr, KafkaRepo: kr}
+func NewTicketService(lr repository.LockRepository, tr repository.TicketRepository, sr repository.SeatRepository, kr *repository.KafkaRepository, ob repository.OutboxRepository, pg payment.PaymentGateway) *TicketService {
+	return &TicketService{LockRepo: lr, TicketRepo: tr, SeatRepo: sr, KafkaRepo: kr, Outbox: ob, Payments: pg, events: newEventCache(), queueSecret: newQueueSecret(), queueHub: newQueueHub(), throughput: newThroughputCache()}
 }
 
 // BuyTicket: 대기열 진입부터 예매 성공까지의 핵심 로직
-func (s *TicketService) BuyTicket(userID string) (string, int) {
-	ctx := context.Background()
-	ticketName := "concert_2026"
-	maxActive := 100
-
-	// 1. 빠른 재고 확인
-	currentStock, err := s.LockRepo.GetStock(ctx, ticketName)
-	if err != nil || currentStock <= 0 {
-		return "SOLD_OUT", 0
+// 예약(홀드 생성)과 확정을 한 번에 수행하며, 이미 잡아둔 홀드가 있으면 그 홀드를 확정합니다.
+// Redis 장애로 차단기가 열려 있으면 MySQL만으로 판매합니다.
+// quantity는 이번 주문 수량이며, LIMIT_EXCEEDED이면 두 번째 값으로 남은 구매 한도를 반환합니다.
+// 세 번째 값은 주문 ID입니다. (SUCCESS, PAYMENT_FAILED일 때)
+func (s *TicketService) BuyTicket(userID string, eventID uint, quantity int) (string, int, string) {
+	if s.degraded() {
+		return s.buyDirect(userID, eventID, quantity)
 	}
 
-	// 2. 가상 대기열 진입 시도
-	status, rank, err := s.LockRepo.TryEnterOrEnqueue(ctx, userID, maxActive)
-	if err != nil || status == "WAITING" {
-		return status, rank
+	// 1. 대기열 진입 + 재고 차감 + 홀드 생성
+	res := s.Reserve(userID, eventID, quantity)
+	switch res.Status {
+	case "RESERVED", "ALREADY_RESERVED":
+	case "WAITING", "LOBBY":
+		return res.Status, res.Rank, ""
+	case "LIMIT_EXCEEDED":
+		return res.Status, res.Allowance, ""
+	case "FAIL":
+		// 이번 요청으로 차단기가 열렸다면 바로 MySQL로 판매
+		if s.degraded() {
+			return s.buyDirect(userID, eventID, quantity)
+		}
+		return res.Status, 0, ""
+	default:
+		return res.Status, 0, ""
 	}
 
-	// 3. 진입 성공 시, 함수 종료 시점에 무조건 Active Set에서 유저 제거 (defer 사용)
-	defer s.LockRepo.RemoveActiveUser(ctx, userID)
-	metrics.PurchaseRequests.Inc()
+	// 2. 홀드 확정 + 예매 이벤트 Outbox 기록
+	return s.ConfirmReservation(userID, eventID, res.HoldID)
+}
 
-	// 4. 중복 구매 체크
-	if purchased, _ := s.LockRepo.IsUserPurchased(ctx, ticketName, userID); purchased {
-		return "ALREADY_PURCHASED", 0
-	}
+// CancelTicket: 예매 취소 로직 (유저가 구매한 모든 주문을 환불하고 전체 수량을 취소)
+func (s *TicketService) CancelTicket(userID string, eventID uint) (bool, string) {
+	ctx := context.Background()
 
-	// 5. Redis 재고 차감 (Lua Script 호출)
-	remaining, err := s.LockRepo.DecreaseStock(ctx, ticketName)
-	if err != nil || remaining < 0 {
-		return "SOLD_OUT", 0
+	if s.degraded() {
+		// 취소는 Redis 구매자 명단/재고와 함께 처리해야 하므로 복구 후 가능
+		return false, "시스템 점검 중으로 취소할 수 없습니다. 잠시 후 다시 시도해주세요."
 	}
 
-	// 6. Kafka로 예매 이벤트 발행 (비동기 저장 시작)
-	if err := s.KafkaRepo.PublishPurchase(userID, ticketName); err != nil {
-		s.rollbackRedis(ctx, ticketName, userID) // 실패 시 재고 복구
-		return "FAIL", 0
+	event, err := s.getEvent(eventID)
+	if err != nil {
+		return false, "존재하지 않는 이벤트입니다."
 	}
+	ticketName := event.Name
 
-	// 7. 구매자 명단 추가
-	s.LockRepo.AddPurchasedUser(ctx, ticketName, userID)
-	metrics.TicketStockLevel.Set(float64(remaining))
-
-	return "SUCCESS", remaining
-}
-
-// CancelTicket: 예매 취소 로직
-func (s *TicketService) CancelTicket(userID string) (bool, string) {
-	ctx := context.Background()
-	ticketName := "concert_2026"
-
-	isPurchased, err := s.LockRepo.IsUserPurchased(ctx, ticketName, userID)
-	if err != nil || !isPurchased {
+	quantity, err := s.LockRepo.GetPurchasedQuantity(ctx, ticketName, userID)
+	if err != nil || quantity == 0 {
 		return false, "구매 내역이 없거나 이미 취소되었습니다."
 	}
 
-	newStock, err := s.LockRepo.IncreaseStock(ctx, ticketName)
+	// 환불할 주문별 결제 정보 (MySQL 구매 내역 기준)
+	purchases, err := s.purchasePayments(userID, ticketName, quantity)
+	if errors.Is(err, ErrPaymentPending) {
+		return false, "결제 정보를 확인 중입니다. 잠시 후 다시 시도해주세요."
+	} else if err != nil {
+		return false, "구매 내역 조회 중 오류가 발생했습니다."
+	}
+
+	// 구매자 명단/수량 제거 + 재고 복구 + 취소 이벤트 Outbox 기록 (Lua Script)
+	// 티켓을 먼저 회수해야 환불 후 Redis 오류/경합으로 돈과 티켓을 모두 갖게 되는 일이 없음
+	paymentIDs := make([]string, len(purchases))
+	for i, p := range purchases {
+		paymentIDs[i] = p.PaymentID
+	}
+	entry := repository.CancelMessage(userID, ticketName, quantity, paymentIDs, purchases[0].PaymentID)
+	newStock, err := s.LockRepo.CancelPurchase(ctx, ticketName, userID, quantity, entry)
 	if err != nil {
 		return false, "재고 복구 중 오류가 발생했습니다."
 	}
-
-	metrics.TicketStockLevel.Set(float64(newStock))
-	s.LockRepo.RemovePurchasedUser(ctx, ticketName, userID)
-	s.KafkaRepo.PublishCancel(userID, ticketName)
-
+	if newStock == -2 {
+		// 조회 이후 구매/양도로 수량이 바뀌어 환불할 주문 목록이 달라짐
+		return false, "결제 정보를 확인 중입니다. 잠시 후 다시 시도해주세요."
+	}
+	if newStock < 0 {
+		// 동시에 들어온 취소 요청이 먼저 처리됨
+		return false, "구매 내역이 없거나 이미 취소되었습니다."
+	}
+	metrics.TicketStockLevel.WithLabelValues(ticketName).Set(float64(newStock))
+
+	// 주문별 결제 환불 (재시도해도 주문마다 한 번만 환불됨)
+	// 실패한 주문은 감사 로그에 REFUND_FAILED로 남겨 운영자가 같은 결제 ID로 재처리하도록 함
+	refunded := true
+	for _, p := range purchases {
+		if err := s.refundPayment(ctx, p.PaymentID, p.Amount, p.PaymentID); err != nil {
+			refunded = false
+			s.recordRefundFailure(&p, err)
+		}
+	}
+	if !refunded {
+		return true, "취소되었지만 환불 처리가 지연되고 있습니다. 확인 후 환불해드리겠습니다."
+	}
+	if err := s.TicketRepo.UpdatePaymentStatus(userID, ticketName, payment.StatusRefunded); err != nil {
+		fmt.Printf("[결제 상태 갱신 실패] 유저 %s 이벤트 %s: %v\n", userID, ticketName, err)
+	}
 	return true, "취소 요청이 접수되었습니다."
 }
 
-// rollbackRedis: Kafka 전송 실패 등 예외 상황 발생 시 Redis 재고 원상복구
-func (s *TicketService) rollbackRedis(ctx context.Context, ticketName, userID string) {
-	newStock, _ := s.LockRepo.IncreaseStock(ctx, ticketName)
-	metrics.TicketStockLevel.Set(float64(newStock))
+// recordRefundFailure: 취소가 반영된 뒤 환불에 실패한 주문을 감사 로그에 기록
+func (s *TicketService) recordRefundFailure(p *repository.Purchase, cause error) {
+	err := s.TicketRepo.AppendAudit(&repository.AuditLog{
+		UserID:     p.UserID,
+		TicketName: p.TicketName,
+		Action:     repository.AuditRefundFailed,
+		Quantity:   p.Quantity,
+		OrderID:    p.OrderID,
+		PaymentID:  p.PaymentID,
+		Actor:      repository.ActorAPI,
+		Detail:     cause.Error(),
+	})
+	if err != nil {
+		fmt.Printf("[환불 실패 기록 실패] 유저 %s 결제 %s: %v\n", p.UserID, p.PaymentID, err)
+	}
+}
+
+// rollbackRedis: 홀드 해제 등으로 차감했던 Redis 재고를 quantity만큼 원상복구
+func (s *TicketService) rollbackRedis(ctx context.Context, ticketName string, quantity int) {
+	newStock, _ := s.LockRepo.AdjustStock(ctx, ticketName, quantity)
+	metrics.TicketStockLevel.WithLabelValues(ticketName).Set(float64(newStock))
 }
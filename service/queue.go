@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"ticket-system/repository"
+	"time"
+)
+
+const (
+	queueTokenTTL     = 6 * time.Hour    // 대기열 토큰 유효 시간 (판매 오픈 전후 대기 시간을 충분히 포함)
+	admissionGrace    = 2 * time.Minute  // 승급된 유저가 예매를 시작해야 하는 유예 시간
+	activeSessionTTL  = 30 * time.Second // Active Set에 입장한 유저가 예매 요청을 마칠 때까지 자리를 유지하는 시간
+	estimatedTurnTime = 30 * time.Second // 처리량 기록이 없을 때 앞선 Active Set 한 차례가 예매를 마치는 데 걸리는 예상 시간
+)
+
+var ErrInvalidQueueToken = errors.New("대기열 토큰이 올바르지 않거나 만료되었습니다")
+
+// ErrQueueTokenRequired: 대기실/대기열에 들어간 유저가 대기열 토큰 없이 요청함
+var ErrQueueTokenRequired = errors.New("대기열에 입장한 유저는 대기열 토큰이 필요합니다")
+
+/*
+ * 대기열 토큰
+ * - 대기열에 진입하면 (유저, 이벤트, 진입 시 순번, 만료 시각)을 HMAC-SHA256으로 서명한 토큰을 발급합니다.
+ * - 진입 시 순번은 진행률(progress_percent)의 기준이며, 같은 토큰으로 다시 요청하면 기준이 유지됩니다.
+ * - 대기 중인 유저는 토큰으로 /queue/status를 조회하며, 조회는 대기열에 다시 진입하지 않으므로 순번이 유지됩니다.
+ * - 승급(ADMITTED)된 유저는 admissionGrace 안에 토큰과 함께 예매를 요청해야 하며, 지나면 대기열 맨 뒤로 다시 진입합니다.
+ * - 서버가 여러 대면 SetQueueSecret으로 같은 키를 설정해야 다른 서버가 발급한 토큰을 검증할 수 있습니다.
+ */
+
+// queueClaims: 토큰에 서명되는 내용
+type queueClaims struct {
+	UserID    string `json:"uid"`
+	EventID   uint   `json:"eid"`
+	Rank      int    `json:"rank,omitempty"` // 대기열 진입 시 순번
+	ExpiresAt int64  `json:"exp"`
+}
+
+// QueueStatus: 대기열 상태 조회 결과
+// State: LOBBY(판매 시작 전 대기실, OpensAt에 무작위 순서로 배정), WAITING(Rank, 예상 대기 시간, 진행률), ADMITTED(AdmissionExpiresAt까지 예매 가능), EXPIRED(유예 시간 경과, 다시 진입 필요), NONE(대기열에 없음)
+type QueueStatus struct {
+	EventID            uint       `json:"event_id"`
+	State              string     `json:"state"`
+	Rank               int        `json:"rank,omitempty"`
+	EstimatedWait      int        `json:"estimated_wait_seconds"`
+	ETA                *time.Time `json:"eta,omitempty"`    // 예상 입장 시각
+	Progress           int        `json:"progress_percent"` // 진입 시 순번 대비 진행률 (0~100)
+	AdmissionExpiresAt *time.Time `json:"admission_expires_at,omitempty"`
+	OpensAt            *time.Time `json:"opens_at,omitempty"` // 대기실 유저가 대기열에 배정되는 판매 시작 시각
+}
+
+// newQueueSecret: 서버 기동 시 임의로 생성하는 기본 서명 키
+func newQueueSecret() []byte {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return b
+}
+
+// SetQueueSecret: 대기열 토큰 서명 키 설정 (여러 서버가 같은 키를 공유해야 함)
+func (s *TicketService) SetQueueSecret(secret []byte) {
+	s.queueSecret = secret
+}
+
+// IssueQueueToken: 대기열에 진입한 유저에게 발급하는 서명 토큰 (rank: 진입 시 순번)
+func (s *TicketService) IssueQueueToken(userID string, eventID uint, rank int) string {
+	payload, _ := json.Marshal(queueClaims{
+		UserID:    userID,
+		EventID:   eventID,
+		Rank:      rank,
+		ExpiresAt: time.Now().Add(queueTokenTTL).Unix(),
+	})
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + base64.RawURLEncoding.EncodeToString(s.signQueueToken(body))
+}
+
+// CheckQueueEntry: queue_token 없이 들어온 예매 요청을 허용할지 확인
+// 이미 대기실/대기열에 들어갔거나 승급된 유저는 발급받은 토큰으로만 요청할 수 있습니다.
+// (토큰 없이 user_id만으로 다른 유저의 순번을 쓰거나 그 유저의 토큰을 새로 발급받는 것을 막음)
+func (s *TicketService) CheckQueueEntry(userID string, eventID uint) error {
+	if s.degraded() {
+		// MySQL 직접 판매 중에는 대기열을 거치지 않음
+		return nil
+	}
+	event, err := s.getEvent(eventID)
+	if err != nil {
+		return err
+	}
+	pos, err := s.LockRepo.GetQueuePosition(context.Background(), event.Name, userID)
+	if err != nil {
+		return err
+	}
+	if pos.State != "NONE" {
+		return ErrQueueTokenRequired
+	}
+	return nil
+}
+
+// VerifyQueueToken: 서명과 만료 시각을 확인하고 토큰의 유저/이벤트를 반환
+func (s *TicketService) VerifyQueueToken(token string) (string, uint, error) {
+	claims, err := s.parseQueueToken(token)
+	if err != nil {
+		return "", 0, err
+	}
+	return claims.UserID, claims.EventID, nil
+}
+
+func (s *TicketService) parseQueueToken(token string) (queueClaims, error) {
+	var claims queueClaims
+	body, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return claims, ErrInvalidQueueToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, s.signQueueToken(body)) {
+		return claims, ErrInvalidQueueToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return claims, ErrInvalidQueueToken
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.UserID == "" || claims.EventID == 0 {
+		return claims, ErrInvalidQueueToken
+	}
+	if time.Now().Unix() > claims.ExpiresAt {
+		return claims, ErrInvalidQueueToken
+	}
+	return claims, nil
+}
+
+func (s *TicketService) signQueueToken(body string) []byte {
+	h := hmac.New(sha256.New, s.queueSecret)
+	h.Write([]byte(body))
+	return h.Sum(nil)
+}
+
+// GetQueueStatus: 토큰 소유자의 대기 순번/입장 상태 조회 (대기열에 다시 진입하지 않음)
+func (s *TicketService) GetQueueStatus(token string) (*QueueStatus, error) {
+	claims, err := s.parseQueueToken(token)
+	if err != nil {
+		return nil, err
+	}
+	event, err := s.getEvent(claims.EventID)
+	if err != nil {
+		return nil, err
+	}
+	return s.queueStatus(context.Background(), claims.UserID, event, claims.Rank)
+}
+
+// WaitingStatus: 대기열 진입(WAITING) 응답에 담을 상태와 대기열 토큰
+// 요청에 같은 유저/이벤트의 토큰이 있으면 진행률 기준을 유지하도록 그대로 돌려주고,
+// 없거나 다시 줄을 서서 순번이 뒤로 밀린 경우에는 현재 순번으로 새로 발급합니다.
+func (s *TicketService) WaitingStatus(userID string, eventID uint, rank int, token string) (*QueueStatus, string) {
+	claims, err := s.parseQueueToken(token)
+	if err != nil || claims.UserID != userID || claims.EventID != eventID || claims.Rank < rank {
+		token = s.IssueQueueToken(userID, eventID, rank)
+		claims.Rank = rank
+	}
+
+	status := &QueueStatus{EventID: eventID, State: "WAITING", Rank: rank}
+	if event, err := s.getEvent(eventID); err == nil {
+		s.fillWaiting(context.Background(), status, event, claims.Rank)
+	}
+	return status, token
+}
+
+// queueStatus: 유저의 현재 대기열 상태 (Redis 기준, initialRank: 진입 시 순번)
+func (s *TicketService) queueStatus(ctx context.Context, userID string, event *repository.Ticket, initialRank int) (*QueueStatus, error) {
+	pos, err := s.LockRepo.GetQueuePosition(ctx, event.Name, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &QueueStatus{EventID: event.ID, State: pos.State, Rank: pos.Rank}
+	switch pos.State {
+	case "LOBBY":
+		status.OpensAt = &event.SaleStartAt
+	case "WAITING":
+		s.fillWaiting(ctx, status, event, initialRank)
+	case "ADMITTED":
+		status.Progress = 100
+	}
+	if !pos.AdmittedUntil.IsZero() {
+		status.AdmissionExpiresAt = &pos.AdmittedUntil
+	}
+	return status, nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sync"
+	"ticket-system/repository"
+	"time"
+)
+
+const (
+	throughputWindow   = time.Minute // 예상 대기 시간 계산에 사용하는 최근 처리량 구간
+	throughputCacheTTL = time.Second // 이벤트별 처리량 조회 결과 캐시 (상태 조회마다 Redis를 읽지 않도록)
+)
+
+/*
+ * 예상 대기 시간 (ETA)
+ * - Promoter의 승급 인원과 Active Set에서 예매를 마치고 나간 인원을 Redis에 초 단위로 집계합니다. (모든 API 서버 합산)
+ * - 순번을 최근 1분간의 초당 승급 인원으로 나누어 예상 대기 시간을 구하며, 승급 기록이 없으면 완료 인원을 사용합니다.
+ * - 판매 직후처럼 처리량 기록이 없으면 Active Set 크기 단위의 차례 수 × estimatedTurnTime으로 추정합니다.
+ */
+
+// throughputCache: 이벤트별 최근 처리량 캐시
+type throughputCache struct {
+	mu    sync.Mutex
+	rates map[string]cachedThroughput
+}
+
+type cachedThroughput struct {
+	rate     repository.QueueThroughput
+	loadedAt time.Time
+}
+
+func newThroughputCache() *throughputCache {
+	return &throughputCache{rates: make(map[string]cachedThroughput)}
+}
+
+// queueThroughput: 이벤트의 최근 초당 승급/완료 인원 (조회 실패 시 처리량 없음으로 간주)
+func (s *TicketService) queueThroughput(ctx context.Context, ticketName string) repository.QueueThroughput {
+	c := s.throughput
+	c.mu.Lock()
+	cached, ok := c.rates[ticketName]
+	c.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < throughputCacheTTL {
+		return cached.rate
+	}
+
+	rate, err := s.LockRepo.GetQueueThroughput(ctx, ticketName, throughputWindow)
+	if err != nil {
+		return cached.rate
+	}
+	c.mu.Lock()
+	c.rates[ticketName] = cachedThroughput{rate: rate, loadedAt: time.Now()}
+	c.mu.Unlock()
+	return rate
+}
+
+// fillWaiting: 대기 중인 유저의 예상 대기 시간, 예상 입장 시각, 진행률 계산
+func (s *TicketService) fillWaiting(ctx context.Context, status *QueueStatus, event *repository.Ticket, initialRank int) {
+	fillWaitingAt(status, event, s.queueThroughput(ctx, event.Name), initialRank, time.Now())
+}
+
+// fillWaitingAt: 조회해 둔 처리량과 기준 시각으로 대기 상태를 채움 (Redis를 조회하지 않음)
+// Hub는 승급 알림마다 이벤트당 한 번만 처리량을 조회하고 구독자 전체에 같은 값을 나눠 씁니다.
+func fillWaitingAt(status *QueueStatus, event *repository.Ticket, rate repository.QueueThroughput, initialRank int, now time.Time) {
+	status.EstimatedWait = estimateWait(rate, event, status.Rank)
+	eta := now.Add(time.Duration(status.EstimatedWait) * time.Second)
+	status.ETA = &eta
+	status.Progress = queueProgress(status.Rank, initialRank)
+}
+
+// estimateWait: 예상 대기 시간(초)
+func estimateWait(rate repository.QueueThroughput, event *repository.Ticket, rank int) int {
+	perSecond := rate.Admitted
+	if perSecond == 0 {
+		perSecond = rate.Completed
+	}
+	if perSecond > 0 {
+		return int(math.Ceil(float64(rank) / perSecond))
+	}
+
+	// 처리량 기록이 없으면 앞선 대기자가 Active Set 크기 단위로 입장한다고 보고 추정
+	activeLimit := max(event.ActiveLimit(), 1)
+	turns := (rank + activeLimit - 1) / activeLimit
+	return turns * int(estimatedTurnTime/time.Second)
+}
+
+// queueProgress: 진입 시 순번 대비 앞으로 나아간 비율 (0~100)
+func queueProgress(rank int, initialRank int) int {
+	if initialRank <= 0 || rank >= initialRank {
+		return 0
+	}
+	return (initialRank - rank) * 100 / initialRank
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"testing"
+	"ticket-system/repository"
+)
+
+func TestQueueProgress(t *testing.T) {
+	tests := []struct {
+		rank, initialRank, want int
+	}{
+		{rank: 100, initialRank: 100, want: 0},
+		{rank: 50, initialRank: 100, want: 50},
+		{rank: 1, initialRank: 100, want: 99},
+		{rank: 2, initialRank: 3, want: 33},
+		{rank: 120, initialRank: 100, want: 0}, // 다시 줄을 서서 순번이 밀린 경우
+		{rank: 5, initialRank: 0, want: 0},     // 진입 순번을 모름 (대기실 배정 전)
+		{rank: 5, initialRank: -1, want: 0},
+	}
+	for _, tt := range tests {
+		if got := queueProgress(tt.rank, tt.initialRank); got != tt.want {
+			t.Errorf("queueProgress(%d, %d) = %d, want %d", tt.rank, tt.initialRank, got, tt.want)
+		}
+	}
+}
+
+func TestEstimateWait(t *testing.T) {
+	event := &repository.Ticket{MaxActive: 10}
+	tests := []struct {
+		name string
+		rate repository.QueueThroughput
+		rank int
+		want int
+	}{
+		{"승급 처리량 기준", repository.QueueThroughput{Admitted: 4, Completed: 100}, 10, 3},
+		{"승급 기록이 없으면 완료 처리량", repository.QueueThroughput{Completed: 2}, 10, 5},
+		{"처리량 기록 없음: 한 차례", repository.QueueThroughput{}, 10, 30},
+		{"처리량 기록 없음: 두 차례", repository.QueueThroughput{}, 11, 60},
+	}
+	for _, tt := range tests {
+		if got := estimateWait(tt.rate, event, tt.rank); got != tt.want {
+			t.Errorf("%s: estimateWait(rank %d) = %d, want %d", tt.name, tt.rank, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"ticket-system/repository"
+	"time"
+)
+
+// queueHubRetryDelay: Redis 알림 구독이 끊겼을 때 다시 구독하기까지의 대기 시간
+const queueHubRetryDelay = time.Second
+
+/*
+ * 대기열 푸시 (SSE)
+ * - 대기 유저는 폴링 대신 한 번 연결(SubscribeQueue)하여 순번 갱신과 입장 알림을 받습니다.
+ * - Promoter가 승급 결과를 Redis Pub/Sub으로 발행하면, 각 API 서버의 Hub가 자신에게 연결된 유저에게만 전달합니다.
+ * - 승급은 대기열 앞에서부터 이루어지므로 남은 대기자의 순번은 승급 인원만큼 줄어들며,
+ *   알림 유실에 대비해 연결마다 주기적으로 Redis 기준 순번으로 다시 맞춥니다(Resync).
+ */
+
+// queueHub: 이벤트별로 이 서버에 연결된 대기열 구독자
+type queueHub struct {
+	mu   sync.Mutex
+	subs map[string]map[*QueueSubscription]struct{}
+}
+
+func newQueueHub() *queueHub {
+	return &queueHub{subs: make(map[string]map[*QueueSubscription]struct{})}
+}
+
+func (h *queueHub) add(sub *QueueSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[sub.ticketName] == nil {
+		h.subs[sub.ticketName] = make(map[*QueueSubscription]struct{})
+	}
+	h.subs[sub.ticketName][sub] = struct{}{}
+}
+
+func (h *queueHub) remove(sub *QueueSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subs[sub.ticketName], sub)
+	if len(h.subs[sub.ticketName]) == 0 {
+		delete(h.subs, sub.ticketName)
+	}
+}
+
+// subscribers: 이벤트의 현재 구독자 목록
+func (h *queueHub) subscribers(ticketName string) []*QueueSubscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	subs := make([]*QueueSubscription, 0, len(h.subs[ticketName]))
+	for sub := range h.subs[ticketName] {
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+// dispatchQueueUpdate: 승급 결과를 해당 이벤트의 구독자에게 전달
+// 처리량(Redis)은 알림마다 이벤트당 한 번만 조회하고, 구독자별 순번/예상 대기 시간은 그 값으로 메모리에서 계산합니다.
+// (구독자마다 Redis를 조회하면 Hub 고루틴이 막혀 다음 알림이 밀림)
+func (s *TicketService) dispatchQueueUpdate(ctx context.Context, update repository.QueueUpdate) {
+	subs := s.queueHub.subscribers(update.TicketName)
+	if len(subs) == 0 {
+		return
+	}
+
+	if update.LobbyOpened {
+		// 대기실 유저가 대기열에 배정되었으므로 각자 배정된 순번을 조회
+		for _, sub := range subs {
+			go sub.Resync()
+		}
+		return
+	}
+
+	admitted := make(map[string]bool, len(update.Admitted))
+	for _, userID := range update.Admitted {
+		admitted[userID] = true
+	}
+	until := time.UnixMilli(update.AdmittedUntil)
+	rate := s.queueThroughput(ctx, update.TicketName)
+	now := time.Now()
+	for _, sub := range subs {
+		if admitted[sub.userID] {
+			sub.admit(until)
+		} else {
+			sub.advance(len(update.Admitted), rate, now)
+		}
+	}
+}
+
+// QueueSubscription: 대기 유저 한 명의 푸시 연결
+// Updates로 LOBBY(판매 전 대기실)/WAITING(순번 갱신) 상태가 이어지다가 ADMITTED/EXPIRED/NONE 중 하나가 오면 더 이상 갱신되지 않습니다.
+type QueueSubscription struct {
+	Updates <-chan QueueStatus
+
+	svc         *TicketService
+	event       *repository.Ticket
+	userID      string
+	ticketName  string
+	initialRank int // 대기열 진입 시 순번 (진행률 기준, 대기실에서 들어왔으면 배정된 순번)
+	updates     chan QueueStatus
+
+	mu   sync.Mutex
+	rank int
+	done bool
+}
+
+// SubscribeQueue: 토큰 소유자의 대기열 푸시 구독 (현재 상태를 첫 갱신으로 전달)
+func (s *TicketService) SubscribeQueue(token string) (*QueueSubscription, error) {
+	claims, err := s.parseQueueToken(token)
+	if err != nil {
+		return nil, err
+	}
+	event, err := s.getEvent(claims.EventID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(chan QueueStatus, 1)
+	sub := &QueueSubscription{
+		Updates:     updates,
+		svc:         s,
+		event:       event,
+		userID:      claims.UserID,
+		ticketName:  event.Name,
+		initialRank: claims.Rank,
+		updates:     updates,
+	}
+	// 첫 상태 조회 전에 등록해야 조회와 등록 사이의 승급 알림을 놓치지 않음
+	s.queueHub.add(sub)
+	if err := sub.Resync(); err != nil {
+		s.queueHub.remove(sub)
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Resync: Redis 기준 현재 상태로 순번을 다시 맞춤
+func (sub *QueueSubscription) Resync() error {
+	status, err := sub.svc.queueStatus(context.Background(), sub.userID, sub.event, sub.initialRank)
+	if err != nil {
+		return err
+	}
+
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.done {
+		return nil
+	}
+	if sub.initialRank == 0 && status.State == "WAITING" {
+		sub.initialRank = status.Rank // 대기실에서 배정된 순번을 진행률 기준으로 사용
+	}
+	sub.rank = status.Rank
+	sub.done = status.State != "WAITING" && status.State != "LOBBY"
+	sub.push(*status)
+	return nil
+}
+
+// Close: 구독 해제
+func (sub *QueueSubscription) Close() {
+	sub.svc.queueHub.remove(sub)
+}
+
+// admit: 입장 알림
+func (sub *QueueSubscription) admit(until time.Time) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.done {
+		return
+	}
+	sub.done = true
+	sub.push(QueueStatus{EventID: sub.event.ID, State: "ADMITTED", Progress: 100, AdmissionExpiresAt: &until})
+}
+
+// advance: 앞선 대기자가 promoted명 승급했으므로 순번을 그만큼 당김 (rate, now: Hub가 이벤트당 한 번 구한 처리량과 기준 시각)
+func (sub *QueueSubscription) advance(promoted int, rate repository.QueueThroughput, now time.Time) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.done || sub.rank == 0 {
+		return
+	}
+	sub.rank = max(sub.rank-promoted, 1)
+	status := QueueStatus{EventID: sub.event.ID, State: "WAITING", Rank: sub.rank}
+	fillWaitingAt(&status, sub.event, rate, sub.initialRank, now)
+	sub.push(status)
+}
+
+// push: 가장 최근 상태만 남기고 전달 (느린 연결이 Hub를 막지 않도록 이전 갱신은 버림, sub.mu 보유 중 호출)
+func (sub *QueueSubscription) push(status QueueStatus) {
+	select {
+	case <-sub.updates:
+	default:
+	}
+	sub.updates <- status
+}
+
+// StartQueueHub: Redis 승급 알림을 구독하여 이 서버에 연결된 대기 유저에게 전달
+func (s *TicketService) StartQueueHub(ctx context.Context) {
+	fmt.Println("대기열 알림 Hub가 가동되었습니다. (구독: queue:updates:*)")
+
+	for {
+		updates, err := s.LockRepo.SubscribeQueueUpdates(ctx)
+		if err != nil {
+			fmt.Printf("[Queue Hub 에러] 승급 알림 구독 실패: %v\n", err)
+		} else {
+			for update := range updates {
+				s.dispatchQueueUpdate(ctx, update)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("대기열 알림 Hub를 종료합니다.")
+			return
+		case <-time.After(queueHubRetryDelay):
+		}
+	}
+}
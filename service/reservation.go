@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"ticket-system/metrics"
+	"ticket-system/repository"
+	"time"
+)
+
+// 결제 대기 홀드 유지 시간 (이 시간 안에 확정하지 않으면 Reaper가 재고를 반환)
+const reservationTTL = 10 * time.Minute
+
+// ReservationResult: 예약(홀드) 요청 결과
+// Status: RESERVED, ALREADY_RESERVED, LOBBY(판매 전 대기실), WAITING(Rank), SOLD_OUT, ALREADY_PURCHASED, LIMIT_EXCEEDED(Allowance),
+// ORDER_LIMIT_EXCEEDED, NOT_FOUND, NOT_ON_SALE, SEAT_REQUIRED, FAIL
+type ReservationResult struct {
+	Status    string    `json:"status"`
+	HoldID    string    `json:"hold_id,omitempty"`
+	Quantity  int       `json:"quantity,omitempty"`
+	Rank      int       `json:"rank,omitempty"`
+	Allowance int       `json:"allowance,omitempty"` // 1인 한도까지 더 구매할 수 있는 수량
+	Stock     int       `json:"stock"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// Reserve: 대기열을 통과한 유저의 재고를 quantity만큼 차감하고 reservationTTL 동안 유지되는 홀드를 생성 (1단계)
+// quantity가 0 이하면 1매로 처리하며, 1회 주문 한도와 1인 누적 한도를 넘으면 홀드를 만들지 않습니다.
+func (s *TicketService) Reserve(userID string, eventID uint, quantity int) ReservationResult {
+	ctx := context.Background()
+	quantity = max(quantity, 1)
+
+	// 0. 이벤트 조회 및 판매 기간 확인
+	event, err := s.getEvent(eventID)
+	if errors.Is(err, repository.ErrTicketNotFound) {
+		return ReservationResult{Status: "NOT_FOUND"}
+	} else if err != nil {
+		return ReservationResult{Status: "FAIL"}
+	}
+	now := time.Now()
+	if event.InLobby(now) {
+		// 판매 시작 전에는 대기실에 모였다가 판매 시작 시 무작위 순서로 대기열에 배정
+		if status, _ := s.joinLobby(ctx, event, userID); status != "" {
+			return ReservationResult{Status: status}
+		}
+		now = event.SaleStartAt // 다른 서버가 이미 대기실을 열었으므로 판매가 시작된 것으로 봄
+	}
+	if !event.IsOnSale(now) {
+		return ReservationResult{Status: "NOT_ON_SALE"}
+	}
+	if event.Reserved {
+		// 지정석 이벤트는 좌석 선점(HoldSeats) → 확정(BuySeats) 흐름으로만 예매
+		return ReservationResult{Status: "SEAT_REQUIRED"}
+	}
+	if quantity > event.OrderLimit() {
+		return ReservationResult{Status: "ORDER_LIMIT_EXCEEDED", Allowance: event.OrderLimit()}
+	}
+	ticketName := event.Name
+
+	// 1. 빠른 재고 확인
+	currentStock, err := s.LockRepo.GetStock(ctx, ticketName)
+	if err != nil {
+		return ReservationResult{Status: "FAIL"}
+	}
+	if currentStock < quantity {
+		return ReservationResult{Status: "SOLD_OUT"}
+	}
+
+	// 2. 가상 대기열 진입 시도
+	status, rank, err := s.LockRepo.TryEnterOrEnqueue(ctx, ticketName, userID, event.ActiveLimit(), activeSessionTTL)
+	if err != nil {
+		return ReservationResult{Status: "FAIL"}
+	}
+	if status == "WAITING" || status == "LOBBY" {
+		// LOBBY: 판매가 막 시작되어 대기실 유저가 아직 대기열에 배정되기 전
+		return ReservationResult{Status: status, Rank: rank}
+	}
+
+	// 3. 홀드를 잡으면 재고가 확보되므로 Active Set 자리는 바로 다음 유저에게 넘김
+	defer s.LockRepo.RemoveActiveUser(ctx, ticketName, userID)
+	metrics.PurchaseRequests.Inc()
+
+	// 4. 누적 한도 체크 + 재고 차감 + 홀드 생성 (Lua Script)
+	holdID := newID()
+	status, holdID, remaining, err := s.LockRepo.ReserveStock(ctx, ticketName, userID, holdID, quantity, event.UserLimit(), reservationTTL)
+	if err != nil {
+		return ReservationResult{Status: "FAIL"}
+	}
+	switch status {
+	case "RESERVED":
+	case "LIMIT_EXCEEDED":
+		return ReservationResult{Status: status, Allowance: remaining}
+	case "SOLD_OUT":
+		return ReservationResult{Status: status, Stock: remaining}
+	default:
+		return ReservationResult{Status: status, HoldID: holdID}
+	}
+
+	metrics.TicketStockLevel.WithLabelValues(ticketName).Set(float64(remaining))
+	return ReservationResult{
+		Status:    "RESERVED",
+		HoldID:    holdID,
+		Quantity:  quantity,
+		Stock:     remaining,
+		ExpiresAt: time.Now().Add(reservationTTL),
+	}
+}
+
+// ConfirmReservation: 결제 후 홀드를 구매로 확정하고 예매 이벤트를 Outbox에 기록 (2단계)
+// 결제 전에 홀드 ID를 주문 ID로 하는 PENDING 주문을 만들고, 워커가 구매 내역을 저장하면서 확정합니다.
+// status: SUCCESS, PAYMENT_FAILED(결제 거절, 홀드는 유지), EXPIRED(홀드 만료), NOT_FOUND(홀드 없음), FAIL
+func (s *TicketService) ConfirmReservation(userID string, eventID uint, holdID string) (string, int, string) {
+	ctx := context.Background()
+
+	event, err := s.getEvent(eventID)
+	if err != nil {
+		return "NOT_FOUND", 0, ""
+	}
+	ticketName := event.Name
+
+	// 홀드 수량만큼 결제
+	hold, err := s.LockRepo.GetHold(ctx, ticketName, holdID, userID)
+	if err != nil {
+		return "FAIL", 0, ""
+	}
+	if hold == nil {
+		return "NOT_FOUND", 0, ""
+	}
+
+	// 0. 결제 대기 주문 생성 (결제 재시도 시에는 기존 주문 유지)
+	if err := s.TicketRepo.CreateOrder(repository.NewOrder(holdID, userID, ticketName, hold.Quantity, event.Price, nil)); err != nil {
+		return "FAIL", 0, ""
+	}
+
+	// 1. 결제 승인 (거절되어도 홀드는 만료 전까지 유지되므로 다른 수단으로 재시도 가능)
+	auth, err := s.authorizePayment(ctx, holdID, userID, ticketName, event.Price*hold.Quantity)
+	if err != nil {
+		return "PAYMENT_FAILED", 0, holdID
+	}
+
+	// 2. 매입
+	captured, err := s.capturePayment(ctx, holdID, auth.ID)
+	if err != nil {
+		s.voidPayment(ctx, holdID, auth)
+		return "PAYMENT_FAILED", 0, holdID
+	}
+
+	// 매입 결과를 주문에 기록 (Reconciler가 워커 저장 전에도 원래 결제 ID로 복구할 수 있도록 Redis 확정 전에 기록)
+	if err := s.TicketRepo.RecordOrderPayment(holdID, captured.ID, captured.Amount); err != nil {
+		s.refundPayment(ctx, captured.ID, captured.Amount, holdID)
+		return "FAIL", 0, ""
+	}
+
+	// 3. Redis 홀드 확정 + 예매 이벤트 Outbox 기록 (하나의 Lua 스크립트로 원자적으로 처리)
+	// Kafka 발행은 OutboxRelay가 담당하므로 이 시점 이후 프로세스가 죽어도 이벤트는 유실되지 않음
+	entry := repository.PurchaseMessage(userID, ticketName, hold.Quantity, captured.ID, captured.Amount, holdID)
+	// 같은 홀드의 확정 요청이 동시에 들어와도(클라이언트 재시도, ALREADY_RESERVED 재확정) 같은 결제 키로 매입한 같은 결제이므로,
+	// 홀드가 만료되었거나 확정된 적 없이 사라진 경우에만 환불합니다.
+	status, err := s.LockRepo.ConfirmHold(ctx, ticketName, holdID, userID, entry)
+	if err != nil {
+		// 확정 여부를 알 수 없으므로 환불하지 않음 (재시도하면 ALREADY_CONFIRMED 또는 EXPIRED로 결정되고, 주문에 결제 ID가 남아 있음)
+		return "FAIL", 0, ""
+	}
+	switch status {
+	case "CONFIRMED", "ALREADY_CONFIRMED":
+	default:
+		s.refundPayment(ctx, captured.ID, captured.Amount, holdID)
+		s.TicketRepo.CancelPendingOrder(holdID)
+		return status, 0, ""
+	}
+
+	remaining, _ := s.LockRepo.GetStock(ctx, ticketName)
+	metrics.TicketStockLevel.WithLabelValues(ticketName).Set(float64(remaining))
+	return "SUCCESS", remaining, holdID
+}
+
+// ReleaseReservation: 유저가 결제를 포기한 경우 홀드를 해제하고 재고를 반환
+func (s *TicketService) ReleaseReservation(userID string, eventID uint, holdID string) (bool, string) {
+	ctx := context.Background()
+
+	event, err := s.getEvent(eventID)
+	if err != nil {
+		return false, "존재하지 않는 이벤트입니다."
+	}
+
+	// 홀드 해제 + 재고 반환 + 만료 이벤트 Outbox 기록 (Lua Script)
+	newStock, err := s.LockRepo.ReleaseHold(ctx, event.Name, holdID, userID)
+	if err != nil {
+		return false, "홀드 해제 중 오류가 발생했습니다."
+	}
+	if newStock < 0 {
+		return false, "유효한 예약이 없거나 이미 만료되었습니다."
+	}
+	metrics.TicketStockLevel.WithLabelValues(event.Name).Set(float64(newStock))
+
+	// 결제를 시도했던 홀드라면 주문도 바로 취소 (실패해도 워커가 만료 이벤트로 다시 처리)
+	s.TicketRepo.CancelPendingOrder(holdID)
+	return true, "예약이 취소되었습니다."
+}
+
+// StartReaper는 백그라운드에서 주기적으로 만료된 홀드를 회수합니다. (재고 반환 + 만료 이벤트 Outbox 기록은 회수와 원자적으로 처리)
+func (s *TicketService) StartReaper(ctx context.Context) {
+	ticker := time.NewTicker(1 * time.Second) // 1초 주기로 실행
+	defer ticker.Stop()
+
+	fmt.Println("Reaper 워커가 가동되었습니다. (대상: 이벤트별 reservations)")
+
+	for {
+		select {
+		case <-ticker.C:
+			s.reapExpiredHolds(ctx)
+		case <-ctx.Done():
+			fmt.Println("Reaper 워커를 종료합니다.")
+			return
+		}
+	}
+}
+
+// reapExpiredHolds: 판매 종료 후에도 남은 홀드가 있을 수 있으므로 모든 이벤트를 대상으로 회수
+func (s *TicketService) reapExpiredHolds(ctx context.Context) {
+	if s.degraded() {
+		return // 홀드는 Redis에 있으므로 복구 후 회수
+	}
+	events, err := s.ListEvents()
+	if err != nil {
+		fmt.Printf("[Reaper 에러] 이벤트 목록 조회 중 오류: %v\n", err)
+		return
+	}
+
+	now := time.Now()
+	for _, ev := range events {
+		expired, err := s.LockRepo.ClaimExpiredHolds(ctx, ev.Name, now, 100)
+		if err != nil {
+			fmt.Printf("[Reaper 에러] %s 만료 홀드 회수 중 오류: %v\n", ev.Name, err)
+			continue
+		}
+
+		if len(expired) == 0 {
+			continue
+		}
+		// 재고 반환과 만료 이벤트 기록은 회수 스크립트에서 함께 처리됨
+		metrics.ReservationsExpired.WithLabelValues(ev.Name).Add(float64(len(expired)))
+		if newStock, err := s.LockRepo.GetStock(ctx, ev.Name); err == nil {
+			metrics.TicketStockLevel.WithLabelValues(ev.Name).Set(float64(newStock))
+		}
+		fmt.Printf("[Reaper] %s 만료된 홀드 %d건의 재고를 반환했습니다.\n", ev.Name, len(expired))
+	}
+}
+
+// newID: 홀드/주문 식별용 랜덤 ID (16바이트 hex)
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"ticket-system/metrics"
+	"ticket-system/payment"
+	"ticket-system/repository"
+	"time"
+)
+
+// 좌석 선점 유지 시간 (이 시간 안에 구매를 확정하지 않으면 자동으로 풀립니다)
+const seatHoldTTL = 5 * time.Minute
+
+var (
+	ErrSeatMapExists   = errors.New("이미 좌석 배치도가 등록된 이벤트입니다")
+	ErrInvalidSeatMap  = errors.New("좌석 배치도 정보가 올바르지 않습니다")
+	ErrNotReservedSeat = errors.New("지정석 이벤트가 아닙니다")
+	ErrSeatMapHasSales = errors.New("이미 판매되었거나 예약된 티켓이 있는 이벤트입니다")
+)
+
+// SeatMapRequest: 좌석 배치도 등록 요청 (구역 > 열 > 좌석 수)
+type SeatMapRequest struct {
+	Sections []struct {
+		Name string `json:"name"`
+		Rows []struct {
+			Name  string `json:"name"`
+			Seats int    `json:"seats"`
+		} `json:"rows"`
+	} `json:"sections"`
+}
+
+// SeatMap: 실시간 좌석 상태가 반영된 배치도 응답
+type SeatMap struct {
+	EventID  uint          `json:"event_id"`
+	Sections []SeatSection `json:"sections"`
+}
+
+type SeatSection struct {
+	Name string    `json:"name"`
+	Rows []SeatRow `json:"rows"`
+}
+
+type SeatRow struct {
+	Name  string            `json:"name"`
+	Seats []repository.Seat `json:"seats"`
+}
+
+// CreateSeatMap: 좌석 배치도를 등록하고 이벤트를 지정석으로 전환합니다.
+// 판매 수량과 Redis 재고는 등록된 좌석 수로 다시 설정됩니다.
+func (s *TicketService) CreateSeatMap(ctx context.Context, eventID uint, req SeatMapRequest) (int, error) {
+	event, err := s.TicketRepo.GetTicket(eventID)
+	if err != nil {
+		return 0, err
+	}
+	if count, err := s.SeatRepo.CountSeats(eventID); err != nil {
+		return 0, err
+	} else if count > 0 {
+		return 0, ErrSeatMapExists
+	}
+
+	// 판매 수량 변경(UpdateEvent)과 겹치지 않도록 이벤트 단위로 직렬화
+	lockKey := "lock:event:" + event.Name
+	if ok, err := s.LockRepo.Lock(ctx, lockKey, eventLockTTL); err != nil {
+		return 0, err
+	} else if !ok {
+		return 0, ErrEventBusy
+	}
+	defer s.LockRepo.Unlock(ctx, lockKey)
+	if event, err = s.TicketRepo.GetTicket(eventID); err != nil {
+		return 0, err
+	}
+	if err := s.checkNoSales(ctx, event); err != nil {
+		return 0, err
+	}
+
+	var seats []repository.Seat
+	for _, section := range req.Sections {
+		for _, row := range section.Rows {
+			if section.Name == "" || row.Name == "" || row.Seats <= 0 {
+				return 0, ErrInvalidSeatMap
+			}
+			for n := 1; n <= row.Seats; n++ {
+				seats = append(seats, repository.Seat{
+					TicketID: eventID,
+					Section:  section.Name,
+					Row:      row.Name,
+					Number:   n,
+					Status:   repository.SeatAvailable,
+				})
+			}
+		}
+	}
+	if len(seats) == 0 {
+		return 0, ErrInvalidSeatMap
+	}
+
+	if err := s.SeatRepo.CreateSeats(seats); err != nil {
+		return 0, err
+	}
+
+	delta := len(seats) - event.Capacity
+	event.Reserved = true
+	event.Capacity = len(seats)
+	if err := s.TicketRepo.UpdateTicket(event, delta); err != nil {
+		return 0, err
+	}
+	s.events.invalidate()
+
+	if err := s.LockRepo.InitStock(ctx, event.Name, len(seats)); err != nil {
+		return 0, err
+	}
+	metrics.TicketStockLevel.WithLabelValues(event.Name).Set(float64(len(seats)))
+	return len(seats), nil
+}
+
+// checkNoSales: 자유석으로 판매/예약된 티켓이 있는지 확인
+// 좌석 배치도를 등록하면 재고를 좌석 수로 초기화하므로, 이미 팔린 티켓이 있으면 그만큼 초과 판매됩니다.
+// (MySQL 재고는 워커가 저장한 판매분, Redis는 워커 반영 전 판매분과 결제 대기 홀드 기준)
+func (s *TicketService) checkNoSales(ctx context.Context, event *repository.Ticket) error {
+	if event.Stock != event.Capacity {
+		return ErrSeatMapHasSales
+	}
+	purchased, err := s.LockRepo.GetPurchasedQuantities(ctx, event.Name)
+	if err != nil {
+		return err
+	}
+	holds, err := s.LockRepo.CountHolds(ctx, event.Name)
+	if err != nil {
+		return err
+	}
+	if len(purchased) > 0 || holds > 0 {
+		return ErrSeatMapHasSales
+	}
+	return nil
+}
+
+// GetSeatMap: MySQL 좌석 배치도에 Redis의 실시간 판매/선점 상태를 덮어 반환
+func (s *TicketService) GetSeatMap(ctx context.Context, eventID uint) (*SeatMap, error) {
+	event, err := s.getEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if !event.Reserved {
+		return nil, ErrNotReservedSeat
+	}
+
+	seats, err := s.SeatRepo.ListSeats(eventID)
+	if err != nil {
+		return nil, err
+	}
+	seatIDs := make([]uint, len(seats))
+	for i, seat := range seats {
+		seatIDs[i] = seat.ID
+	}
+	states, err := s.LockRepo.GetSeatStates(ctx, event.Name, seatIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	seatMap := &SeatMap{EventID: eventID}
+	for _, seat := range seats {
+		seat.Status = repository.SeatAvailable
+		if state, ok := states[seat.ID]; ok {
+			seat.Status = state
+		}
+
+		if n := len(seatMap.Sections); n == 0 || seatMap.Sections[n-1].Name != seat.Section {
+			seatMap.Sections = append(seatMap.Sections, SeatSection{Name: seat.Section})
+		}
+		section := &seatMap.Sections[len(seatMap.Sections)-1]
+		if n := len(section.Rows); n == 0 || section.Rows[n-1].Name != seat.Row {
+			section.Rows = append(section.Rows, SeatRow{Name: seat.Row})
+		}
+		row := &section.Rows[len(section.Rows)-1]
+		row.Seats = append(row.Seats, seat)
+	}
+	return seatMap, nil
+}
+
+// HoldSeats: 대기열을 통과한 유저가 여러 좌석을 한 번에 선점 (전부 성공하거나 전부 실패)
+// status: HELD(선점 성공), LOBBY(판매 전 대기실), WAITING(대기), SEAT_UNAVAILABLE(이미 판매/선점된 좌석 포함), INVALID_SEATS 등
+func (s *TicketService) HoldSeats(userID string, eventID uint, seatIDs []uint) (string, int) {
+	ctx := context.Background()
+
+	event, status := s.seatEvent(eventID)
+	if status == "NOT_ON_SALE" && event.InLobby(time.Now()) {
+		// 판매 시작 전에는 대기실에 모였다가 판매 시작 시 무작위 순서로 대기열에 배정
+		// 다른 서버가 이미 대기실을 열었으면 판매가 시작된 것으로 보고 대기열로 진행
+		if status, size := s.joinLobby(ctx, event, userID); status != "" {
+			return status, size
+		}
+		status = ""
+	}
+	if status != "" {
+		return status, 0
+	}
+	seatIDs, ok := normalizeSeatIDs(seatIDs)
+	if !ok {
+		return "INVALID_SEATS", 0
+	}
+	// 다른 이벤트의 좌석 ID로 선점하지 못하도록 배치도 소속 확인
+	if exists, err := s.SeatRepo.SeatsExist(eventID, seatIDs); err != nil {
+		return "FAIL", 0
+	} else if !exists {
+		return "INVALID_SEATS", 0
+	}
+
+	// 좌석 선점 후 구매 확정까지 Active 상태가 유지되므로 세션도 선점 시간만큼 유지
+	status, rank, err := s.LockRepo.TryEnterOrEnqueue(ctx, event.Name, userID, event.ActiveLimit(), seatHoldTTL)
+	if err != nil {
+		return "FAIL", 0
+	}
+	if status != "ACTIVE" {
+		return status, rank // WAITING 또는 대기실 배정 전(LOBBY)
+	}
+
+	if purchased, _ := s.LockRepo.IsUserPurchased(ctx, event.Name, userID); purchased {
+		s.LockRepo.RemoveActiveUser(ctx, event.Name, userID)
+		return "ALREADY_PURCHASED", 0
+	}
+
+	held, err := s.LockRepo.HoldSeats(ctx, event.Name, userID, seatIDs, seatHoldTTL)
+	if err != nil {
+		s.LockRepo.RemoveActiveUser(ctx, event.Name, userID)
+		return "FAIL", 0
+	}
+	if !held {
+		// 다른 좌석을 고를 수 있도록 Active 상태는 유지합니다.
+		return "SEAT_UNAVAILABLE", 0
+	}
+	return "HELD", len(seatIDs)
+}
+
+// BuySeats: 선점한 좌석을 구매 확정하고 좌석 구매 이벤트를 Outbox에 기록
+// 좌석별 항목을 가진 PENDING 주문을 결제 전에 만들고, 세 번째 값으로 주문 ID를 반환합니다.
+// status: SUCCESS(remaining = 남은 좌석 수), NOT_HELD(선점 만료/타인 좌석), FAIL 등
+func (s *TicketService) BuySeats(userID string, eventID uint, seatIDs []uint) (string, int, string) {
+	ctx := context.Background()
+
+	event, status := s.seatEvent(eventID)
+	if status != "" {
+		return status, 0, ""
+	}
+	seatIDs, ok := normalizeSeatIDs(seatIDs)
+	if !ok {
+		return "INVALID_SEATS", 0, ""
+	}
+	defer s.LockRepo.RemoveActiveUser(ctx, event.Name, userID)
+	metrics.PurchaseRequests.Inc()
+
+	// 0. 결제 대기 주문 생성 (주문 ID = 결제 키)
+	paymentKey := newID()
+	if err := s.TicketRepo.CreateOrder(repository.NewOrder(paymentKey, userID, event.Name, len(seatIDs), event.Price, seatIDs)); err != nil {
+		return "FAIL", 0, ""
+	}
+
+	// 1. 결제 승인 (좌석 수 x 가격)
+	auth, err := s.authorizePayment(ctx, paymentKey, userID, event.Name, event.Price*len(seatIDs))
+	if err != nil {
+		s.TicketRepo.CancelPendingOrder(paymentKey)
+		return "PAYMENT_FAILED", 0, ""
+	}
+
+	// 2. 매입
+	captured, err := s.capturePayment(ctx, paymentKey, auth.ID)
+	if err != nil {
+		s.voidPayment(ctx, paymentKey, auth)
+		s.TicketRepo.CancelPendingOrder(paymentKey)
+		return "PAYMENT_FAILED", 0, ""
+	}
+
+	// 매입 결과를 주문에 기록 (Reconciler가 워커 저장 전에도 원래 결제 ID로 복구할 수 있도록 Redis 확정 전에 기록)
+	if err := s.TicketRepo.RecordOrderPayment(paymentKey, captured.ID, captured.Amount); err != nil {
+		s.refundPayment(ctx, captured.ID, captured.Amount, paymentKey)
+		s.TicketRepo.CancelPendingOrder(paymentKey)
+		return "FAIL", 0, ""
+	}
+
+	// 3. 선점 좌석 확정 + 좌석 구매 이벤트 Outbox 기록 (실패 시 매입 건 환불)
+	entry := repository.SeatPurchaseMessage(userID, event.Name, seatIDs, captured.ID, captured.Amount, paymentKey)
+	remaining, err := s.LockRepo.ConfirmSeats(ctx, event.Name, userID, seatIDs, entry)
+	if err != nil || remaining < 0 {
+		s.refundPayment(ctx, captured.ID, captured.Amount, paymentKey)
+		if err != nil {
+			return "FAIL", 0, ""
+		}
+		s.TicketRepo.CancelPendingOrder(paymentKey)
+		return "NOT_HELD", 0, ""
+	}
+
+	metrics.TicketStockLevel.WithLabelValues(event.Name).Set(float64(remaining))
+	return "SUCCESS", remaining, paymentKey
+}
+
+// ReleaseSeats: 구매하지 않고 선점만 해제
+func (s *TicketService) ReleaseSeats(userID string, eventID uint, seatIDs []uint) (bool, string) {
+	ctx := context.Background()
+
+	event, status := s.seatEvent(eventID)
+	if status != "" && status != "NOT_ON_SALE" {
+		return false, "지정석 이벤트가 아닙니다."
+	}
+	seatIDs, ok := normalizeSeatIDs(seatIDs)
+	if !ok {
+		return false, "좌석 ID가 올바르지 않습니다."
+	}
+
+	if err := s.LockRepo.ReleaseSeats(ctx, event.Name, userID, seatIDs); err != nil {
+		return false, "좌석 선점 해제 중 오류가 발생했습니다."
+	}
+	s.LockRepo.RemoveActiveUser(ctx, event.Name, userID)
+	return true, "좌석 선점이 해제되었습니다."
+}
+
+// CancelSeats: 구매한 좌석 중 일부 또는 전체 취소
+func (s *TicketService) CancelSeats(userID string, eventID uint, seatIDs []uint) (bool, string) {
+	ctx := context.Background()
+
+	event, status := s.seatEvent(eventID)
+	if status != "" && status != "NOT_ON_SALE" {
+		return false, "지정석 이벤트가 아닙니다."
+	}
+	seatIDs, ok := normalizeSeatIDs(seatIDs)
+	if !ok {
+		return false, "좌석 ID가 올바르지 않습니다."
+	}
+
+	// 취소 좌석 수만큼 부분 환불 (환불 키에 좌석 목록을 포함하여 같은 취소 요청은 한 번만 환불)
+	// 지정석은 유저당 한 번만 구매하므로 구매 내역은 1건
+	purchases, err := s.purchasePayments(userID, event.Name, 0)
+	if errors.Is(err, ErrPaymentPending) {
+		return false, "결제 정보를 확인 중입니다. 잠시 후 다시 시도해주세요."
+	} else if err != nil {
+		return false, "구매 내역이 없거나 이미 취소된 좌석입니다."
+	}
+	purchase := purchases[0]
+
+	// 좌석 반환과 취소 이벤트 기록은 하나의 Lua 스크립트로 처리 (남은 좌석이 없으면 구매 취소 이벤트도 함께 기록)
+	left, err := s.LockRepo.CancelSeats(ctx, event.Name, userID, seatIDs,
+		repository.SeatCancelMessage(userID, event.Name, seatIDs, purchase.PaymentID),
+		repository.CancelMessage(userID, event.Name, purchase.Quantity, []string{purchase.PaymentID}, purchase.PaymentID))
+	if err != nil {
+		return false, "재고 복구 중 오류가 발생했습니다."
+	}
+	if left < 0 {
+		return false, "구매 내역이 없거나 이미 취소된 좌석입니다."
+	}
+
+	refundKey := purchase.PaymentID + ":" + repository.JoinSeatIDs(seatIDs)
+	if err := s.refundPayment(ctx, purchase.PaymentID, event.Price*len(seatIDs), refundKey); err != nil {
+		// 환불이 실패하면 좌석 취소를 되돌리지 않고 기록만 남겨 운영자가 재처리하도록 함
+		fmt.Printf("[좌석 환불 실패] 유저 %s 좌석 %v: %v\n", userID, seatIDs, err)
+	} else if left == 0 {
+		s.TicketRepo.UpdatePaymentStatus(userID, event.Name, payment.StatusRefunded)
+	} else {
+		s.TicketRepo.UpdatePaymentStatus(userID, event.Name, payment.StatusPartial)
+	}
+
+	if stock, err := s.LockRepo.GetStock(ctx, event.Name); err == nil {
+		metrics.TicketStockLevel.WithLabelValues(event.Name).Set(float64(stock))
+	}
+	return true, "취소 요청이 접수되었습니다."
+}
+
+// seatEvent: 지정석 요청 공통 검증 (문제가 없으면 빈 status 반환)
+func (s *TicketService) seatEvent(eventID uint) (*repository.Ticket, string) {
+	event, err := s.getEvent(eventID)
+	if errors.Is(err, repository.ErrTicketNotFound) {
+		return nil, "NOT_FOUND"
+	} else if err != nil {
+		return nil, "FAIL"
+	}
+	if !event.Reserved {
+		return nil, "NOT_RESERVED"
+	}
+	if !event.IsOnSale(time.Now()) {
+		return event, "NOT_ON_SALE"
+	}
+	return event, ""
+}
+
+// normalizeSeatIDs: 중복 제거 및 정렬 (좌석 수 제한은 한 번에 최대 10석)
+func normalizeSeatIDs(seatIDs []uint) ([]uint, bool) {
+	if len(seatIDs) == 0 || len(seatIDs) > 10 {
+		return nil, false
+	}
+	seen := make(map[uint]bool, len(seatIDs))
+	unique := make([]uint, 0, len(seatIDs))
+	for _, id := range seatIDs {
+		if id == 0 {
+			return nil, false
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	sort.Slice(unique, func(i, j int) bool { return unique[i] < unique[j] })
+	return unique, true
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ticket-system/codec"
+	"ticket-system/metrics"
+	"ticket-system/repository"
+	"time"
+)
+
+const (
+	warmStartLockKey = "lock:warm_start"
+	warmStartLockTTL = 1 * time.Minute
+	warmStartWait    = 2 * time.Minute
+)
+
+var ErrWarmStartTimeout = errors.New("다른 서버의 상태 복원이 끝나지 않았습니다")
+
+/*
+ * WarmStart: 서버 시작 시 Redis 판매 상태를 MySQL 기준으로 복원
+ * - 재고 키가 이미 있는 이벤트는 판매 중인 상태이므로 건드리지 않습니다. (재시작해도 판매 상태 유지)
+ * - 재고 키가 없는 이벤트(신규 Redis, 데이터 유실)만 tickets/purchases/seats로 재고와 구매자 명단을 복원하고,
+ *   워커가 아직 반영하지 못한 Kafka 메시지(groupID의 Lag)를 더해 판매 직후 상태를 맞춥니다.
+ * - 여러 API 서버가 동시에 떠도 분산 락으로 한 서버씩 순서대로 수행되며, 복원 자체도 키가 없을 때만 적용됩니다.
+ */
+func (s *TicketService) WarmStart(ctx context.Context, groupID string) error {
+	return s.warmStart(ctx, s.LockRepo, groupID)
+}
+
+// warmStart: 지정한 LockRepository로 복원 (Failover 복구 시에는 차단기를 거치지 않는 Redis를 사용)
+func (s *TicketService) warmStart(ctx context.Context, lr repository.LockRepository, groupID string) error {
+	if err := acquireWarmStartLock(ctx, lr); err != nil {
+		return err
+	}
+	defer lr.Unlock(ctx, warmStartLockKey)
+
+	events, err := s.TicketRepo.ListTickets()
+	if err != nil {
+		return err
+	}
+
+	var missing []repository.Ticket
+	for _, ev := range events {
+		exists, err := lr.HasStock(ctx, ev.Name)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			missing = append(missing, ev)
+		}
+	}
+	if len(missing) == 0 {
+		fmt.Printf("♻️ [WarmStart] 모든 이벤트(%d개)의 Redis 상태가 유지되어 있어 복원하지 않습니다.\n", len(events))
+		return nil
+	}
+
+	// 처리되지 않은 메시지를 빼고 복원하면 판매분이 재고로 되살아나 초과 판매가 발생하므로 Lag 조회 실패 시 중단
+	pending, err := s.KafkaRepo.PendingEvents(ctx, "ticket-topic", groupID)
+	if err != nil {
+		return fmt.Errorf("Kafka Lag 조회 실패: %w", err)
+	}
+	// 재시도 토픽에서 대기 중인 메시지도 아직 MySQL에 반영되지 않은 이벤트
+	for _, tier := range repository.DefaultRetryTiers {
+		retrying, err := s.KafkaRepo.PendingEvents(ctx, tier.Topic, repository.RetryGroupID(groupID, tier.Topic))
+		if err != nil {
+			return fmt.Errorf("%s Lag 조회 실패: %w", tier.Topic, err)
+		}
+		pending = append(pending, retrying...)
+	}
+
+	for i := range missing {
+		ev := &missing[i]
+		stock, purchased, soldSeats, failoverOrders, err := s.saleState(ev, pending)
+		if err != nil {
+			return fmt.Errorf("%s 상태 계산 실패: %w", ev.Name, err)
+		}
+		seeded, err := lr.SeedSaleState(ctx, ev.Name, stock, purchased, soldSeats, failoverOrders)
+		if err != nil {
+			return fmt.Errorf("%s 상태 복원 실패: %w", ev.Name, err)
+		}
+		if seeded {
+			metrics.TicketStockLevel.WithLabelValues(ev.Name).Set(float64(stock))
+			fmt.Printf("♻️ [WarmStart] %s 복원 완료 (재고 %d, 구매자 %d명)\n", ev.Name, stock, len(purchased))
+		}
+	}
+	return nil
+}
+
+// acquireWarmStartLock: 다른 서버가 복원 중이면 끝날 때까지 대기
+func acquireWarmStartLock(ctx context.Context, lr repository.LockRepository) error {
+	deadline := time.Now().Add(warmStartWait)
+	for {
+		ok, err := lr.Lock(ctx, warmStartLockKey, warmStartLockTTL)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrWarmStartTimeout
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// saleState: MySQL 판매 내역 + 미처리 메시지로 재고/유저별 구매 수량/판매 좌석을 계산
+// 마지막 값은 계산에 포함된 장애 모드 판매 주문으로, 복원 후 재동기화에서 다시 차감하지 않도록 함께 기록합니다.
+func (s *TicketService) saleState(ev *repository.Ticket, pending []*codec.Event) (int, map[string]int, map[uint]string, []string, error) {
+	if ev.Reserved {
+		seats, err := s.SeatRepo.ListSeats(ev.ID)
+		if err != nil {
+			return 0, nil, nil, nil, err
+		}
+		soldSeats := make(map[uint]string)
+		for _, seat := range seats {
+			if seat.Status == repository.SeatSold {
+				soldSeats[seat.ID] = seat.UserID
+			}
+		}
+		for _, e := range pending {
+			if e.TicketName != ev.Name {
+				continue
+			}
+			switch e.Type {
+			case codec.TypeSeatPurchase:
+				for _, id := range e.SeatIDs {
+					soldSeats[id] = e.UserID
+				}
+			case codec.TypeSeatCancel:
+				for _, id := range e.SeatIDs {
+					if soldSeats[id] == e.UserID {
+						delete(soldSeats, id)
+					}
+				}
+			}
+		}
+
+		// 좌석을 하나라도 보유한 유저가 구매자 (수량 = 보유 좌석 수)
+		purchased := make(map[string]int)
+		for _, userID := range soldSeats {
+			purchased[userID]++
+		}
+		return ev.Capacity - len(soldSeats), purchased, soldSeats, nil, nil
+	}
+
+	purchases, failoverOrders, err := s.TicketRepo.SaleSnapshot(ev.Name)
+	if err != nil {
+		return 0, nil, nil, nil, err
+	}
+	purchased := make(map[string]int, len(purchases))
+	for _, p := range purchases {
+		purchased[p.UserID] += max(p.Quantity, 1)
+	}
+	for _, e := range pending {
+		if e.TicketName != ev.Name {
+			continue
+		}
+		switch e.Type {
+		case codec.TypePurchase:
+			purchased[e.UserID] += max(e.Quantity, 1)
+		case codec.TypeCancel:
+			if len(e.ItemIDs) == 0 {
+				delete(purchased, e.UserID)
+				break
+			}
+			// 주문 항목 단위 취소는 취소한 항목 수만큼만 차감
+			if purchased[e.UserID] -= len(e.ItemIDs); purchased[e.UserID] <= 0 {
+				delete(purchased, e.UserID)
+			}
+		case codec.TypeTransfer:
+			// 양도는 재고 변화 없이 구매 수량만 받는 유저에게 이동
+			n := min(max(e.Quantity, 1), purchased[e.UserID])
+			if purchased[e.UserID] -= n; purchased[e.UserID] <= 0 {
+				delete(purchased, e.UserID)
+			}
+			if n > 0 {
+				purchased[e.ToUserID] += n
+			}
+		}
+	}
+	sold := 0
+	for _, n := range purchased {
+		sold += n
+	}
+	return ev.Capacity - sold, purchased, nil, failoverOrders, nil
+}
@@ -0,0 +1,200 @@
+package worker
+
+import (
+	"fmt"
+	"log"
+	"ticket-system/codec"
+	"ticket-system/repository"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	// DefaultBatchSize: 레인별로 한 번에 MySQL에 반영하는 최대 메시지 수
+	DefaultBatchSize = 100
+	// DefaultBatchWait: 첫 메시지 이후 배치를 채우기 위해 기다리는 최대 시간
+	DefaultBatchWait = 20 * time.Millisecond
+)
+
+var (
+	batchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "worker_batch_size",
+		Help:    "The number of messages written to MySQL in one batch",
+		Buckets: []float64{1, 5, 10, 25, 50, 100, 250, 500},
+	})
+
+	batchFlushSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "worker_batch_flush_seconds",
+		Help:    "The latency of a batched MySQL write",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"type"})
+
+	// 일괄 저장/삭제가 실패하여 건별 처리로 전환한 횟수
+	batchFallbacks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "worker_batch_fallback_total",
+		Help: "The total number of batches that fell back to per-message processing",
+	}, []string{"type"})
+)
+
+// decoded: 해석된 메시지
+type decoded struct {
+	msg kafka.Message
+	ev  *codec.Event
+}
+
+// collect: 첫 메시지 이후 BatchSize가 차거나 BatchWait이 지날 때까지 레인의 메시지를 모음
+func (w *PurchaseWorker) collect(first kafka.Message, lane <-chan kafka.Message) []kafka.Message {
+	batch := []kafka.Message{first}
+	timer := time.NewTimer(w.BatchWait)
+	defer timer.Stop()
+
+	for len(batch) < w.BatchSize {
+		select {
+		case m, ok := <-lane:
+			if !ok {
+				return batch
+			}
+			batch = append(batch, m)
+		case <-timer.C:
+			return batch
+		}
+	}
+	return batch
+}
+
+/*
+ * processBatch: 레인에서 모은 메시지를 순서대로 처리
+ * - 연속된 PURCHASE는 multi-row INSERT, 연속된 (전체) CANCEL은 WHERE ... IN 한 번으로 취소 처리(Soft Delete)합니다.
+ *   주문 항목 단위 취소는 항목별 상태 변경이 필요하므로 건별로 처리합니다.
+ * - 종류가 바뀌는 지점에서 끊어 처리하므로 같은 유저의 구매 → 취소 순서가 유지됩니다.
+ * - 일괄 반영이 실패하면 해당 묶음만 건별 처리로 전환하여 실패한 메시지만 재시도 토픽으로 보냅니다.
+ * - 앞선 메시지가 재시도 중인 유저의 메시지는 반영하지 않고 같은 재시도 단계로 옮깁니다.
+ */
+func (w *PurchaseWorker) processBatch(batch []kafka.Message) {
+	batchSize.Observe(float64(len(batch)))
+
+	var run []decoded
+	flush := func() {
+		if len(run) > 0 {
+			w.flushRun(run)
+			run = nil
+		}
+	}
+
+	for _, m := range batch {
+		if _, retrying := w.retrying.tier(string(m.Key)); retrying {
+			// 같은 유저의 앞선 메시지가 재시도 중이면 일괄 반영하지 않고 그 뒤에 줄을 세움
+			flush()
+			w.settle(m)
+			continue
+		}
+		ev, err := codec.Decode(m.Key, m.Value, repository.Headers(m))
+		if err != nil {
+			flush()
+			w.settle(m)
+			continue
+		}
+		if ev.Legacy {
+			legacyMessages.Inc()
+		}
+		if (ev.Type != codec.TypePurchase && ev.Type != codec.TypeCancel) || len(ev.ItemIDs) > 0 {
+			flush()
+			w.settle(m)
+			continue
+		}
+		if len(run) > 0 && run[0].ev.Type != ev.Type {
+			flush()
+		}
+		run = append(run, decoded{msg: m, ev: ev})
+	}
+	flush()
+}
+
+// flushRun: 같은 종류의 연속된 이벤트를 한 번에 반영하고, 실패하면 건별로 처리
+func (w *PurchaseWorker) flushRun(run []decoded) {
+	typ := run[0].ev.Type
+	start := time.Now()
+
+	var err error
+	switch typ {
+	case codec.TypePurchase:
+		err = w.savePurchases(run)
+	case codec.TypeCancel:
+		err = w.deletePurchases(run)
+	}
+	batchFlushSeconds.WithLabelValues(string(typ)).Observe(time.Since(start).Seconds())
+	if err == nil {
+		return
+	}
+
+	batchFallbacks.WithLabelValues(string(typ)).Inc()
+	log.Printf("⚠️ [일괄 %s 실패] %d건을 건별로 다시 처리합니다: %v", actionName(typ), len(run), err)
+	for _, d := range run {
+		w.settle(d.msg)
+	}
+}
+
+func (w *PurchaseWorker) savePurchases(run []decoded) error {
+	purchases := make([]*repository.Purchase, 0, len(run))
+	// 같은 주문(유저, 이벤트, 결제 ID)이 중복 수신된 경우 한 번만 저장
+	seen := make(map[[3]string]bool, len(run))
+	for _, d := range run {
+		key := [3]string{d.ev.UserID, d.ev.TicketName, d.ev.PaymentID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		purchases = append(purchases, purchaseFromEvent(d.ev, repository.MessageAudit(repository.ActorWorker, d.msg)))
+	}
+
+	saved, err := w.TicketRepo.SavePurchases(purchases)
+	if err != nil {
+		return err
+	}
+	mysqlSaveSuccess.Add(float64(saved))
+	fmt.Printf("✅ [일괄 저장 성공] %d건 중 %d건 MySQL 저장 완료 (나머지는 이미 처리됨)\n", len(run), saved)
+	return nil
+}
+
+func (w *PurchaseWorker) deletePurchases(run []decoded) error {
+	keys := make([]repository.PurchaseKey, 0, len(run))
+	seen := make(map[[2]string]bool, len(run))
+	for _, d := range run {
+		pair := [2]string{d.ev.UserID, d.ev.TicketName}
+		if seen[pair] {
+			// 같은 구매를 두 번 취소하는 메시지는 건별 처리와 결과가 달라지므로 일괄 처리하지 않음
+			return fmt.Errorf("유저 %s의 취소 메시지가 중복되었습니다", d.ev.UserID)
+		}
+		seen[pair] = true
+		keys = append(keys, repository.PurchaseKey{
+			UserID:     d.ev.UserID,
+			TicketName: d.ev.TicketName,
+			PaymentIDs: d.ev.PaymentIDs,
+			Audit:      repository.MessageAudit(repository.ActorWorker, d.msg),
+		})
+	}
+
+	if err := w.TicketRepo.DeletePurchases(keys); err != nil {
+		return err
+	}
+	fmt.Printf("🗑️ [일괄 취소 성공] %d건의 구매 내역 DB 취소 처리 완료\n", len(keys))
+	return nil
+}
+
+// settle: 메시지를 건별로 처리하고, 재시도/DLQ 토픽에 넘길 수 없으면 넘어갈 때까지 반복
+// 같은 유저의 앞선 메시지가 재시도 중이면 처리하지 않고 같은 재시도 단계로 옮깁니다.
+func (w *PurchaseWorker) settle(m kafka.Message) {
+	for {
+		diverted, err := w.divert(m, -1)
+		if !diverted {
+			err = w.handleMessage(m)
+		}
+		if err == nil {
+			return
+		}
+		time.Sleep(time.Second)
+	}
+}
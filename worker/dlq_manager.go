@@ -0,0 +1,478 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"ticket-system/codec"
+	"ticket-system/repository"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+var (
+	ErrDLQMessageNotFound = errors.New("DLQ 메시지를 찾을 수 없습니다")
+	ErrJobNotFound        = errors.New("재처리 작업을 찾을 수 없습니다")
+)
+
+// DLQ 목록 조회 시 한 번에 읽는 메시지 수 (필터로 걸러지는 경우 limit을 채울 때까지 반복)
+const dlqScanChunk = 100
+
+const (
+	// replayJobTTL: 끝난 재처리 Job을 조회할 수 있는 기간
+	replayJobTTL = 24 * time.Hour
+	// maxReplayJobs: 보관하는 재처리 Job 수 (넘으면 오래된 끝난 Job부터 정리, 실행 중인 Job은 유지)
+	maxReplayJobs = 100
+)
+
+/*
+ * DLQManager: ticket-dlq-topic 운영 도구
+ * - 목록/상세 조회 (error_reason 헤더와 처리 결과 포함)
+ * - 개별 메시지 또는 필터 범위 재처리 (비동기 Job으로 진행 상황 제공)
+ * - 개별 메시지 폐기, 파티션 구간 정리(Purge)
+ * Kafka 메시지 자체는 지우지 않고 Redis에 처리 결과를 기록하며, 실제 삭제는 토픽 보존 기간에 맡깁니다.
+ */
+type DLQManager struct {
+	KafkaRepo *repository.KafkaRepository
+	State     repository.DLQStateRepository
+	Worker    *PurchaseWorker
+
+	mu    sync.Mutex
+	jobs  map[string]*ReplayJob
+	jobSq int
+}
+
+// DLQMessage: DLQ 메시지와 처리 결과
+type DLQMessage struct {
+	Partition   int                    `json:"partition"`
+	Offset      int64                  `json:"offset"`
+	Key         string                 `json:"key"`
+	Value       string                 `json:"value"`
+	Headers     map[string]string      `json:"headers"`
+	ErrorReason string                 `json:"error_reason"`
+	Failure     repository.FailureMeta `json:"failure"` // 원본 위치, 시도 횟수, 최초/최근 실패 시각
+	Time        time.Time              `json:"time"`
+	Status      string                 `json:"status"`
+	Note        string                 `json:"note,omitempty"`
+	Event       *codec.Event           `json:"event,omitempty"` // 해석 가능한 경우에만
+}
+
+// DLQFilter: 목록 조회/범위 재처리 조건 (빈 값은 조건 없음)
+type DLQFilter struct {
+	Reason string `json:"reason"` // error_reason 부분 일치
+	Type   string `json:"type"`   // 이벤트 종류 (PURCHASE, CANCEL ...)
+	Ticket string `json:"ticket"`
+	Status string `json:"status"`
+}
+
+// DLQPartition: 파티션별 offset 범위
+type DLQPartition struct {
+	Partition    int   `json:"partition"`
+	FirstOffset  int64 `json:"first_offset"`
+	LastOffset   int64 `json:"last_offset"`
+	PurgedBefore int64 `json:"purged_before"`
+}
+
+// ReplayRequest: Messages를 지정하면 해당 메시지만, 아니면 파티션 범위 + 필터로 재처리
+type ReplayRequest struct {
+	Messages   []repository.DLQRef `json:"messages"`
+	Partition  *int                `json:"partition"`   // 없으면 전체 파티션
+	FromOffset int64               `json:"from_offset"` // 0이면 처음부터
+	ToOffset   int64               `json:"to_offset"`   // 0이면 끝까지 (미포함)
+	Filter     DLQFilter           `json:"filter"`
+	Force      bool                `json:"force"` // 이미 재처리한 메시지도 다시 처리
+}
+
+// ReplayJob: 재처리 진행 상황 (메모리에 보관되므로 서버 재시작 시 사라지며, 끝난 Job은 replayJobTTL 이후 정리)
+type ReplayJob struct {
+	ID         string        `json:"id"`
+	Status     string        `json:"status"` // RUNNING, COMPLETED, FAILED
+	Request    ReplayRequest `json:"request"`
+	Scanned    int           `json:"scanned"`
+	Replayed   int           `json:"replayed"`
+	Failed     int           `json:"failed"`
+	Skipped    int           `json:"skipped"`
+	Errors     []string      `json:"errors,omitempty"` // 최근 실패 사유 (최대 20건)
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+}
+
+func NewDLQManager(kr *repository.KafkaRepository, state repository.DLQStateRepository, w *PurchaseWorker) *DLQManager {
+	return &DLQManager{
+		KafkaRepo: kr,
+		State:     state,
+		Worker:    w,
+		jobs:      make(map[string]*ReplayJob),
+	}
+}
+
+// Partitions: 파티션별 offset 범위 (정리된 구간 반영)
+func (d *DLQManager) Partitions(ctx context.Context) ([]DLQPartition, error) {
+	offsets, err := d.KafkaRepo.TopicOffsets(ctx, repository.DLQTopic)
+	if err != nil {
+		return nil, err
+	}
+	purged, err := d.State.GetDLQPurged(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := make([]DLQPartition, 0, len(offsets))
+	for _, p := range offsets {
+		partitions = append(partitions, DLQPartition{
+			Partition:    p.Partition,
+			FirstOffset:  p.FirstOffset,
+			LastOffset:   p.LastOffset,
+			PurgedBefore: purged[p.Partition],
+		})
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].Partition < partitions[j].Partition })
+	return partitions, nil
+}
+
+// List: partition의 offset부터 filter에 맞는 메시지를 최대 limit개 조회 (next: 다음 페이지 시작 offset, 끝이면 -1)
+func (d *DLQManager) List(ctx context.Context, partition int, offset int64, limit int, filter DLQFilter) ([]DLQMessage, int64, error) {
+	p, err := d.partition(ctx, partition)
+	if err != nil {
+		return nil, 0, err
+	}
+	from := max(offset, p.FirstOffset, p.PurgedBefore)
+
+	var result []DLQMessage
+	for from < p.LastOffset && len(result) < limit {
+		msgs, err := d.read(ctx, partition, from, p.LastOffset, dlqScanChunk)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(msgs) == 0 {
+			break
+		}
+		for _, m := range msgs {
+			from = m.Offset + 1
+			if matches(&m, filter) {
+				result = append(result, m)
+				if len(result) >= limit {
+					break
+				}
+			}
+		}
+	}
+
+	if from >= p.LastOffset {
+		from = -1
+	}
+	return result, from, nil
+}
+
+// Get: 메시지 한 건 상세 조회
+func (d *DLQManager) Get(ctx context.Context, partition int, offset int64) (*DLQMessage, error) {
+	p, err := d.partition(ctx, partition)
+	if err != nil {
+		return nil, err
+	}
+	if offset < max(p.FirstOffset, p.PurgedBefore) || offset >= p.LastOffset {
+		return nil, ErrDLQMessageNotFound
+	}
+	msgs, err := d.read(ctx, partition, offset, offset+1, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 || msgs[0].Offset != offset {
+		return nil, ErrDLQMessageNotFound
+	}
+	return &msgs[0], nil
+}
+
+// Discard: 재처리하지 않을 메시지로 표시
+func (d *DLQManager) Discard(ctx context.Context, partition int, offset int64, note string) error {
+	if _, err := d.Get(ctx, partition, offset); err != nil {
+		return err
+	}
+	return d.State.SetDLQStatus(ctx, repository.DLQRef{Partition: partition, Offset: offset}, repository.DLQDiscarded, note)
+}
+
+// Purge: partition의 before 이전 메시지를 목록/재처리 대상에서 제외
+func (d *DLQManager) Purge(ctx context.Context, partition int, before int64) error {
+	if _, err := d.partition(ctx, partition); err != nil {
+		return err
+	}
+	return d.State.PurgeDLQ(ctx, partition, before)
+}
+
+// StartReplay: 재처리 Job을 생성하고 백그라운드에서 실행
+func (d *DLQManager) StartReplay(req ReplayRequest) *ReplayJob {
+	d.mu.Lock()
+	d.pruneJobs(time.Now())
+	d.jobSq++
+	job := &ReplayJob{
+		ID:        fmt.Sprintf("replay-%d", d.jobSq),
+		Status:    "RUNNING",
+		Request:   req,
+		StartedAt: time.Now(),
+	}
+	d.jobs[job.ID] = job
+	snapshot := *job
+	d.mu.Unlock()
+
+	go d.runReplay(context.Background(), job)
+	return &snapshot
+}
+
+// Job / Jobs: 진행 상황 조회 (복사본 반환)
+func (d *DLQManager) Job(id string) (*ReplayJob, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	job, ok := d.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	snapshot := *job
+	snapshot.Errors = append([]string(nil), job.Errors...)
+	return &snapshot, nil
+}
+
+func (d *DLQManager) Jobs() []ReplayJob {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	jobs := make([]ReplayJob, 0, len(d.jobs))
+	for _, job := range d.jobs {
+		jobs = append(jobs, *job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].StartedAt.After(jobs[j].StartedAt) })
+	return jobs
+}
+
+// pruneJobs: TTL이 지난 끝난 Job을 지우고, 그래도 maxReplayJobs 이상이면 먼저 끝난 Job부터 정리 (d.mu를 잡은 상태로 호출)
+// 새 Job이 들어갈 자리를 남기며, 실행 중인 Job은 진행 상황 조회를 위해 지우지 않습니다.
+func (d *DLQManager) pruneJobs(now time.Time) {
+	var finished []*ReplayJob
+	for id, job := range d.jobs {
+		if job.FinishedAt == nil {
+			continue
+		}
+		if now.Sub(*job.FinishedAt) > replayJobTTL {
+			delete(d.jobs, id)
+			continue
+		}
+		finished = append(finished, job)
+	}
+
+	excess := len(d.jobs) - maxReplayJobs + 1
+	if excess <= 0 {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool { return finished[i].FinishedAt.Before(*finished[j].FinishedAt) })
+	for _, job := range finished[:min(excess, len(finished))] {
+		delete(d.jobs, job.ID)
+	}
+}
+
+func (d *DLQManager) runReplay(ctx context.Context, job *ReplayJob) {
+	err := d.replay(ctx, job)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = "COMPLETED"
+	if err != nil {
+		job.Status = "FAILED"
+		job.Errors = appendError(job.Errors, err.Error())
+	}
+	log.Printf("🛠️ [DLQ 재처리] %s %s (재처리 %d, 실패 %d, 건너뜀 %d)", job.ID, job.Status, job.Replayed, job.Failed, job.Skipped)
+}
+
+func (d *DLQManager) replay(ctx context.Context, job *ReplayJob) error {
+	req := job.Request
+
+	// 1. 지정한 메시지만 재처리
+	if len(req.Messages) > 0 {
+		for _, ref := range req.Messages {
+			msg, err := d.Get(ctx, ref.Partition, ref.Offset)
+			if err != nil {
+				d.record(job, nil, fmt.Errorf("%d:%d %w", ref.Partition, ref.Offset, err))
+				continue
+			}
+			d.replayOne(ctx, job, msg, req.Force)
+		}
+		return nil
+	}
+
+	// 2. 파티션 범위 + 필터
+	partitions, err := d.Partitions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, p := range partitions {
+		if req.Partition != nil && *req.Partition != p.Partition {
+			continue
+		}
+		from := max(req.FromOffset, p.FirstOffset, p.PurgedBefore)
+		to := p.LastOffset
+		if req.ToOffset > 0 && req.ToOffset < to {
+			to = req.ToOffset
+		}
+
+		for from < to {
+			msgs, err := d.read(ctx, p.Partition, from, to, dlqScanChunk)
+			if err != nil {
+				return err
+			}
+			if len(msgs) == 0 {
+				break
+			}
+			for i := range msgs {
+				from = msgs[i].Offset + 1
+				if matches(&msgs[i], req.Filter) {
+					d.replayOne(ctx, job, &msgs[i], req.Force)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// replayOne: 폐기/재처리 완료 메시지는 건너뛰고, 결과를 Redis와 Job에 기록
+func (d *DLQManager) replayOne(ctx context.Context, job *ReplayJob, msg *DLQMessage, force bool) {
+	ref := repository.DLQRef{Partition: msg.Partition, Offset: msg.Offset}
+	if msg.Status == repository.DLQDiscarded || msg.Status == repository.DLQRequeued || msg.Status == repository.DLQParked ||
+		(msg.Status == repository.DLQReplayed && !force) {
+		d.mu.Lock()
+		job.Scanned++
+		job.Skipped++
+		d.mu.Unlock()
+		return
+	}
+
+	m := kafka.Message{
+		Topic:     repository.DLQTopic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Key:       []byte(msg.Key),
+		Value:     []byte(msg.Value),
+		Headers:   toKafkaHeaders(msg.Headers),
+	}
+	err := d.Worker.Replay(m)
+	if err != nil {
+		// 실패 이력(attempt_count 등)을 갱신하여 DLQ에 다시 넣고, 한도를 넘으면 Parked 토픽으로 격리
+		status := repository.DLQReplayFailed
+		if topic, pubErr := d.KafkaRepo.PublishToDLQ(ctx, m, fmt.Sprintf("DLQ 재처리: %v", err)); pubErr == nil {
+			dlqPublished.WithLabelValues(topic).Inc()
+			status = repository.DLQRequeued
+			if topic == repository.ParkedTopic {
+				status = repository.DLQParked
+			}
+		}
+		d.State.SetDLQStatus(ctx, ref, status, err.Error())
+		d.record(job, nil, fmt.Errorf("%d:%d %w", msg.Partition, msg.Offset, err))
+		return
+	}
+	d.State.SetDLQStatus(ctx, ref, repository.DLQReplayed, job.ID)
+	d.record(job, msg, nil)
+}
+
+func (d *DLQManager) record(job *ReplayJob, msg *DLQMessage, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	job.Scanned++
+	if err != nil {
+		job.Failed++
+		job.Errors = appendError(job.Errors, err.Error())
+		return
+	}
+	job.Replayed++
+}
+
+func appendError(errs []string, msg string) []string {
+	errs = append(errs, msg)
+	if len(errs) > 20 {
+		errs = errs[len(errs)-20:]
+	}
+	return errs
+}
+
+func (d *DLQManager) partition(ctx context.Context, partition int) (*DLQPartition, error) {
+	partitions, err := d.Partitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range partitions {
+		if partitions[i].Partition == partition {
+			return &partitions[i], nil
+		}
+	}
+	return nil, ErrDLQMessageNotFound
+}
+
+// read: Kafka 메시지를 읽어 처리 결과와 함께 DLQMessage로 변환
+func (d *DLQManager) read(ctx context.Context, partition int, from int64, to int64, limit int) ([]DLQMessage, error) {
+	readCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	msgs, err := d.KafkaRepo.ReadPartition(readCtx, repository.DLQTopic, partition, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]repository.DLQRef, len(msgs))
+	for i, m := range msgs {
+		refs[i] = repository.DLQRef{Partition: m.Partition, Offset: m.Offset}
+	}
+	marks, err := d.State.GetDLQStatuses(ctx, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]DLQMessage, 0, len(msgs))
+	for i, m := range msgs {
+		headers := repository.Headers(m)
+		msg := DLQMessage{
+			Partition:   m.Partition,
+			Offset:      m.Offset,
+			Key:         string(m.Key),
+			Value:       string(m.Value),
+			Headers:     headers,
+			ErrorReason: headers[repository.HeaderErrorReason],
+			Failure:     repository.ParseFailureMeta(headers),
+			Time:        m.Time,
+			Status:      repository.DLQPending,
+		}
+		if mark, ok := marks[refs[i]]; ok {
+			msg.Status, msg.Note = mark.Status, mark.Note
+		}
+		if ev, err := codec.Decode(m.Key, m.Value, headers); err == nil {
+			msg.Event = ev
+		}
+		result = append(result, msg)
+	}
+	return result, nil
+}
+
+func matches(m *DLQMessage, f DLQFilter) bool {
+	if f.Reason != "" && !strings.Contains(m.ErrorReason, f.Reason) {
+		return false
+	}
+	if f.Status != "" && m.Status != f.Status {
+		return false
+	}
+	if f.Type != "" && (m.Event == nil || string(m.Event.Type) != f.Type) {
+		return false
+	}
+	if f.Ticket != "" && (m.Event == nil || m.Event.TicketName != f.Ticket) {
+		return false
+	}
+	return true
+}
+
+func toKafkaHeaders(headers map[string]string) []kafka.Header {
+	out := make([]kafka.Header, 0, len(headers))
+	for k, v := range headers {
+		out = append(out, kafka.Header{Key: k, Value: []byte(v)})
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func finishedJob(id string, at time.Time) *ReplayJob {
+	return &ReplayJob{ID: id, Status: "COMPLETED", StartedAt: at, FinishedAt: &at}
+}
+
+func TestPruneJobsExpiresFinishedJobs(t *testing.T) {
+	now := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	d := NewDLQManager(nil, nil, nil)
+	d.jobs["old"] = finishedJob("old", now.Add(-replayJobTTL-time.Minute))
+	d.jobs["recent"] = finishedJob("recent", now.Add(-time.Hour))
+	d.jobs["running"] = &ReplayJob{ID: "running", Status: "RUNNING", StartedAt: now.Add(-48 * time.Hour)}
+
+	d.pruneJobs(now)
+
+	if _, ok := d.jobs["old"]; ok {
+		t.Error("TTL이 지난 Job이 남아 있음")
+	}
+	for _, id := range []string{"recent", "running"} {
+		if _, ok := d.jobs[id]; !ok {
+			t.Errorf("Job %s가 정리됨", id)
+		}
+	}
+}
+
+func TestPruneJobsCapsFinishedJobs(t *testing.T) {
+	now := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	d := NewDLQManager(nil, nil, nil)
+	d.jobs["running"] = &ReplayJob{ID: "running", Status: "RUNNING", StartedAt: now.Add(-time.Hour)}
+	for i := 0; i < maxReplayJobs; i++ {
+		id := fmt.Sprintf("replay-%d", i)
+		d.jobs[id] = finishedJob(id, now.Add(time.Duration(i-maxReplayJobs)*time.Minute))
+	}
+
+	d.pruneJobs(now)
+
+	// 새 Job이 들어갈 자리를 남기고, 먼저 끝난 Job부터 정리
+	if len(d.jobs) != maxReplayJobs-1 {
+		t.Fatalf("len(jobs) = %d, want %d", len(d.jobs), maxReplayJobs-1)
+	}
+	if _, ok := d.jobs["running"]; !ok {
+		t.Error("실행 중인 Job이 정리됨")
+	}
+	for _, id := range []string{"replay-0", "replay-1"} {
+		if _, ok := d.jobs[id]; ok {
+			t.Errorf("가장 먼저 끝난 Job %s가 남아 있음", id)
+		}
+	}
+	if _, ok := d.jobs[fmt.Sprintf("replay-%d", maxReplayJobs-1)]; !ok {
+		t.Error("가장 최근에 끝난 Job이 정리됨")
+	}
+}
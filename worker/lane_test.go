@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithKeyWaitsForLane(t *testing.T) {
+	w := &PurchaseWorker{Lanes: 4}
+	w.initLanes()
+	key := []byte("user_1")
+
+	// 레인이 같은 유저의 배치를 반영하는 중이면 DLQ 재처리는 끝날 때까지 대기
+	lane := &w.laneLocks[laneOf(key, w.Lanes)]
+	lane.Lock()
+	ran := make(chan struct{})
+	go w.withKey(key, func() error {
+		close(ran)
+		return nil
+	})
+
+	select {
+	case <-ran:
+		t.Fatal("레인 처리 중에 같은 유저의 재처리가 실행됨")
+	case <-time.After(50 * time.Millisecond):
+	}
+	lane.Unlock()
+
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("레인 잠금이 풀린 뒤에도 재처리가 실행되지 않음")
+	}
+}
+
+func TestInitLanesDefault(t *testing.T) {
+	w := &PurchaseWorker{}
+	w.initLanes()
+	if w.Lanes != DefaultLanes || len(w.laneLocks) != DefaultLanes {
+		t.Fatalf("Lanes = %d, locks = %d; want %d", w.Lanes, len(w.laneLocks), DefaultLanes)
+	}
+}
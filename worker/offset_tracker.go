@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// offsetTracker: 병렬 처리된 메시지 중 앞 offset이 모두 끝난 지점까지만 커밋 대상으로 내보냄
+// 뒤 메시지가 먼저 끝나도 앞 메시지가 처리 중이면 커밋하지 않으므로, 장애로 재시작해도 처리되지 않은 메시지를 다시 읽습니다.
+type offsetTracker struct {
+	mu    sync.Mutex
+	parts map[int]*partitionOffsets
+}
+
+type partitionOffsets struct {
+	inflight []kafka.Message // Fetch 순서 (offset 오름차순)
+	done     map[int64]bool
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{parts: make(map[int]*partitionOffsets)}
+}
+
+// track: Fetch한 메시지를 처리 중 목록에 추가
+func (t *offsetTracker) track(m kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, ok := t.parts[m.Partition]
+	if !ok {
+		p = &partitionOffsets{done: make(map[int64]bool)}
+		t.parts[m.Partition] = p
+	}
+	p.inflight = append(p.inflight, m)
+}
+
+// complete: 처리 완료 표시 후, 앞에서부터 연속으로 끝난 마지막 메시지를 반환 (커밋할 것이 없으면 false)
+func (t *offsetTracker) complete(m kafka.Message) (kafka.Message, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, ok := t.parts[m.Partition]
+	if !ok {
+		return kafka.Message{}, false
+	}
+	p.done[m.Offset] = true
+
+	var last kafka.Message
+	n := 0
+	for n < len(p.inflight) && p.done[p.inflight[n].Offset] {
+		last = p.inflight[n]
+		delete(p.done, last.Offset)
+		n++
+	}
+	if n == 0 {
+		return kafka.Message{}, false
+	}
+	p.inflight = p.inflight[n:]
+	return last, true
+}
@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func msg(partition int, offset int64) kafka.Message {
+	return kafka.Message{Partition: partition, Offset: offset}
+}
+
+func TestOffsetTrackerCompleteInOrder(t *testing.T) {
+	tr := newOffsetTracker()
+	for off := int64(10); off < 13; off++ {
+		tr.track(msg(0, off))
+	}
+
+	for off := int64(10); off < 13; off++ {
+		last, ok := tr.complete(msg(0, off))
+		if !ok || last.Offset != off {
+			t.Fatalf("complete(%d) = %d, %v; want %d, true", off, last.Offset, ok, off)
+		}
+	}
+}
+
+func TestOffsetTrackerWaitsForEarlierOffsets(t *testing.T) {
+	tr := newOffsetTracker()
+	for off := int64(0); off < 4; off++ {
+		tr.track(msg(0, off))
+	}
+
+	// 뒤 메시지가 먼저 끝나도 0번이 처리 중이면 커밋하지 않음
+	for _, off := range []int64{2, 1} {
+		if last, ok := tr.complete(msg(0, off)); ok {
+			t.Fatalf("complete(%d) = %d, true; want false", off, last.Offset)
+		}
+	}
+
+	// 0번이 끝나면 연속으로 끝난 2번까지 한 번에 커밋
+	last, ok := tr.complete(msg(0, 0))
+	if !ok || last.Offset != 2 {
+		t.Fatalf("complete(0) = %d, %v; want 2, true", last.Offset, ok)
+	}
+
+	last, ok = tr.complete(msg(0, 3))
+	if !ok || last.Offset != 3 {
+		t.Fatalf("complete(3) = %d, %v; want 3, true", last.Offset, ok)
+	}
+}
+
+func TestOffsetTrackerPartitionsAreIndependent(t *testing.T) {
+	tr := newOffsetTracker()
+	tr.track(msg(0, 5))
+	tr.track(msg(1, 7))
+	tr.track(msg(0, 6))
+
+	// 다른 파티션의 처리 중 메시지는 커밋을 막지 않음
+	last, ok := tr.complete(msg(1, 7))
+	if !ok || last.Partition != 1 || last.Offset != 7 {
+		t.Fatalf("complete(p1, 7) = p%d %d, %v; want p1 7, true", last.Partition, last.Offset, ok)
+	}
+	if _, ok := tr.complete(msg(0, 6)); ok {
+		t.Fatal("complete(p0, 6) = true; want false (5번 처리 중)")
+	}
+	last, ok = tr.complete(msg(0, 5))
+	if !ok || last.Partition != 0 || last.Offset != 6 {
+		t.Fatalf("complete(p0, 5) = p%d %d, %v; want p0 6, true", last.Partition, last.Offset, ok)
+	}
+}
+
+func TestOffsetTrackerUnknownPartition(t *testing.T) {
+	tr := newOffsetTracker()
+	if _, ok := tr.complete(msg(3, 1)); ok {
+		t.Fatal("추적하지 않은 파티션의 complete = true; want false")
+	}
+}
@@ -0,0 +1,215 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"ticket-system/repository"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// Outbox → Kafka 발행 성공 건수
+	outboxPublished = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "outbox_relay_published_total",
+		Help: "The total number of outbox entries published to Kafka",
+	})
+
+	// Kafka 발행 실패 횟수 (항목은 스트림에 남아 재시도됨)
+	outboxPublishFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "outbox_relay_publish_failures_total",
+		Help: "The total number of failed outbox publish attempts",
+	})
+
+	// 아직 발행 완료되지 않은 Outbox 항목 수
+	outboxBacklog = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "outbox_relay_backlog",
+		Help: "The number of outbox entries not yet published",
+	})
+)
+
+/*
+ * OutboxRelay: Redis Outbox 스트림을 읽어 ticket-topic으로 발행하는 릴레이
+ * 모든 API 서버에서 실행되지만, Redis 리더 리스(OutboxLeaderKey)를 가진 한 대만 발행하고 나머지는 대기합니다.
+ * 여러 Relay가 같은 그룹에서 나눠 읽으면 같은 유저의 PURCHASE→CANCEL이 서로 다른 Relay에서 동시에 발행되어
+ * 워커가 CANCEL을 먼저 받을 수 있기 때문입니다.
+ * 발행에 성공한 항목만 ACK 하며, 발행에 실패한 배치는 새 항목을 읽지 않고 그 자리에서 재시도합니다.
+ * 리더가 바뀌면 새 리더는 이전 리더가 ACK 하지 못한 항목(pending)을 새 항목보다 먼저 모두 회수해 재발행합니다. (At-Least-Once)
+ * 같은 메시지가 두 번 발행될 수 있으므로 워커 측 저장은 멱등하게 처리됩니다.
+ * 리더가 리스 연장 없이 outboxLeaseTTL 이상 멈췄다가 다시 발행하면 순서가 어긋날 수 있으므로, TTL은 발행 재시도 간격보다 충분히 깁니다.
+ */
+type OutboxRelay struct {
+	Outbox    repository.OutboxRepository
+	KafkaRepo *repository.KafkaRepository
+	Consumer  string
+}
+
+const (
+	outboxBatchSize     = 100
+	outboxBlock         = 1 * time.Second
+	outboxClaimInterval = 5 * time.Second
+	outboxMinIdle       = 30 * time.Second // 이 시간 동안 ACK 되지 않은 항목은 실패로 간주
+	outboxRetryMin      = 500 * time.Millisecond
+	outboxRetryMax      = 10 * time.Second // 발행 실패 배치 재시도 간격 상한
+	outboxLeaseTTL      = 30 * time.Second // 리더 리스 유지 시간 (리더가 죽으면 이 시간 뒤 다른 Relay가 이어받음)
+	outboxStandby       = 2 * time.Second  // 리더가 아닌 Relay가 리스를 다시 시도하는 간격
+)
+
+func NewOutboxRelay(ob repository.OutboxRepository, kr *repository.KafkaRepository) *OutboxRelay {
+	// 여러 API 서버가 같은 그룹/리스를 두고 경쟁하므로 호스트명 + PID로 Consumer를 구분
+	host, _ := os.Hostname()
+	return &OutboxRelay{
+		Outbox:    ob,
+		KafkaRepo: kr,
+		Consumer:  fmt.Sprintf("%s-%d", host, os.Getpid()),
+	}
+}
+
+func (r *OutboxRelay) Start(ctx context.Context) {
+	if err := r.Outbox.EnsureOutboxGroup(ctx); err != nil {
+		log.Printf("❌ [Outbox] Consumer Group 생성 실패: %v", err)
+		return
+	}
+	fmt.Printf("📮 Outbox Relay 가동 (consumer: %s)\n", r.Consumer)
+
+	leader := false
+	lastClaim := time.Time{}
+	for {
+		select {
+		case <-ctx.Done():
+			if leader {
+				r.Outbox.ReleaseOutboxLease(context.Background(), r.Consumer)
+			}
+			fmt.Println("Outbox Relay를 종료합니다.")
+			return
+		default:
+		}
+
+		// 0. 리더 리스 획득/연장 (리더가 아니면 대기)
+		if !r.renewLease(ctx) {
+			if leader {
+				log.Printf("⚠️ [Outbox] 리더 리스를 잃어 발행을 멈춥니다. (consumer: %s)", r.Consumer)
+				leader = false
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(outboxStandby):
+			}
+			continue
+		}
+		if !leader {
+			// 이전 리더가 발행하지 못한 항목을 새 항목보다 먼저 발행해야 유저별 순서가 유지됨
+			log.Printf("👑 [Outbox] 발행 리더가 되었습니다. (consumer: %s)", r.Consumer)
+			if !r.drainPending(ctx) {
+				continue
+			}
+			leader = true
+			lastClaim = time.Now()
+		}
+
+		// 1. 리더가 되기 전 회수하지 못한 항목 정리 (리스가 끊긴 이전 리더가 뒤늦게 읽은 항목 등)
+		if time.Since(lastClaim) >= outboxClaimInterval {
+			lastClaim = time.Now()
+			stale, err := r.Outbox.ClaimStaleOutbox(ctx, r.Consumer, outboxMinIdle, outboxBatchSize)
+			if err != nil {
+				log.Printf("🚨 [Outbox] 미발행 항목 회수 실패: %v", err)
+			} else if len(stale) > 0 {
+				log.Printf("🔄 [Outbox] 미발행 항목 %d건 재발행", len(stale))
+				r.publish(ctx, stale)
+			}
+			if n, err := r.Outbox.OutboxBacklog(ctx); err == nil {
+				outboxBacklog.Set(float64(n))
+			}
+		}
+
+		// 2. 새 항목 발행
+		entries, err := r.Outbox.ReadOutbox(ctx, r.Consumer, outboxBatchSize, outboxBlock)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("🚨 [Outbox] 스트림 읽기 실패: %v", err)
+				time.Sleep(time.Second)
+			}
+			continue
+		}
+		r.publish(ctx, entries)
+	}
+}
+
+// renewLease: 리더 리스를 얻거나 연장 (실패하면 리더가 아님)
+func (r *OutboxRelay) renewLease(ctx context.Context) bool {
+	ok, err := r.Outbox.AcquireOutboxLease(ctx, r.Consumer, outboxLeaseTTL)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("🚨 [Outbox] 리더 리스 갱신 실패: %v", err)
+		}
+		return false
+	}
+	return ok
+}
+
+// drainPending: 어느 Relay에도 ACK 되지 않은 항목을 모두 회수해 발행 (리더가 된 직후, 새 항목을 읽기 전)
+func (r *OutboxRelay) drainPending(ctx context.Context) bool {
+	for {
+		pending, err := r.Outbox.ClaimStaleOutbox(ctx, r.Consumer, 0, outboxBatchSize)
+		if err != nil {
+			log.Printf("🚨 [Outbox] 미발행 항목 회수 실패: %v", err)
+			return false
+		}
+		if len(pending) == 0 {
+			return true
+		}
+		log.Printf("🔄 [Outbox] 이전 리더의 미발행 항목 %d건 재발행", len(pending))
+		if !r.publish(ctx, pending) {
+			return false
+		}
+	}
+}
+
+// publish: 배치를 Kafka에 발행하고 ACK 될 때까지 그 자리에서 재시도 (그동안 다음 항목을 읽지 않음)
+// 재시도 중 리더 리스를 잃으면 발행을 멈추고 false를 반환합니다. (항목은 pending으로 남아 새 리더가 회수)
+func (r *OutboxRelay) publish(ctx context.Context, entries []repository.OutboxEntry) bool {
+	if len(entries) == 0 {
+		return true
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+
+	backoff := outboxRetryMin
+	published := false
+	for {
+		if !published {
+			if err := r.KafkaRepo.PublishEntries(ctx, entries); err != nil {
+				outboxPublishFailures.Inc()
+				log.Printf("🚨 [Outbox] Kafka 발행 실패 (%d건, %s 후 재시도): %v", len(entries), backoff, err)
+			} else {
+				published = true
+			}
+		}
+		if published {
+			// ACK 실패 시 재발행될 수 있으나 워커 저장이 멱등하므로 중복 반영되지 않음 (발행 순서는 이미 지켜짐)
+			err := r.Outbox.AckOutbox(ctx, ids...)
+			if err == nil {
+				outboxPublished.Add(float64(len(entries)))
+				return true
+			}
+			log.Printf("⚠️ [Outbox] ACK 실패 (%d건, %s 후 재시도): %v", len(ids), backoff, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return false // ACK 되지 않은 항목은 pending으로 남아 다음 리더가 회수
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, outboxRetryMax)
+		if !published && !r.renewLease(ctx) {
+			return false
+		}
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"log"
-	"strings"
+	"sync"
+	"ticket-system/codec"
+	"ticket-system/payment"
 	"ticket-system/repository"
-	"time" // 재시도 대기를 위해 추가
+	"time"
 
 	"github.com/go-sql-driver/mysql"
 	"github.com/segmentio/kafka-go"
@@ -22,15 +25,52 @@ var (
 		Name: "mysql_save_success_total",
 		Help: "The total number of successful MySQL saves",
 	})
+
+	// 만료 이벤트 수신 횟수 (API의 ticket_reservations_expired_total과 비교용)
+	reservationExpired = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "reservation_expired_events_total",
+		Help: "The total number of reservation expiry events consumed",
+	})
+
+	// 구버전 문자열 포맷 메시지 수신 횟수 (0으로 유지되면 Legacy 디코딩 제거 가능)
+	legacyMessages = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "legacy_format_messages_total",
+		Help: "The total number of consumed messages in the legacy string format",
+	})
+
+	// DLQ/Parked 토픽으로 보낸 메시지 수 (destination 라벨로 구분)
+	dlqPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dlq_published_total",
+		Help: "The total number of failed messages published to the DLQ or parked topic",
+	}, []string{"destination"})
+)
+
+const (
+	// DefaultLanes: 기본 병렬 처리 레인 수
+	DefaultLanes = 8
+	// laneBuffer: 레인별 대기 메시지 수 (가득 차면 Fetch가 멈춰 처리 속도에 맞춰짐)
+	laneBuffer = 64
 )
 
 type PurchaseWorker struct {
 	Reader     *kafka.Reader
 	TicketRepo repository.TicketRepository
+	SeatRepo   repository.SeatRepository   // 지정석 판매/취소 상태 반영
 	KafkaRepo  *repository.KafkaRepository // DLQ 전송을 위한 레포지토리 추가
+	Retry      RetryPolicy                 // 실패 메시지의 재시도 단계
+	Lanes      int                         // 병렬 처리 레인 수 (같은 Key=유저의 메시지는 항상 같은 레인에서 순서대로 처리)
+	BatchSize  int                         // 레인별 일괄 처리 최대 메시지 수
+	BatchWait  time.Duration               // 배치를 채우기 위해 기다리는 최대 시간
+
+	brokers  []string
+	groupID  string
+	retrying retryKeys // 재시도 토픽에 메시지가 남아 있는 Key (같은 유저의 뒤 메시지를 같은 단계로 보내 순서 유지)
+
+	laneOnce  sync.Once
+	laneLocks []sync.Mutex // 레인별 잠금 (레인, 재시도 Consumer, DLQ 재처리가 같은 유저의 메시지를 동시에 반영하지 않도록)
 }
 
-func NewPurchaseWorker(brokers []string, topic string, groupID string, tr repository.TicketRepository, kr *repository.KafkaRepository) *PurchaseWorker {
+func NewPurchaseWorker(brokers []string, topic string, groupID string, tr repository.TicketRepository, sr repository.SeatRepository, kr *repository.KafkaRepository) *PurchaseWorker {
 	return &PurchaseWorker{
 		Reader: kafka.NewReader(kafka.ReaderConfig{
 			Brokers:  brokers,
@@ -40,141 +80,312 @@ func NewPurchaseWorker(brokers []string, topic string, groupID string, tr reposi
 			MaxBytes: 10e6,
 		}),
 		TicketRepo: tr,
+		SeatRepo:   sr,
 		KafkaRepo:  kr,
+		Retry:      DefaultRetryPolicy(),
+		Lanes:      DefaultLanes,
+		BatchSize:  DefaultBatchSize,
+		BatchWait:  DefaultBatchWait,
+		brokers:    brokers,
+		groupID:    groupID,
 	}
 }
 
 /*
  * Start: Kafka 이벤트를 소비하여 DB 작업을 수행하는 소비자 루프
  * 예매 성공과 취소 이벤트를 분기하여 처리합니다.
+ * - 메시지 Key(유저 ID)의 해시로 레인을 골라 병렬 처리하되, 같은 유저의 이벤트는 한 레인에서 순서대로 처리합니다.
+ * - 레인마다 BatchSize/BatchWait 기준으로 메시지를 모아 MySQL에 일괄 반영합니다.
+ * - offset은 자동 커밋하지 않고, DB 반영(또는 재시도/DLQ 토픽 이관)이 끝난 연속 구간까지만 커밋합니다.
+ * - 재시도 단계마다 별도의 Consumer를 띄워 실패한 메시지를 메인 루프와 분리해 처리합니다.
+ *   재시도 중인 유저의 뒤 메시지는 같은 재시도 단계 뒤에 줄을 세워 순서를 유지합니다. (retryKeys 참고)
  */
 
 func (w *PurchaseWorker) Start() {
-	fmt.Println("🚀 Kafka Consumer Worker 시작... [예매 저장/취소 처리 대기 중]")
+	w.initLanes()
+	if w.BatchSize <= 0 {
+		w.BatchSize = 1
+	}
+	fmt.Printf("🚀 Kafka Consumer Worker 시작... [예매 저장/취소 처리 대기 중, 레인 %d개]\n", w.Lanes)
+	for i, tier := range w.Retry.Tiers {
+		go w.startRetryConsumer(i, tier)
+	}
+
+	ctx := context.Background()
+	tracker := newOffsetTracker()
+	commits := make(chan kafka.Message, 1024)
+	go w.commitLoop(ctx, commits)
+
+	lanes := make([]chan kafka.Message, w.Lanes)
+	for i := range lanes {
+		lanes[i] = make(chan kafka.Message, laneBuffer)
+		go w.runLane(&w.laneLocks[i], lanes[i], tracker, commits)
+	}
 
 	for {
-		m, err := w.Reader.ReadMessage(context.Background())
+		m, err := w.Reader.FetchMessage(ctx)
 		if err != nil {
 			log.Printf("❌ 메시지 읽기 에러: %v", err)
 			continue
 		}
 
-		userID := string(m.Key)
-		messageVal := string(m.Value)
-
-		if strings.HasPrefix(messageVal, "CANCEL:") {
-			ticketName := strings.TrimPrefix(messageVal, "CANCEL:")
-
-			w.handleCancel(userID, ticketName, m)
-		} else {
+		tracker.track(m)
+		lanes[laneOf(m.Key, len(lanes))] <- m
+	}
+}
 
-			w.handleSave(userID, messageVal, m)
+// runLane: 레인에 배정된 메시지를 배치로 모아 순서대로 처리하고 커밋 가능한 offset을 전달
+// 재시도/DLQ 토픽마저 발행할 수 없으면 커밋하지 않고 같은 메시지를 다시 시도합니다. (이 레인만 대기)
+// 배치를 반영하는 동안 레인 잠금을 잡아 재시도 Consumer나 DLQ 재처리가 같은 유저의 메시지를 동시에 반영하지 않도록 합니다.
+func (w *PurchaseWorker) runLane(mu *sync.Mutex, lane <-chan kafka.Message, tracker *offsetTracker, commits chan<- kafka.Message) {
+	for first := range lane {
+		batch := w.collect(first, lane)
+		mu.Lock()
+		w.processBatch(batch)
+		mu.Unlock()
+		for _, m := range batch {
+			if last, ok := tracker.complete(m); ok {
+				commits <- last
+			}
 		}
 	}
 }
 
-func (w *PurchaseWorker) handleSave(userID string, ticketName string, rawMsg kafka.Message) {
-
-	time.Sleep(100 * time.Millisecond)
-
-	maxRetries := 3
-	var lastErr error
-
-	for i := 0; i < maxRetries; i++ {
-		saved, err := w.TicketRepo.SavePurchase(userID, ticketName)
-
-		if err == nil {
-			if !saved {
-				log.Printf("⚠️ [중복 저장 스킵] 유저 %s는 이미 처리되었습니다.", userID)
-			} else {
-				mysqlSaveSuccess.Inc()
-				fmt.Printf("✅ [저장 성공] 유저 %s의 티켓 정보 MySQL 저장 완료\n", userID)
-			}
-			return
+// commitLoop: 파티션별로 이전보다 큰 offset만 커밋 (레인 간 전달 순서가 뒤바뀌어도 offset이 되돌아가지 않도록)
+func (w *PurchaseWorker) commitLoop(ctx context.Context, commits <-chan kafka.Message) {
+	committed := make(map[int]int64)
+	for m := range commits {
+		if prev, ok := committed[m.Partition]; ok && m.Offset <= prev {
+			continue
+		}
+		if err := w.Reader.CommitMessages(ctx, m); err != nil {
+			log.Printf("❌ offset 커밋 실패 (partition %d, offset %d): %v", m.Partition, m.Offset, err)
+			continue
 		}
+		committed[m.Partition] = m.Offset
+	}
+}
 
-		lastErr = err
-		var mysqlErr *mysql.MySQLError
-		// 중복 키(1062)는 재시도할 필요가 없으므로 즉시 종료
-		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
-			log.Printf("⚠️ [중복 저장 스킵] 유저 %s는 이미 처리되었습니다.", userID)
-			return
+// initLanes: 레인 수를 확정하고 레인별 잠금을 준비 (Start 이전에 DLQ 재처리가 호출되어도 같은 레인 수를 사용)
+func (w *PurchaseWorker) initLanes() {
+	w.laneOnce.Do(func() {
+		if w.Lanes <= 0 {
+			w.Lanes = DefaultLanes
 		}
+		w.laneLocks = make([]sync.Mutex, w.Lanes)
+	})
+}
 
-		log.Printf("🚨 [저장 실패] 유저 %s (재시도 %d/%d): %v", userID, i+1, maxRetries, err)
-		time.Sleep(time.Second * 2)
-	}
+// withKey: Key가 속한 레인의 잠금을 잡고 fn 실행 (레인 밖에서 메시지를 반영할 때 같은 유저의 처리와 겹치지 않도록)
+func (w *PurchaseWorker) withKey(key []byte, fn func() error) error {
+	w.initLanes()
+	mu := &w.laneLocks[laneOf(key, len(w.laneLocks))]
+	mu.Lock()
+	defer mu.Unlock()
+	return fn()
+}
 
-	// [수정 포인트] image_6b283의 UnusedVar 에러 해결: 마지막 에러 정보를 로그에 활용
-	log.Printf("❌ [최종 실패] 유저 %s 메시지 DLQ 이동. 사유: %v", userID, lastErr)
+// laneOf: Key 해시로 레인 선택 (같은 유저는 항상 같은 레인)
+func laneOf(key []byte, n int) int {
+	h := fnv.New32a()
+	h.Write(key)
+	return int(h.Sum32() % uint32(n))
+}
 
-	// DLQ 전송 시 에러 사유를 포함해서 전송
-	err := w.KafkaRepo.PublishToTopic(context.Background(), "ticket-dlq-topic", rawMsg.Key, rawMsg.Value)
+// handleMessage: 메시지를 codec.Event로 해석한 뒤 이벤트 종류별로 처리
+// JSON Envelope과 구버전 문자열 포맷("CANCEL:{ticket}" 등)을 모두 지원합니다.
+// DB 반영에 실패하면 기다리지 않고 재시도 토픽으로 넘기며, 해석할 수 없는 메시지는 사유와 함께 바로 DLQ로 이동합니다.
+// 에러는 재시도/DLQ 토픽 발행까지 실패하여 메시지를 어디에도 남기지 못한 경우에만 반환합니다. (offset 커밋 금지)
+func (w *PurchaseWorker) handleMessage(m kafka.Message) error {
+	ev, err := codec.Decode(m.Key, m.Value, repository.Headers(m))
 	if err != nil {
-		log.Printf("💣 [치명적 에러] DLQ 전송 실패: %v", err)
+		// 해석할 수 없는 메시지(상위 스키마 버전 등)는 버리지 않고 DLQ에 보관
+		log.Printf("⚠️ [메시지 형식 오류] %q: %v", string(m.Value), err)
+		return w.sendToDLQ("형식 오류", m, err)
+	}
+	if ev.Legacy {
+		legacyMessages.Inc()
 	}
-}
 
-func (w *PurchaseWorker) handleCancel(userID string, ticketName string, rawMsg kafka.Message) {
-	maxRetries := 3
-	var lastErr error
+	if err := w.apply(ev, repository.MessageAudit(repository.ActorWorker, m)); err != nil {
+		action := actionName(ev.Type)
+		log.Printf("🚨 [%s 실패] 유저 %s: %v", action, ev.UserID, err)
+		return w.retryOrDLQ(action, m, err)
+	}
+	return nil
+}
 
-	for i := 0; i < maxRetries; i++ {
-		err := w.TicketRepo.DeletePurchase(userID, ticketName)
+// Replay: DLQ 메시지를 한 번 재처리 (실패해도 DLQ로 다시 보내지 않고 에러만 반환)
+// 재처리로 인한 변경은 감사 로그에 dlq-replay 주체로 기록됩니다.
+// 같은 유저의 레인 잠금을 잡고 반영하므로, 레인이나 재시도 Consumer가 처리 중인 메시지와 동시에 반영되지 않습니다.
+func (w *PurchaseWorker) Replay(m kafka.Message) error {
+	ev, err := codec.Decode(m.Key, m.Value, repository.Headers(m))
+	if err != nil {
+		return err
+	}
+	return w.withKey(m.Key, func() error {
+		return w.apply(ev, repository.MessageAudit(repository.ActorDLQReplay, m))
+	})
+}
 
-		if err == nil {
-			fmt.Printf("🗑️ [취소 성공] 유저 %s의 구매 내역 DB 삭제 완료\n", userID)
-			return // 성공 시 종료
+// apply: 이벤트 하나를 DB에 반영 (같은 이벤트가 다시 와도 결과가 같도록 멱등하게 처리)
+// src는 감사 로그에 남길 변경 주체와 원본 메시지 위치입니다.
+func (w *PurchaseWorker) apply(ev *codec.Event, src repository.AuditSource) error {
+	switch ev.Type {
+	case codec.TypeSeatCancel:
+		return w.handleSeatCancel(ev, src)
+	case codec.TypeSeatPurchase:
+		return w.handleSeatSave(ev, src)
+	case codec.TypeExpire:
+		// 만료된 홀드는 구매 내역으로 저장된 적이 없으므로 결제 대기 중이던 주문만 취소 처리
+		if err := w.TicketRepo.CancelPendingOrder(ev.HoldID); err != nil {
+			return err
 		}
+		reservationExpired.Inc()
+		log.Printf("⌛ [홀드 만료] 유저 %s의 %s 예약(%s)이 만료되어 재고가 반환되었습니다.", ev.UserID, ev.TicketName, ev.HoldID)
+		return nil
+	case codec.TypeTransfer:
+		return w.handleTransfer(ev, src)
+	case codec.TypeCancel:
+		return w.handleCancel(ev, src)
+	case codec.TypePurchase:
+		return w.handleSave(ev, src)
+	default:
+		log.Printf("⚠️ [알 수 없는 이벤트] type=%s trace=%s", ev.Type, ev.TraceID)
+		return nil
+	}
+}
 
-		lastErr = err
-		log.Printf("🚨 [취소 실패] 유저 %s (재시도 %d/%d): %v", userID, i+1, maxRetries, err)
-		time.Sleep(time.Second * 2) // 2초 대기
+func actionName(typ codec.EventType) string {
+	switch typ {
+	case codec.TypeSeatCancel:
+		return "좌석 취소"
+	case codec.TypeSeatPurchase:
+		return "좌석 저장"
+	case codec.TypeCancel:
+		return "취소"
+	case codec.TypeTransfer:
+		return "양도"
+	default:
+		return "저장"
 	}
+}
 
-	// 3번 모두 실패 시 DLQ로 전송
-	log.Printf("❌ [취소 최종 실패] 유저 %s의 취소 메시지 DLQ 이동. 사유: %v", userID, lastErr)
+// purchaseFromEvent: 이벤트의 주문 수량과 결제 정보(payment_id, amount)를 포함한 구매 내역 생성
+// 결제 연동 이전에 발행된 메시지는 결제 정보가 없으므로 결제 정보 없이 저장됩니다.
+func purchaseFromEvent(ev *codec.Event, src repository.AuditSource) *repository.Purchase {
+	purchase := &repository.Purchase{
+		UserID:     ev.UserID,
+		TicketName: ev.TicketName,
+		Quantity:   max(ev.Quantity, 1),
+		OrderID:    ev.OrderID,
+		SeatIDs:    ev.SeatIDs,
+		PaymentID:  ev.PaymentID,
+		Audit:      src,
+	}
+	if purchase.PaymentID != "" {
+		purchase.PaymentStatus = payment.StatusCaptured
+		purchase.Amount = ev.Amount
+	}
+	return purchase
+}
 
-	// DLQ 토픽으로 전송
-	err := w.KafkaRepo.PublishToTopic(context.Background(), "ticket-dlq-topic", rawMsg.Key, rawMsg.Value)
+func (w *PurchaseWorker) handleSave(ev *codec.Event, src repository.AuditSource) error {
+	saved, err := w.TicketRepo.SavePurchase(purchaseFromEvent(ev, src))
 	if err != nil {
-		log.Printf("💣 [치명적 에러] 취소 DLQ 전송 실패: %v", err)
+		var mysqlErr *mysql.MySQLError
+		// 중복 키(1062)는 재시도할 필요가 없으므로 즉시 종료
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			log.Printf("⚠️ [중복 저장 스킵] 유저 %s는 이미 처리되었습니다.", ev.UserID)
+			return nil
+		}
+		return err
+	}
+
+	if !saved {
+		log.Printf("⚠️ [중복 저장 스킵] 유저 %s는 이미 처리되었습니다.", ev.UserID)
+	} else {
+		mysqlSaveSuccess.Inc()
+		fmt.Printf("✅ [저장 성공] 유저 %s의 티켓 정보 MySQL 저장 완료\n", ev.UserID)
 	}
+	return nil
 }
 
-func (w *PurchaseWorker) ProcessDLQ() {
-	log.Println("🛠️ [DLQ 복구] 저장 실패했던 데이터를 다시 처리합니다...")
+func (w *PurchaseWorker) handleCancel(ev *codec.Event, src repository.AuditSource) error {
+	if len(ev.ItemIDs) > 0 {
+		return w.handleItemCancel(ev, src)
+	}
+	if err := w.TicketRepo.DeletePurchase(ev.UserID, ev.TicketName, ev.PaymentIDs, src); err != nil {
+		return err
+	}
+	fmt.Printf("🗑️ [취소 성공] 유저 %s의 구매 내역 DB 취소 처리 완료\n", ev.UserID)
+	return nil
+}
 
-	// 복구용 리더 (그룹 ID를 다르게 해서 처음부터 읽음)
-	dlqReader := kafka.NewReader(kafka.ReaderConfig{
-		Brokers:     w.Reader.Config().Brokers,
-		Topic:       "ticket-dlq-topic",
-		GroupID:     "recovery-group-v1",
-		StartOffset: kafka.FirstOffset,
-	})
-	defer dlqReader.Close()
+// handleItemCancel: 주문 항목 단위 취소 → 항목 REFUNDED + 구매 수량 차감 + 재고 복구 (이력은 유지)
+func (w *PurchaseWorker) handleItemCancel(ev *codec.Event, src repository.AuditSource) error {
+	cancelled, err := w.TicketRepo.CancelOrderItems(ev.OrderID, ev.ItemIDs, src)
+	if err != nil {
+		return err
+	}
+	if cancelled == 0 {
+		log.Printf("⚠️ [중복 취소 스킵] 주문 %s 항목 %v는 이미 처리되었습니다.", ev.OrderID, ev.ItemIDs)
+		return nil
+	}
+	fmt.Printf("🗑️ [부분 취소 성공] 유저 %s 주문 %s 항목 %d건 MySQL 반영 완료\n", ev.UserID, ev.OrderID, cancelled)
+	return nil
+}
 
-	for {
-		// 더 이상 읽을 메시지가 없으면 3초 뒤 종료되도록 타임아웃 설정
-		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-		m, err := dlqReader.ReadMessage(ctx)
-		cancel()
+// handleTransfer: 주문 양도 → 주문/구매 내역의 소유자를 받는 유저로 변경 (재고 변화 없음)
+func (w *PurchaseWorker) handleTransfer(ev *codec.Event, src repository.AuditSource) error {
+	transferred, err := w.TicketRepo.TransferOrder(ev.OrderID, ev.UserID, ev.ToUserID, src)
+	if err != nil {
+		return err
+	}
+	if !transferred {
+		log.Printf("⚠️ [중복 양도 스킵] 주문 %s는 이미 유저 %s에게 양도되었습니다.", ev.OrderID, ev.ToUserID)
+		return nil
+	}
+	fmt.Printf("🎁 [양도 성공] 유저 %s → %s 주문 %s MySQL 반영 완료\n", ev.UserID, ev.ToUserID, ev.OrderID)
+	return nil
+}
 
-		if err != nil {
-			log.Println("✅ [DLQ 복구 완료] 모든 유실 데이터를 처리했거나 남은 데이터가 없습니다.")
-			return
-		}
+// handleSeatSave: 지정석 구매 확정 → 구매 내역 저장 + 좌석 SOLD 처리
+func (w *PurchaseWorker) handleSeatSave(ev *codec.Event, src repository.AuditSource) error {
+	if _, err := w.TicketRepo.SavePurchase(purchaseFromEvent(ev, src)); err != nil {
+		return err
+	}
+	if err := w.SeatRepo.MarkSeatsSold(ev.SeatIDs, ev.UserID); err != nil {
+		return err
+	}
+	mysqlSaveSuccess.Inc()
+	fmt.Printf("✅ [좌석 저장 성공] 유저 %s 좌석 %v MySQL 반영 완료\n", ev.UserID, ev.SeatIDs)
+	return nil
+}
 
-		userID := string(m.Key)
-		messageVal := string(m.Value)
+// handleSeatCancel: 지정석 취소 → 좌석을 다시 AVAILABLE로 변경하고 좌석 주문 항목을 환불 처리
+func (w *PurchaseWorker) handleSeatCancel(ev *codec.Event, src repository.AuditSource) error {
+	if err := w.SeatRepo.ReleaseSeats(ev.SeatIDs, ev.UserID); err != nil {
+		return err
+	}
+	if err := w.TicketRepo.CancelSeatItems(ev.UserID, ev.TicketName, ev.SeatIDs, src); err != nil {
+		return err
+	}
+	fmt.Printf("🗑️ [좌석 취소 성공] 유저 %s 좌석 %v (%s) MySQL 반영 완료\n", ev.UserID, ev.SeatIDs, ev.TicketName)
+	return nil
+}
 
-		if strings.HasPrefix(messageVal, "CANCEL:") {
-			ticketName := strings.TrimPrefix(messageVal, "CANCEL:")
-			log.Printf("🔄 [DLQ 취소 재처리] 유저: %s", userID)
-			w.handleCancel(userID, ticketName, m)
-		} else {
-			log.Printf("🔄 [DLQ 저장 재처리] 유저: %s", userID)
-			w.handleSave(userID, messageVal, m)
-		}
+// sendToDLQ: 원본 메시지를 실패 이력(원본 위치, 사유, 시도 횟수, 실패 시각 헤더)과 함께 DLQ로 이동
+func (w *PurchaseWorker) sendToDLQ(action string, m kafka.Message, cause error) error {
+	reason := fmt.Sprintf("%s: %v", action, cause)
+	topic, err := w.KafkaRepo.PublishToDLQ(context.Background(), m, reason)
+	if err != nil {
+		log.Printf("💣 [치명적 에러] %s DLQ 전송 실패: %v", action, err)
+		return err
+	}
+	dlqPublished.WithLabelValues(topic).Inc()
+	if topic == repository.ParkedTopic {
+		log.Printf("🅿️ [격리] %s 메시지가 %d회 실패하여 %s로 이동했습니다.", action, repository.MaxDLQAttempts, topic)
 	}
+	return nil
 }
@@ -0,0 +1,470 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"ticket-system/payment"
+	"ticket-system/repository"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// 재고 차이 (실제 값 - 기대 값), store: redis / mysql
+	reconcileStockDrift = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "reconcile_stock_drift",
+		Help: "Difference between actual and expected stock per ticket",
+	}, []string{"ticket", "store"})
+
+	// Redis 구매자 명단에는 있지만 purchases 행이 없는 유저 수
+	reconcileOrphanMembers = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "reconcile_orphan_members",
+		Help: "Purchased set members without a purchases row",
+	}, []string{"ticket"})
+
+	// purchases 행은 있지만 Redis 구매자 명단에 없는 유저 수
+	reconcileMissingMembers = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "reconcile_missing_members",
+		Help: "Purchases rows whose user is missing from the purchased set",
+	}, []string{"ticket"})
+
+	// 양쪽에 모두 있지만 Redis 구매 수량과 purchases 수량 합이 다른 유저 수
+	reconcileQuantityDrift = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "reconcile_quantity_drift_users",
+		Help: "Users whose purchased quantity differs between Redis and purchases rows",
+	}, []string{"ticket"})
+
+	reconcileRepairs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "reconcile_repairs_total",
+		Help: "The total number of drift repairs applied by the reconciler",
+	}, []string{"ticket", "kind"})
+
+	reconcileLastRun = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "reconcile_last_run_timestamp_seconds",
+		Help: "Unix time of the last completed reconciliation",
+	})
+)
+
+/*
+ * Reconciler: Redis(판매 시점의 기준 데이터)와 MySQL(워커가 뒤따라 반영)의 정합성 검사
+ * - 재고: Redis ticket_stock = capacity - MySQL 판매 수량 - 진행 중인 홀드 수량
+ *         MySQL tickets.stock = capacity - MySQL 판매 수량
+ * - 구매자: purchased_users 집합 ↔ purchases 행, 유저별 Redis 구매 수량 ↔ purchases 수량 합
+ * Kafka/Outbox에 아직 처리되지 않은 메시지가 있으면 일시적인 차이가 생기므로,
+ * 차이가 발견되면 Settle 만큼 기다렸다가 다시 검사해 두 번 모두 나타난 차이만 보고합니다.
+ */
+type Reconciler struct {
+	LockRepo   repository.LockRepository
+	TicketRepo repository.TicketRepository
+	SeatRepo   repository.SeatRepository
+	Settle     time.Duration
+	Repair     bool
+}
+
+// DriftReport: 이벤트 하나의 정합성 검사 결과
+type DriftReport struct {
+	Ticket          string      `json:"ticket"`
+	Reserved        bool        `json:"reserved"`
+	RedisStock      int         `json:"redis_stock"`
+	ExpectedStock   int         `json:"expected_stock"`
+	DBStock         int         `json:"db_stock"`
+	ExpectedDBStock int         `json:"expected_db_stock"`
+	Holds           int         `json:"holds"`
+	OrphanMembers   []string    `json:"orphan_members"`  // Redis 구매자인데 purchases 행 없음
+	MissingMembers  []string    `json:"missing_members"` // purchases 행은 있는데 Redis 구매자 아님
+	QuantityDrift   []UserDrift `json:"quantity_drift"`  // 양쪽에 있지만 구매 수량이 다름
+}
+
+// UserDrift: 유저 한 명의 Redis 구매 수량과 purchases 수량 합
+type UserDrift struct {
+	UserID        string `json:"user_id"`
+	RedisQuantity int    `json:"redis_quantity"`
+	DBQuantity    int    `json:"db_quantity"`
+}
+
+func (d *DriftReport) StockDrift() int   { return d.RedisStock - d.ExpectedStock }
+func (d *DriftReport) DBStockDrift() int { return d.DBStock - d.ExpectedDBStock }
+
+func (d *DriftReport) HasDrift() bool {
+	return d.StockDrift() != 0 || d.DBStockDrift() != 0 || len(d.OrphanMembers) > 0 || len(d.MissingMembers) > 0 || len(d.QuantityDrift) > 0
+}
+
+func NewReconciler(lr repository.LockRepository, tr repository.TicketRepository, sr repository.SeatRepository) *Reconciler {
+	return &Reconciler{
+		LockRepo:   lr,
+		TicketRepo: tr,
+		SeatRepo:   sr,
+		Settle:     10 * time.Second,
+	}
+}
+
+// Run: 전체 이벤트를 검사하고 (Repair 모드면 복구 후) 차이가 확인된 이벤트의 보고서를 반환
+func (rc *Reconciler) Run(ctx context.Context) ([]DriftReport, error) {
+	events, err := rc.TicketRepo.ListTickets()
+	if err != nil {
+		return nil, err
+	}
+
+	first := make(map[string]*DriftReport)
+	for i := range events {
+		report, err := rc.check(ctx, &events[i])
+		if err != nil {
+			return nil, fmt.Errorf("%s 검사 실패: %w", events[i].Name, err)
+		}
+		if report.HasDrift() {
+			first[report.Ticket] = report
+		}
+		rc.export(report)
+	}
+
+	// 일시적인 차이(처리 중인 메시지)를 걸러내기 위해 재검사
+	if len(first) > 0 && rc.Settle > 0 {
+		log.Printf("🔍 [Reconciler] %d개 이벤트에서 차이 발견, %s 후 재검사합니다.", len(first), rc.Settle)
+		select {
+		case <-time.After(rc.Settle):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	var reports []DriftReport
+	for i := range events {
+		ev := &events[i]
+		prev, ok := first[ev.Name]
+		if !ok {
+			continue
+		}
+		report, err := rc.check(ctx, ev)
+		if err != nil {
+			return nil, fmt.Errorf("%s 재검사 실패: %w", ev.Name, err)
+		}
+		stable(prev, report)
+		rc.export(report)
+		if !report.HasDrift() {
+			continue
+		}
+
+		if rc.Repair {
+			if err := rc.repair(ctx, ev, report); err != nil {
+				return nil, fmt.Errorf("%s 복구 실패: %w", ev.Name, err)
+			}
+		}
+		reports = append(reports, *report)
+	}
+
+	reconcileLastRun.SetToCurrentTime()
+	return reports, nil
+}
+
+// check: 이벤트 하나의 Redis/MySQL 상태를 비교
+func (rc *Reconciler) check(ctx context.Context, ev *repository.Ticket) (*DriftReport, error) {
+	report := &DriftReport{Ticket: ev.Name, Reserved: ev.Reserved, DBStock: ev.Stock}
+
+	var err error
+	if report.RedisStock, err = rc.LockRepo.GetStock(ctx, ev.Name); err != nil {
+		return nil, err
+	}
+	if report.Holds, err = rc.LockRepo.CountHolds(ctx, ev.Name); err != nil {
+		return nil, err
+	}
+	members, err := rc.LockRepo.GetPurchasedQuantities(ctx, ev.Name)
+	if err != nil {
+		return nil, err
+	}
+	purchases, err := rc.TicketRepo.ListPurchases(ev.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// 판매 수량: 지정석은 SOLD 좌석 수, 일반 이벤트는 구매 내역의 수량 합
+	sold := 0
+	for _, p := range purchases {
+		sold += p.Quantity
+	}
+	if ev.Reserved {
+		if sold, err = rc.SeatRepo.CountSoldSeats(ev.ID); err != nil {
+			return nil, err
+		}
+	}
+	report.ExpectedDBStock = ev.Capacity - sold
+	report.ExpectedStock = ev.Capacity - sold - report.Holds
+
+	rows := make(map[string]int, len(purchases))
+	for _, p := range purchases {
+		rows[p.UserID] += p.Quantity
+	}
+	for userID, quantity := range members {
+		dbQuantity, ok := rows[userID]
+		switch {
+		case !ok:
+			report.OrphanMembers = append(report.OrphanMembers, userID)
+		case dbQuantity != quantity:
+			report.QuantityDrift = append(report.QuantityDrift, UserDrift{UserID: userID, RedisQuantity: quantity, DBQuantity: dbQuantity})
+		}
+	}
+	for userID := range rows {
+		if _, ok := members[userID]; !ok {
+			report.MissingMembers = append(report.MissingMembers, userID)
+		}
+	}
+	return report, nil
+}
+
+// stable: 첫 검사와 재검사에서 모두 나타난 차이만 남김
+func stable(prev *DriftReport, cur *DriftReport) {
+	if prev.StockDrift() == 0 {
+		cur.ExpectedStock = cur.RedisStock
+	}
+	if prev.DBStockDrift() == 0 {
+		cur.ExpectedDBStock = cur.DBStock
+	}
+	cur.OrphanMembers = intersect(prev.OrphanMembers, cur.OrphanMembers)
+	cur.MissingMembers = intersect(prev.MissingMembers, cur.MissingMembers)
+
+	// 수량 차이는 같은 값이 두 번 나타난 경우만 (처리 중인 메시지로 수량이 바뀌는 중이면 제외)
+	seen := make(map[UserDrift]bool, len(prev.QuantityDrift))
+	for _, d := range prev.QuantityDrift {
+		seen[d] = true
+	}
+	var drift []UserDrift
+	for _, d := range cur.QuantityDrift {
+		if seen[d] {
+			drift = append(drift, d)
+		}
+	}
+	cur.QuantityDrift = drift
+}
+
+func intersect(a []string, b []string) []string {
+	seen := make(map[string]bool, len(a))
+	for _, v := range a {
+		seen[v] = true
+	}
+	var out []string
+	for _, v := range b {
+		if seen[v] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+/*
+ * repair: 확인된 차이를 복구
+ * - Orphan Member / Redis 수량이 더 많음: Redis 판매가 기준이므로 purchases 행이 없는 결제 완료 주문을
+ *   원래 주문 ID/결제 ID로 저장 (지정석은 좌석도 SOLD 처리)
+ *   결제 ID가 같으면 워커가 뒤늦게 같은 주문을 저장해도 UNIQUE KEY로 한 번만 반영되며,
+ *   결제 정보가 남은 주문이 없으면 중복 저장을 막기 위해 자동 복구하지 않습니다. (수동 확인)
+ * - Missing Member / MySQL 수량이 더 많음: 환불 완료된 행은 취소가 유실된 것이므로 취소 처리,
+ *   남은 수량이 Redis보다 많으면 Redis 데이터가 유실된 것이므로 차이만큼 구매자 명단에 다시 추가 (지정석은 수동 확인)
+ * - 재고: 구매자 복구 후 다시 계산한 기대 값으로 Redis(증감)와 MySQL을 보정
+ *         (수동 확인이 남은 유저가 있으면 기대 값이 판매분을 빠뜨리므로 Redis 재고는 그대로 둠)
+ */
+func (rc *Reconciler) repair(ctx context.Context, ev *repository.Ticket, report *DriftReport) error {
+	quantities, err := rc.LockRepo.GetPurchasedQuantities(ctx, ev.Name)
+	if err != nil {
+		return err
+	}
+	// 자동 복구하지 못한 유저가 있으면 재고 기대 값이 판매분을 빠뜨리므로 Redis 재고는 보정하지 않음
+	resolved := true
+	for _, userID := range report.OrphanMembers {
+		ok, err := rc.restorePurchases(ev, userID, quantities[userID])
+		if err != nil {
+			return err
+		}
+		resolved = resolved && ok
+	}
+	for _, userID := range report.MissingMembers {
+		ok, err := rc.restoreMember(ctx, ev, userID, 0)
+		if err != nil {
+			return err
+		}
+		resolved = resolved && ok
+	}
+	for _, d := range report.QuantityDrift {
+		var ok bool
+		if d.RedisQuantity > d.DBQuantity {
+			ok, err = rc.restorePurchases(ev, d.UserID, d.RedisQuantity-d.DBQuantity)
+		} else {
+			ok, err = rc.restoreMember(ctx, ev, d.UserID, d.RedisQuantity)
+		}
+		if err != nil {
+			return err
+		}
+		resolved = resolved && ok
+	}
+
+	// 구매자 복구 결과를 반영해 재고 기대 값 재계산
+	fresh, err := rc.TicketRepo.GetTicket(ev.ID)
+	if err != nil {
+		return err
+	}
+	after, err := rc.check(ctx, fresh)
+	if err != nil {
+		return err
+	}
+	if delta := after.ExpectedStock - after.RedisStock; delta != 0 && !resolved {
+		log.Printf("⚠️ [Reconciler] %s 수동 확인이 필요한 유저가 있어 Redis 재고(%d → %d)를 보정하지 않습니다.", ev.Name, after.RedisStock, after.ExpectedStock)
+	} else if delta != 0 {
+		if _, err := rc.LockRepo.AdjustStock(ctx, ev.Name, delta); err != nil {
+			return err
+		}
+		reconcileRepairs.WithLabelValues(ev.Name, "redis_stock").Inc()
+		log.Printf("🔧 [Reconciler] %s Redis 재고 %d → %d", ev.Name, after.RedisStock, after.ExpectedStock)
+	}
+	if after.DBStockDrift() != 0 {
+		if err := rc.TicketRepo.SetStock(ev.Name, after.ExpectedDBStock); err != nil {
+			return err
+		}
+		reconcileRepairs.WithLabelValues(ev.Name, "mysql_stock").Inc()
+		log.Printf("🔧 [Reconciler] %s MySQL 재고 %d → %d", ev.Name, after.DBStock, after.ExpectedDBStock)
+	}
+	return nil
+}
+
+// restorePurchases: purchases 행이 없는 결제 완료 주문을 원래 주문 ID/결제 ID로 저장
+// 오래된 주문부터 Redis에만 있는 수량(missing)을 넘지 않는 만큼만 복구합니다.
+func (rc *Reconciler) restorePurchases(ev *repository.Ticket, userID string, missing int) (bool, error) {
+	orders, err := rc.TicketRepo.ListOrders(userID, ev.Name)
+	if err != nil {
+		return false, err
+	}
+	saved := make(map[string]bool)
+	purchases, err := rc.TicketRepo.ListUserPurchases(userID, ev.Name)
+	if err != nil && !errors.Is(err, repository.ErrPurchaseNotFound) {
+		return false, err
+	}
+	for _, p := range purchases {
+		saved[p.PaymentID] = true
+	}
+
+	restored := 0
+	for i := len(orders) - 1; i >= 0; i-- { // ListOrders는 최신 주문부터 반환
+		o := orders[i]
+		if o.PaymentID == "" || saved[o.PaymentID] || (o.Status != repository.OrderPending && o.Status != repository.OrderConfirmed) {
+			continue
+		}
+		purchase := &repository.Purchase{
+			UserID:        userID,
+			TicketName:    ev.Name,
+			OrderID:       o.ID,
+			PaymentID:     o.PaymentID,
+			PaymentStatus: payment.StatusCaptured,
+			Amount:        o.Amount,
+			Audit:         repository.AuditSource{Actor: repository.ActorReconciler},
+		}
+		for _, item := range o.Items {
+			if item.Status != repository.OrderPending && item.Status != repository.OrderConfirmed {
+				continue
+			}
+			purchase.Quantity++
+			if item.SeatID != 0 {
+				purchase.SeatIDs = append(purchase.SeatIDs, item.SeatID)
+			}
+		}
+		if purchase.Quantity == 0 || restored+purchase.Quantity > missing {
+			continue
+		}
+		if _, err := rc.TicketRepo.SavePurchase(purchase); err != nil {
+			return false, err
+		}
+		if ev.Reserved && len(purchase.SeatIDs) > 0 {
+			if err := rc.SeatRepo.MarkSeatsSold(purchase.SeatIDs, userID); err != nil {
+				return false, err
+			}
+		}
+		restored += purchase.Quantity
+		reconcileRepairs.WithLabelValues(ev.Name, "insert_purchase").Inc()
+		log.Printf("🔧 [Reconciler] %s 유저 %s 주문 %s 구매 내역 복구", ev.Name, userID, o.ID)
+	}
+	if restored < missing {
+		log.Printf("⚠️ [Reconciler] %s 유저 %s 결제 정보가 남은 주문이 없어 %d매를 자동 복구하지 않습니다. (수동 확인 필요)", ev.Name, userID, missing-restored)
+		return false, nil
+	}
+	return true, nil
+}
+
+// restoreMember: MySQL 구매 내역을 기준으로 Redis 구매자 명단/수량 복구 (redisQuantity: 현재 Redis 구매 수량)
+func (rc *Reconciler) restoreMember(ctx context.Context, ev *repository.Ticket, userID string, redisQuantity int) (bool, error) {
+	purchases, err := rc.TicketRepo.ListUserPurchases(userID, ev.Name)
+	if err != nil {
+		return false, err
+	}
+	var refunded []string
+	quantity := 0
+	for _, p := range purchases {
+		if p.PaymentStatus == payment.StatusRefunded {
+			refunded = append(refunded, p.PaymentID)
+			continue
+		}
+		quantity += p.Quantity
+	}
+	if len(refunded) > 0 {
+		if err := rc.TicketRepo.DeletePurchase(userID, ev.Name, refunded, repository.AuditSource{Actor: repository.ActorReconciler}); err != nil {
+			return false, err
+		}
+		reconcileRepairs.WithLabelValues(ev.Name, "delete_purchase").Inc()
+		log.Printf("🔧 [Reconciler] %s 유저 %s 환불 완료 구매 내역 취소 처리", ev.Name, userID)
+	}
+
+	missing := quantity - redisQuantity
+	switch {
+	case missing <= 0:
+		return true, nil
+	case ev.Reserved:
+		log.Printf("⚠️ [Reconciler] %s 유저 %s 좌석 정보가 Redis에 없어 자동 복구하지 않습니다. (수동 확인 필요)", ev.Name, userID)
+		return false, nil
+	}
+	if err := rc.LockRepo.AddPurchased(ctx, ev.Name, userID, missing); err != nil {
+		return false, err
+	}
+	err = rc.TicketRepo.AppendAudit(&repository.AuditLog{
+		UserID:     userID,
+		TicketName: ev.Name,
+		Action:     repository.AuditRepair,
+		Quantity:   missing,
+		Actor:      repository.ActorReconciler,
+		Detail:     "Redis 구매자 명단 복구",
+	})
+	if err != nil {
+		return false, err
+	}
+	reconcileRepairs.WithLabelValues(ev.Name, "add_member").Inc()
+	log.Printf("🔧 [Reconciler] %s 유저 %s 구매자 명단 복구 (+%d)", ev.Name, userID, missing)
+	return true, nil
+}
+
+// Start: interval 주기로 검사를 반복 (메트릭 수집용 상시 실행 모드)
+func (rc *Reconciler) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		reports, err := rc.Run(ctx)
+		if err != nil {
+			log.Printf("❌ [Reconciler] 검사 실패: %v", err)
+		}
+		for _, r := range reports {
+			log.Printf("⚠️ [Reconciler] %s 재고 차이 redis=%d mysql=%d, orphan=%d, missing=%d, quantity=%d",
+				r.Ticket, r.StockDrift(), r.DBStockDrift(), len(r.OrphanMembers), len(r.MissingMembers), len(r.QuantityDrift))
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (rc *Reconciler) export(report *DriftReport) {
+	reconcileStockDrift.WithLabelValues(report.Ticket, "redis").Set(float64(report.StockDrift()))
+	reconcileStockDrift.WithLabelValues(report.Ticket, "mysql").Set(float64(report.DBStockDrift()))
+	reconcileOrphanMembers.WithLabelValues(report.Ticket).Set(float64(len(report.OrphanMembers)))
+	reconcileMissingMembers.WithLabelValues(report.Ticket).Set(float64(len(report.MissingMembers)))
+	reconcileQuantityDrift.WithLabelValues(report.Ticket).Set(float64(len(report.QuantityDrift)))
+}
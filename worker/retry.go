@@ -0,0 +1,216 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"strconv"
+	"sync"
+	"ticket-system/repository"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/segmentio/kafka-go"
+)
+
+// 재시도 토픽으로 넘긴 메시지 수 (topic 라벨로 단계 구분)
+var retryScheduled = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "retry_scheduled_total",
+	Help: "The total number of failed messages scheduled on a retry topic",
+}, []string{"topic"})
+
+// 앞선 메시지가 재시도 중이어서 순서 보장을 위해 재시도 토픽으로 옮긴 메시지 수
+var retryDiverted = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "retry_diverted_total",
+	Help: "The total number of messages moved behind an earlier message of the same key on a retry topic",
+}, []string{"topic"})
+
+// RetryPolicy: 단계별 재시도 토픽과 대기 시간
+// Jitter는 대기 시간에 더하는 무작위 비율(0.2면 ±20%)로, MySQL 복구 직후 재시도가 한꺼번에 몰리는 것을 막습니다.
+type RetryPolicy struct {
+	Tiers  []repository.RetryTier
+	Jitter float64
+}
+
+// DefaultRetryPolicy: 5초 → 1분 → DLQ, ±20% Jitter
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Tiers:  repository.DefaultRetryTiers,
+		Jitter: 0.2,
+	}
+}
+
+// delay: 단계 대기 시간에 Jitter를 적용
+func (p RetryPolicy) delay(tier repository.RetryTier) time.Duration {
+	if p.Jitter <= 0 {
+		return tier.Delay
+	}
+	spread := float64(tier.Delay) * p.Jitter
+	return tier.Delay + time.Duration((rand.Float64()*2-1)*spread)
+}
+
+// retryOrDLQ: 실패한 메시지를 다음 재시도 단계로 넘기고, 모든 단계를 거쳤으면 DLQ로 이동
+// Consumer 루프는 대기하지 않고 바로 다음 메시지를 처리합니다.
+func (w *PurchaseWorker) retryOrDLQ(action string, m kafka.Message, cause error) error {
+	attempt := repository.RetryAttempt(m)
+	if attempt >= len(w.Retry.Tiers) {
+		log.Printf("❌ [%s 최종 실패] 재시도 %d단계 모두 실패하여 DLQ 이동. 사유: %v", action, attempt, cause)
+		return w.sendToDLQ(action, m, cause)
+	}
+
+	tier := w.Retry.Tiers[attempt]
+	notBefore := time.Now().Add(w.Retry.delay(tier))
+	reason := fmt.Sprintf("%s: %v", action, cause)
+	if err := w.KafkaRepo.PublishToRetry(context.Background(), m, tier.Topic, notBefore, reason); err != nil {
+		// 재시도 토픽 발행마저 실패하면 유실되지 않도록 바로 DLQ로 보냄
+		log.Printf("💣 [%s] 재시도 토픽(%s) 전송 실패, DLQ로 이동: %v", action, tier.Topic, err)
+		return w.sendToDLQ(action, m, cause)
+	}
+	w.retrying.add(string(m.Key), attempt)
+	retryScheduled.WithLabelValues(tier.Topic).Inc()
+	log.Printf("🔁 [%s 실패] %s로 이동 (%s 이후 재시도): %v", action, tier.Topic, notBefore.Format("15:04:05"), cause)
+	return nil
+}
+
+// startRetryConsumer: 재시도 토픽을 소비하며 not_before 시각까지 기다린 뒤 다시 처리
+// 같은 토픽의 메시지는 같은 대기 시간으로 쌓이므로 앞 메시지를 기다리는 동안 뒤 메시지도 대기 대상입니다.
+// 같은 Key의 앞선 메시지가 더 뒤 단계로 넘어갔으면 처리하지 않고 그 단계 뒤에 줄을 세웁니다.
+func (w *PurchaseWorker) startRetryConsumer(tierIndex int, tier repository.RetryTier) {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:  w.brokers,
+		Topic:    tier.Topic,
+		GroupID:  repository.RetryGroupID(w.groupID, tier.Topic),
+		MinBytes: 10e3,
+		MaxBytes: 10e6,
+	})
+	defer reader.Close()
+
+	ctx := context.Background()
+	for {
+		m, err := reader.FetchMessage(ctx)
+		if err != nil {
+			log.Printf("❌ [%s] 메시지 읽기 에러: %v", tier.Topic, err)
+			continue
+		}
+		if wait := time.Until(repository.NotBefore(m)); wait > 0 {
+			time.Sleep(wait)
+		}
+		for w.retryOnce(m, tierIndex) != nil {
+			time.Sleep(time.Second)
+		}
+		w.retrying.done(string(m.Key))
+		if err := reader.CommitMessages(ctx, m); err != nil {
+			log.Printf("❌ [%s] offset 커밋 실패: %v", tier.Topic, err)
+		}
+	}
+}
+
+// retryOnce: 재시도 토픽의 메시지를 레인 잠금을 잡고 한 번 처리 (앞선 메시지가 더 뒤 단계에 있으면 그 단계로 이동)
+func (w *PurchaseWorker) retryOnce(m kafka.Message, tierIndex int) error {
+	return w.withKey(m.Key, func() error {
+		if diverted, err := w.divert(m, tierIndex); diverted || err != nil {
+			return err
+		}
+		return w.handleMessage(m)
+	})
+}
+
+/*
+ * divert: 같은 Key(유저)의 앞선 메시지가 재시도 중이면 처리하지 않고 같은 재시도 단계 뒤에 줄을 세움
+ * - 재시도 토픽도 Key 기준으로 파티셔닝되고 단계별 Consumer가 순서대로 처리하므로,
+ *   예를 들어 구매(PURCHASE)가 실패해 재시도 대기 중일 때 도착한 취소(CANCEL)가 구매보다 먼저 반영되지 않습니다.
+ * - from은 메시지의 현재 위치입니다. (메인 토픽 -1, 재시도 단계는 인덱스) 앞선 메시지가 더 뒤 단계에 있을 때만 옮깁니다.
+ * - 발행에 실패하면 에러를 반환하며, 호출자는 순서가 뒤바뀌지 않도록 DLQ 대신 같은 메시지를 다시 시도합니다.
+ */
+func (w *PurchaseWorker) divert(m kafka.Message, from int) (bool, error) {
+	tierIndex, ok := w.retrying.behind(string(m.Key), from)
+	if !ok {
+		return false, nil
+	}
+
+	tier := w.Retry.Tiers[tierIndex]
+	notBefore := time.Now().Add(w.Retry.delay(tier))
+	reason := "선행 메시지 재시도 대기"
+	if err := w.KafkaRepo.PublishToRetry(context.Background(), withRetryAttempt(m, tierIndex), tier.Topic, notBefore, reason); err != nil {
+		log.Printf("💣 [순서 보장] 재시도 토픽(%s) 전송 실패, 다시 시도합니다: %v", tier.Topic, err)
+		return true, err
+	}
+	w.retrying.add(string(m.Key), tierIndex)
+	retryDiverted.WithLabelValues(tier.Topic).Inc()
+	log.Printf("↪️ [순서 보장] 유저 %s의 앞선 메시지가 재시도 중이므로 %s 뒤에 이어서 처리합니다.", string(m.Key), tier.Topic)
+	return true, nil
+}
+
+// withRetryAttempt: 재시도 단계 헤더를 바꾼 복사본 (PublishToRetry가 attempt+1을 기록하므로 목표 단계 인덱스를 지정)
+func withRetryAttempt(m kafka.Message, attempt int) kafka.Message {
+	headers := make([]kafka.Header, 0, len(m.Headers)+1)
+	for _, h := range m.Headers {
+		if h.Key != repository.HeaderRetryAttempt {
+			headers = append(headers, h)
+		}
+	}
+	m.Headers = append(headers, kafka.Header{Key: repository.HeaderRetryAttempt, Value: []byte(strconv.Itoa(attempt))})
+	return m
+}
+
+/*
+ * retryKeys: 재시도 토픽에 메시지가 남아 있는 Key와 그중 가장 뒤 단계
+ * - 재시도 토픽으로 넘길 때 add, 재시도 Consumer가 처리(성공/다음 단계/DLQ)를 마칠 때 done을 호출합니다.
+ * - 남은 메시지가 없어질 때까지 가장 뒤 단계를 유지하여, 뒤따르는 메시지가 앞선 메시지보다 앞 단계에 놓이지 않도록 합니다.
+ * - 워커 프로세스 메모리에만 있으므로 재시작하거나, 메인 토픽과 재시도 토픽의 파티션이 서로 다른 워커 프로세스에
+ *   배정되면 순서를 보장하지 못합니다. (같은 유저의 메시지가 최종 실패해 DLQ로 간 이후의 메시지도 순서 보장 대상이 아님)
+ */
+type retryKeys struct {
+	mu      sync.Mutex
+	pending map[string]retryKey
+}
+
+type retryKey struct {
+	tier  int // 재시도 중인 메시지 중 가장 뒤 단계
+	count int // 재시도 토픽에 남아 있는 메시지 수
+}
+
+func (r *retryKeys) add(key string, tier int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending == nil {
+		r.pending = make(map[string]retryKey)
+	}
+	k := r.pending[key]
+	k.tier = max(k.tier, tier)
+	k.count++
+	r.pending[key] = k
+}
+
+func (r *retryKeys) done(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k, ok := r.pending[key]
+	if !ok {
+		return
+	}
+	if k.count--; k.count <= 0 {
+		delete(r.pending, key)
+		return
+	}
+	r.pending[key] = k
+}
+
+// tier: Key의 메시지가 재시도 중이면 가장 뒤 단계 인덱스
+func (r *retryKeys) tier(key string) (int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k, ok := r.pending[key]
+	return k.tier, ok
+}
+
+// behind: 위치 from의 메시지가 앞선 메시지를 따라 옮겨가야 할 재시도 단계
+func (r *retryKeys) behind(key string, from int) (int, bool) {
+	tier, ok := r.tier(key)
+	if !ok || tier <= from {
+		return 0, false
+	}
+	return tier, true
+}
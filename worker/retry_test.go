@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"testing"
+	"ticket-system/repository"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestRetryKeysPurchaseFailsThenCancel(t *testing.T) {
+	var r retryKeys
+	const user = "user_1"
+
+	// 1. 메인 토픽의 PURCHASE가 실패하여 5초 단계(0)로 이동
+	r.add(user, 0)
+
+	// 2. 뒤따르는 CANCEL은 처리되지 않고 같은 5초 단계로 옮겨짐
+	tier, ok := r.behind(user, -1)
+	if !ok || tier != 0 {
+		t.Fatalf("CANCEL(메인) = %d, %v; want 0단계로 이동", tier, ok)
+	}
+	r.add(user, tier)
+
+	// 3. 5초 단계에서 PURCHASE가 다시 실패하여 1분 단계(1)로 이동
+	if _, ok := r.behind(user, 0); ok {
+		t.Fatal("PURCHASE(0단계)가 처리되지 않고 옮겨짐")
+	}
+	r.add(user, 1)
+	r.done(user)
+
+	// 4. 5초 단계의 CANCEL은 PURCHASE를 앞지르지 않도록 1분 단계로 옮겨짐
+	tier, ok = r.behind(user, 0)
+	if !ok || tier != 1 {
+		t.Fatalf("CANCEL(0단계) = %d, %v; want 1단계로 이동", tier, ok)
+	}
+	r.add(user, tier)
+	r.done(user)
+
+	// 5. 1분 단계에서 PURCHASE → CANCEL 순서로 처리
+	if _, ok := r.behind(user, 1); ok {
+		t.Fatal("PURCHASE(1단계)가 처리되지 않고 옮겨짐")
+	}
+	r.done(user)
+	if _, ok := r.behind(user, 1); ok {
+		t.Fatal("CANCEL(1단계)가 처리되지 않고 옮겨짐")
+	}
+	r.done(user)
+
+	// 6. 재시도 중인 메시지가 없으므로 이후 메시지는 메인 토픽에서 바로 처리
+	if _, ok := r.tier(user); ok {
+		t.Fatal("모든 재시도가 끝났는데 Key가 남아 있음")
+	}
+}
+
+func TestRetryKeysOtherUsersUnaffected(t *testing.T) {
+	var r retryKeys
+	r.add("user_1", 0)
+	if _, ok := r.behind("user_2", -1); ok {
+		t.Fatal("재시도 중이 아닌 유저의 메시지가 옮겨짐")
+	}
+	r.done("user_2") // 추적하지 않는 Key의 done은 무시
+	if _, ok := r.tier("user_1"); !ok {
+		t.Fatal("다른 유저의 done으로 재시도 중인 Key가 지워짐")
+	}
+}
+
+func TestWithRetryAttempt(t *testing.T) {
+	m := kafka.Message{Headers: []kafka.Header{
+		{Key: "event_type", Value: []byte("CANCEL")},
+		{Key: repository.HeaderRetryAttempt, Value: []byte("0")},
+	}}
+
+	got := withRetryAttempt(m, 1)
+	if n := repository.RetryAttempt(got); n != 1 {
+		t.Fatalf("RetryAttempt = %d, want 1", n)
+	}
+	if len(got.Headers) != 2 || repository.Headers(got)["event_type"] != "CANCEL" {
+		t.Errorf("headers = %v", got.Headers)
+	}
+	if repository.RetryAttempt(m) != 0 {
+		t.Error("원본 메시지의 헤더가 바뀜")
+	}
+}